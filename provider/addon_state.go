@@ -0,0 +1,37 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+type AddonState struct {
+	ID          string                 `tfsdk:"id"`
+	Name        string                 `tfsdk:"name"`
+	Description string                 `tfsdk:"description"`
+	Enabled     bool                   `tfsdk:"enabled"`
+	Parameters  []*AddonParameterState `tfsdk:"parameters"`
+}
+
+type AddonParameterState struct {
+	ID           string   `tfsdk:"id"`
+	Name         string   `tfsdk:"name"`
+	Description  string   `tfsdk:"description"`
+	ValueType    string   `tfsdk:"value_type"`
+	Required     bool     `tfsdk:"required"`
+	Editable     bool     `tfsdk:"editable"`
+	DefaultValue string   `tfsdk:"default_value"`
+	Validation   string   `tfsdk:"validation"`
+	Options      []string `tfsdk:"options"`
+}
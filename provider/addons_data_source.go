@@ -0,0 +1,229 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+type AddonsDataSourceType struct {
+}
+
+type AddonsDataSource struct {
+	logger     logging.Logger
+	collection *cmv1.AddOnsClient
+}
+
+func (t *AddonsDataSourceType) GetSchema(ctx context.Context) (result tfsdk.Schema,
+	diags diag.Diagnostics) {
+	result = tfsdk.Schema{
+		Description: "List of add-ons that can be installed in clusters.",
+		Attributes: map[string]tfsdk.Attribute{
+			"items": {
+				Description: "Items of the list.",
+				Attributes: tfsdk.ListNestedAttributes(
+					t.itemAttributes(),
+					tfsdk.ListNestedAttributesOptions{},
+				),
+				Computed: true,
+			},
+		},
+	}
+	return
+}
+
+func (t *AddonsDataSourceType) itemAttributes() map[string]tfsdk.Attribute {
+	return map[string]tfsdk.Attribute{
+		"id": {
+			Description: "Unique identifier of the add-on. This is what " +
+				"should be used as the value of the `addon` attribute of " +
+				"the `ocm_cluster_addon` resource.",
+			Type:     types.StringType,
+			Computed: true,
+		},
+		"name": {
+			Description: "Human friendly name of the add-on.",
+			Type:        types.StringType,
+			Computed:    true,
+		},
+		"description": {
+			Description: "Description of the add-on.",
+			Type:        types.StringType,
+			Computed:    true,
+		},
+		"enabled": {
+			Description: "Indicates if the add-on can be installed.",
+			Type:        types.BoolType,
+			Computed:    true,
+		},
+		"parameters": {
+			Description: "Parameters supported by the add-on.",
+			Attributes: tfsdk.ListNestedAttributes(
+				t.parameterAttributes(),
+				tfsdk.ListNestedAttributesOptions{},
+			),
+			Computed: true,
+		},
+	}
+}
+
+func (t *AddonsDataSourceType) parameterAttributes() map[string]tfsdk.Attribute {
+	return map[string]tfsdk.Attribute{
+		"id": {
+			Description: "Unique identifier of the parameter. This is what " +
+				"should be used as the key in the `parameters` attribute " +
+				"of the `ocm_cluster_addon` resource.",
+			Type:     types.StringType,
+			Computed: true,
+		},
+		"name": {
+			Description: "Human friendly name of the parameter.",
+			Type:        types.StringType,
+			Computed:    true,
+		},
+		"description": {
+			Description: "Description of the parameter.",
+			Type:        types.StringType,
+			Computed:    true,
+		},
+		"value_type": {
+			Description: "Type of the value of the parameter, for example " +
+				"`string`, `number` or `boolean`.",
+			Type:     types.StringType,
+			Computed: true,
+		},
+		"required": {
+			Description: "Indicates if the parameter is required.",
+			Type:        types.BoolType,
+			Computed:    true,
+		},
+		"editable": {
+			Description: "Indicates if the parameter can be changed after " +
+				"the add-on has been installed.",
+			Type:     types.BoolType,
+			Computed: true,
+		},
+		"default_value": {
+			Description: "Value used when the parameter isn't set.",
+			Type:        types.StringType,
+			Computed:    true,
+		},
+		"validation": {
+			Description: "Regular expression that the value must match.",
+			Type:        types.StringType,
+			Computed:    true,
+		},
+		"options": {
+			Description: "Allowed values of the parameter. Empty if any " +
+				"value is allowed.",
+			Type: types.ListType{
+				ElemType: types.StringType,
+			},
+			Computed: true,
+		},
+	}
+}
+
+func (t *AddonsDataSourceType) NewDataSource(ctx context.Context,
+	p tfsdk.Provider) (result tfsdk.DataSource, diags diag.Diagnostics) {
+	// Cast the provider interface to the specific implementation:
+	parent := p.(*Provider)
+
+	// Get the collection of add-ons:
+	collection := parent.connection.ClustersMgmt().V1().Addons()
+
+	// Create the resource:
+	result = &AddonsDataSource{
+		logger:     parent.logger,
+		collection: collection,
+	}
+	return
+}
+
+func (s *AddonsDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest,
+	response *tfsdk.ReadDataSourceResponse) {
+	// Fetch the complete list of add-ons:
+	var listItems []*cmv1.AddOn
+	listSize := 100
+	listPage := 1
+	listRequest := s.collection.List().Size(listSize)
+	for {
+		listResponse, err := listRequest.SendContext(ctx)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't list add-ons",
+				err.Error(),
+			)
+			return
+		}
+		if listItems == nil {
+			listItems = make([]*cmv1.AddOn, 0, listResponse.Total())
+		}
+		listResponse.Items().Each(func(listItem *cmv1.AddOn) bool {
+			listItems = append(listItems, listItem)
+			return true
+		})
+		if listResponse.Size() < listSize {
+			break
+		}
+		listPage++
+		listRequest.Page(listPage)
+	}
+
+	// Populate the state:
+	state := &AddonsState{
+		Items: make([]*AddonState, len(listItems)),
+	}
+	for i, listItem := range listItems {
+		parameters := []*AddonParameterState{}
+		listItem.Parameters().Each(func(parameter *cmv1.AddOnParameter) bool {
+			options := []string{}
+			for _, option := range parameter.Options() {
+				options = append(options, option.Value())
+			}
+			parameters = append(parameters, &AddonParameterState{
+				ID:           parameter.ID(),
+				Name:         parameter.Name(),
+				Description:  parameter.Description(),
+				ValueType:    parameter.ValueType(),
+				Required:     parameter.Required(),
+				Editable:     parameter.Editable(),
+				DefaultValue: parameter.DefaultValue(),
+				Validation:   parameter.Validation(),
+				Options:      options,
+			})
+			return true
+		})
+		state.Items[i] = &AddonState{
+			ID:          listItem.ID(),
+			Name:        listItem.Name(),
+			Description: listItem.Description(),
+			Enabled:     listItem.Enabled(),
+			Parameters:  parameters,
+		}
+	}
+
+	// Save the state:
+	diags := response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
@@ -0,0 +1,650 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+type ClusterAddonResourceType struct {
+}
+
+type ClusterAddonResource struct {
+	logger     logging.Logger
+	collection *cmv1.ClustersClient
+	addons     *cmv1.AddOnsClient
+}
+
+func (t *ClusterAddonResourceType) GetSchema(ctx context.Context) (result tfsdk.Schema,
+	diags diag.Diagnostics) {
+	result = tfsdk.Schema{
+		Description: "Add-on installed in a cluster.",
+		Attributes: map[string]tfsdk.Attribute{
+			"cluster": {
+				Description: "Identifier of the cluster.",
+				Type:        types.StringType,
+				Required:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"id": {
+				Description: "Unique identifier of the add-on installation.",
+				Type:        types.StringType,
+				Computed:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"addon": {
+				Description: "Identifier of the add-on, for example " +
+					"`cluster-logging-operator`. Use the `ocm_addons` data " +
+					"source to find the possible values.",
+				Type:     types.StringType,
+				Required: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"parameters": {
+				Description: "Values of the parameters of the add-on. Use " +
+					"the `ocm_addons` data source to find the parameters " +
+					"supported by each add-on.",
+				Type: types.MapType{
+					ElemType: types.StringType,
+				},
+				Optional: true,
+			},
+			"state": {
+				Description: "State of the add-on installation.",
+				Type:        types.StringType,
+				Computed:    true,
+			},
+			"timeouts": {
+				Description: "Timeouts of the create, update and delete operations.",
+				Attributes:  timeoutsResource(),
+				Optional:    true,
+			},
+		},
+	}
+	return
+}
+
+func (t *ClusterAddonResourceType) NewResource(ctx context.Context,
+	p tfsdk.Provider) (result tfsdk.Resource, diags diag.Diagnostics) {
+	// Cast the provider interface to the specific implementation: use it directly when needed.
+	parent := p.(*Provider)
+
+	// Get the collections of clusters and add-ons:
+	collection := parent.connection.ClustersMgmt().V1().Clusters()
+	addons := parent.connection.ClustersMgmt().V1().Addons()
+
+	// Create the resource:
+	result = &ClusterAddonResource{
+		logger:     parent.logger,
+		collection: collection,
+		addons:     addons,
+	}
+
+	return
+}
+
+func (r *ClusterAddonResource) Create(ctx context.Context,
+	request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	// Get the plan:
+	state := &ClusterAddonState{}
+	diags := request.Plan.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Apply the create timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, state.Timeouts.CreateTimeout())
+	defer cancel()
+
+	// Wait till the cluster is ready:
+	resource := r.collection.Cluster(state.Cluster.Value)
+	_, err := waitTillClusterReady(ctx, resource, state.Timeouts.Interval())
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't poll cluster state",
+			fmt.Sprintf(
+				"Can't poll state of cluster with identifier '%s': %v",
+				state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+
+	// Install the add-on. Note that the parameters have already been checked when the plan
+	// was calculated.
+	parameters := addonParameters(state.Parameters)
+	builder := cmv1.NewAddOnInstallation().
+		Addon(cmv1.NewAddOn().ID(state.Addon.Value))
+	if len(parameters) > 0 {
+		builder.Parameters(addonParametersBuilder(parameters))
+	}
+	object, err := builder.Build()
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't build add-on installation",
+			fmt.Sprintf(
+				"Can't build installation of add-on '%s' for cluster '%s': %v",
+				state.Addon.Value, state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+	add, err := resource.Addons().Add().Body(object).SendContext(ctx)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't install add-on",
+			fmt.Sprintf(
+				"Can't install add-on '%s' in cluster '%s': %v",
+				state.Addon.Value, state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+	object = add.Body()
+
+	// Wait till the add-on is installed. Note that we save the state even if this fails, so
+	// that Terraform marks the resource as tainted and replaces it in the next run.
+	installation := resource.Addons().Addoninstallation(object.ID())
+	ready, err := waitTillAddonReady(ctx, installation, state.Timeouts.Interval())
+	if ready != nil {
+		object = ready
+	}
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't install add-on",
+			fmt.Sprintf(
+				"Can't install add-on '%s' in cluster '%s': %v",
+				state.Addon.Value, state.Cluster.Value, err,
+			),
+		)
+	}
+
+	// Save the state:
+	r.populateState(object, state)
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *ClusterAddonResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest,
+	response *tfsdk.ReadResourceResponse) {
+	// Get the current state:
+	state := &ClusterAddonState{}
+	diags := request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Find the add-on installation:
+	get, err := r.collection.Cluster(state.Cluster.Value).
+		Addons().
+		Addoninstallation(state.ID.Value).
+		Get().
+		SendContext(ctx)
+	if err != nil && get != nil && get.Status() == http.StatusNotFound {
+		r.logger.Warn(
+			ctx,
+			"Add-on '%s' isn't installed in cluster '%s', removing from state",
+			state.ID.Value, state.Cluster.Value,
+		)
+		response.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't find add-on installation",
+			fmt.Sprintf(
+				"Can't find installation of add-on '%s' in cluster '%s': %v",
+				state.ID.Value, state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+	object := get.Body()
+
+	// Save the state:
+	r.populateState(object, state)
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *ClusterAddonResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest,
+	response *tfsdk.UpdateResourceResponse) {
+	var diags diag.Diagnostics
+
+	// Get the state:
+	state := &ClusterAddonState{}
+	diags = request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Get the plan:
+	plan := &ClusterAddonState{}
+	diags = request.Plan.Get(ctx, plan)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Apply the update timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, plan.Timeouts.UpdateTimeout())
+	defer cancel()
+
+	// Send the new parameters only if they have changed, as the server reinstalls the add-on
+	// when they are updated:
+	installation := r.collection.Cluster(state.Cluster.Value).
+		Addons().
+		Addoninstallation(state.ID.Value)
+	parameters := addonParameters(plan.Parameters)
+	if !plan.Parameters.Equal(state.Parameters) {
+		patch, err := cmv1.NewAddOnInstallation().
+			Parameters(addonParametersBuilder(parameters)).
+			Build()
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't build add-on installation patch",
+				fmt.Sprintf(
+					"Can't build patch for add-on '%s' in cluster '%s': %v",
+					state.ID.Value, state.Cluster.Value, err,
+				),
+			)
+			return
+		}
+		_, err = installation.Update().Body(patch).SendContext(ctx)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't update add-on",
+				fmt.Sprintf(
+					"Can't update add-on '%s' in cluster '%s': %v",
+					state.ID.Value, state.Cluster.Value, err,
+				),
+			)
+			return
+		}
+	}
+
+	// Wait till the add-on is ready again:
+	object, err := waitTillAddonReady(ctx, installation, plan.Timeouts.Interval())
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't update add-on",
+			fmt.Sprintf(
+				"Can't update add-on '%s' in cluster '%s': %v",
+				state.ID.Value, state.Cluster.Value, err,
+			),
+		)
+		if object == nil {
+			return
+		}
+	}
+
+	// Save the state:
+	state.Parameters = plan.Parameters
+	state.Timeouts = plan.Timeouts
+	r.populateState(object, state)
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *ClusterAddonResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest,
+	response *tfsdk.DeleteResourceResponse) {
+	// Get the state:
+	state := &ClusterAddonState{}
+	diags := request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Apply the delete timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, state.Timeouts.DeleteTimeout())
+	defer cancel()
+
+	// Send the request to uninstall the add-on:
+	installation := r.collection.Cluster(state.Cluster.Value).
+		Addons().
+		Addoninstallation(state.ID.Value)
+	_, err := installation.Delete().SendContext(ctx)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't uninstall add-on",
+			fmt.Sprintf(
+				"Can't uninstall add-on '%s' from cluster '%s': %v",
+				state.ID.Value, state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+
+	// Wait till the add-on is completely removed:
+	err = waitTillAddonDeleted(ctx, installation, state.Timeouts.Interval())
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't poll add-on deletion",
+			fmt.Sprintf(
+				"Can't poll deletion of add-on '%s' from cluster '%s': %v",
+				state.ID.Value, state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+
+	// Remove the state:
+	response.State.RemoveResource(ctx)
+}
+
+func (r *ClusterAddonResource) ImportState(ctx context.Context,
+	request tfsdk.ImportResourceStateRequest, response *tfsdk.ImportResourceStateResponse) {
+	// The identifier of the add-on is only unique within the cluster, so the import identifier
+	// must contain both:
+	parts, err := parseImportID(request.ID, "cluster_id", "addon_id")
+	if err != nil {
+		response.Diagnostics.AddError("Invalid import identifier", err.Error())
+		return
+	}
+
+	// The read operation only refreshes the parameters that are already in the state, so we
+	// need to retrieve the installation here to import all of them:
+	get, err := r.collection.Cluster(parts[0]).
+		Addons().
+		Addoninstallation(parts[1]).
+		Get().
+		SendContext(ctx)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't find add-on installation",
+			fmt.Sprintf(
+				"Can't find installation of add-on '%s' in cluster '%s': %v",
+				parts[1], parts[0], err,
+			),
+		)
+		return
+	}
+	parameters := types.Map{
+		ElemType: types.StringType,
+		Null:     true,
+	}
+	get.Body().Parameters().Each(func(parameter *cmv1.AddOnInstallationParameter) bool {
+		if parameters.Null {
+			parameters.Null = false
+			parameters.Elems = map[string]attr.Value{}
+		}
+		parameters.Elems[parameter.ID()] = types.String{
+			Value: parameter.Value(),
+		}
+		return true
+	})
+	diags := response.State.SetAttribute(
+		ctx,
+		tftypes.NewAttributePath().WithAttributeName("cluster"),
+		parts[0],
+	)
+	response.Diagnostics.Append(diags...)
+	diags = response.State.SetAttribute(
+		ctx,
+		tftypes.NewAttributePath().WithAttributeName("id"),
+		parts[1],
+	)
+	response.Diagnostics.Append(diags...)
+	diags = response.State.SetAttribute(
+		ctx,
+		tftypes.NewAttributePath().WithAttributeName("parameters"),
+		parameters,
+	)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *ClusterAddonResource) ModifyPlan(ctx context.Context,
+	request tfsdk.ModifyResourcePlanRequest, response *tfsdk.ModifyResourcePlanResponse) {
+	// Nothing to check when the add-on is being deleted:
+	if request.Plan.Raw.IsNull() {
+		return
+	}
+
+	// Get the plan:
+	plan := &ClusterAddonState{}
+	diags := request.Plan.Get(ctx, plan)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Values that aren't known yet can't be checked, so in that case the parameters will be
+	// checked during the apply phase:
+	if plan.Addon.Unknown || plan.Parameters.Unknown {
+		return
+	}
+	for _, value := range plan.Parameters.Elems {
+		if value.(types.String).Unknown {
+			return
+		}
+	}
+
+	// There is no need to retrieve the definition of the add-on if the parameters haven't
+	// changed:
+	if !request.State.Raw.IsNull() {
+		state := &ClusterAddonState{}
+		diags = request.State.Get(ctx, state)
+		response.Diagnostics.Append(diags...)
+		if response.Diagnostics.HasError() {
+			return
+		}
+		if plan.Addon.Value == state.Addon.Value && plan.Parameters.Equal(state.Parameters) {
+			return
+		}
+	}
+
+	// Check the parameters:
+	err := r.checkParameters(ctx, plan.Addon.Value, addonParameters(plan.Parameters))
+	if err != nil {
+		response.Diagnostics.AddAttributeError(
+			tftypes.NewAttributePath().WithAttributeName("parameters"),
+			"Invalid add-on parameters",
+			fmt.Sprintf(
+				"Invalid parameters for add-on '%s': %v",
+				plan.Addon.Value, err,
+			),
+		)
+	}
+}
+
+// checkParameters retrieves the definition of the add-on and checks the given parameters
+// against it.
+func (r *ClusterAddonResource) checkParameters(ctx context.Context, id string,
+	parameters map[string]string) error {
+	get, err := r.addons.Addon(id).Get().SendContext(ctx)
+	if err != nil {
+		return fmt.Errorf("can't find add-on: %v", err)
+	}
+	return checkAddonParameters(get.Body(), parameters)
+}
+
+// populateState copies the data from the API object to the Terraform state. Only the parameters
+// that are already in the state are refreshed, as the server also returns the parameters that
+// have default values.
+func (r *ClusterAddonResource) populateState(object *cmv1.AddOnInstallation,
+	state *ClusterAddonState) {
+	state.ID = types.String{
+		Value: object.ID(),
+	}
+	state.Addon = types.String{
+		Value: object.Addon().ID(),
+	}
+	state.State = types.String{
+		Value: string(object.State()),
+	}
+	if state.Parameters.Unknown || state.Parameters.Null {
+		state.Parameters = types.Map{
+			ElemType: types.StringType,
+			Null:     true,
+		}
+		return
+	}
+	values := map[string]string{}
+	object.Parameters().Each(func(parameter *cmv1.AddOnInstallationParameter) bool {
+		values[parameter.ID()] = parameter.Value()
+		return true
+	})
+	elems := map[string]attr.Value{}
+	for name := range state.Parameters.Elems {
+		value, ok := values[name]
+		if ok {
+			elems[name] = types.String{
+				Value: value,
+			}
+		}
+	}
+	state.Parameters = types.Map{
+		ElemType: types.StringType,
+		Elems:    elems,
+	}
+}
+
+// addonParameters converts the Terraform map of parameters into a Go map.
+func addonParameters(value types.Map) map[string]string {
+	result := map[string]string{}
+	if value.Unknown || value.Null {
+		return result
+	}
+	for k, v := range value.Elems {
+		result[k] = v.(types.String).Value
+	}
+	return result
+}
+
+// addonParametersBuilder creates the builder for the list of installation parameters, sorted by
+// identifier so that the requests are always the same.
+func addonParametersBuilder(parameters map[string]string) *cmv1.AddOnInstallationParameterListBuilder {
+	names := make([]string, 0, len(parameters))
+	for name := range parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	items := make([]*cmv1.AddOnInstallationParameterBuilder, len(names))
+	for i, name := range names {
+		items[i] = cmv1.NewAddOnInstallationParameter().
+			ID(name).
+			Value(parameters[name])
+	}
+	return cmv1.NewAddOnInstallationParameterList().Items(items...)
+}
+
+// checkAddonParameters checks that the given parameters are supported by the add-on, that the
+// required parameters are present and that the values are valid according to the definition of
+// each parameter.
+func checkAddonParameters(addon *cmv1.AddOn, parameters map[string]string) error {
+	definitions := map[string]*cmv1.AddOnParameter{}
+	addon.Parameters().Each(func(definition *cmv1.AddOnParameter) bool {
+		if definition.Enabled() {
+			definitions[definition.ID()] = definition
+		}
+		return true
+	})
+	var problems []string
+	for name, value := range parameters {
+		definition, ok := definitions[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf(
+				"parameter '%s' isn't supported",
+				name,
+			))
+			continue
+		}
+		problem := checkAddonParameterValue(definition, value)
+		if problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+	for name, definition := range definitions {
+		_, ok := parameters[name]
+		if !ok && definition.Required() && definition.DefaultValue() == "" {
+			problems = append(problems, fmt.Sprintf(
+				"parameter '%s' is required",
+				name,
+			))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("%s", strings.Join(problems, ", "))
+}
+
+// checkAddonParameterValue checks the value of a parameter against its definition. It returns a
+// description of the problem, or an empty string if the value is valid.
+func checkAddonParameterValue(definition *cmv1.AddOnParameter, value string) string {
+	options := definition.Options()
+	if len(options) > 0 {
+		values := make([]string, len(options))
+		for i, option := range options {
+			if option.Value() == value {
+				return ""
+			}
+			values[i] = option.Value()
+		}
+		return fmt.Sprintf(
+			"value '%s' of parameter '%s' should be one of %s",
+			value, definition.ID(), strings.Join(values, ", "),
+		)
+	}
+	validation := definition.Validation()
+	if validation == "" {
+		return ""
+	}
+	re, err := regexp.Compile(validation)
+	if err != nil {
+		// The expressions are written for the server, so we let it check the values that
+		// we don't know how to check.
+		return ""
+	}
+	if re.MatchString(value) {
+		return ""
+	}
+	if definition.ValidationErrMsg() != "" {
+		return fmt.Sprintf(
+			"value '%s' of parameter '%s' isn't valid: %s",
+			value, definition.ID(), definition.ValidationErrMsg(),
+		)
+	}
+	return fmt.Sprintf(
+		"value '%s' of parameter '%s' doesn't match regular expression '%s'",
+		value, definition.ID(), validation,
+	)
+}
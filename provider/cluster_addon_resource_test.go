@@ -0,0 +1,115 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	. "github.com/onsi/ginkgo/v2/dsl/core"  // nolint
+	. "github.com/onsi/ginkgo/v2/dsl/table" // nolint
+	. "github.com/onsi/gomega"              // nolint
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var _ = Describe("Add-on parameters", func() {
+	var addon *cmv1.AddOn
+
+	BeforeEach(func() {
+		var err error
+		addon, err = cmv1.NewAddOn().
+			ID("my-addon").
+			Parameters(cmv1.NewAddOnParameterList().Items(
+				cmv1.NewAddOnParameter().
+					ID("size").
+					Enabled(true).
+					Required(true).
+					Options(
+						cmv1.NewAddOnParameterOption().Value("1Ti"),
+						cmv1.NewAddOnParameterOption().Value("4Ti"),
+					),
+				cmv1.NewAddOnParameter().
+					ID("email").
+					Enabled(true).
+					Validation("^[^@]+@[^@]+$").
+					ValidationErrMsg("must be an email address"),
+				cmv1.NewAddOnParameter().
+					ID("replicas").
+					Enabled(true).
+					Required(true).
+					DefaultValue("3"),
+				cmv1.NewAddOnParameter().
+					ID("legacy").
+					Enabled(false),
+			)).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	DescribeTable("Checks the parameters",
+		func(parameters map[string]string, message string) {
+			err := checkAddonParameters(addon, parameters)
+			if message == "" {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(message))
+			}
+		},
+		Entry(
+			"Valid",
+			map[string]string{
+				"size":  "4Ti",
+				"email": "me@example.com",
+			},
+			"",
+		),
+		Entry(
+			"Missing required parameter",
+			map[string]string{},
+			"parameter 'size' is required",
+		),
+		Entry(
+			"Value not in options",
+			map[string]string{
+				"size": "2Ti",
+			},
+			"should be one of 1Ti, 4Ti",
+		),
+		Entry(
+			"Value doesn't match validation",
+			map[string]string{
+				"size":  "1Ti",
+				"email": "me",
+			},
+			"must be an email address",
+		),
+		Entry(
+			"Unsupported parameter",
+			map[string]string{
+				"size":  "1Ti",
+				"color": "blue",
+			},
+			"parameter 'color' isn't supported",
+		),
+		Entry(
+			"Disabled parameter",
+			map[string]string{
+				"size":   "1Ti",
+				"legacy": "true",
+			},
+			"parameter 'legacy' isn't supported",
+		),
+	)
+})
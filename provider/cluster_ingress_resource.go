@@ -0,0 +1,510 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+type ClusterIngressResourceType struct {
+}
+
+type ClusterIngressResource struct {
+	logger     logging.Logger
+	collection *cmv1.ClustersClient
+}
+
+func (t *ClusterIngressResourceType) GetSchema(ctx context.Context) (result tfsdk.Schema,
+	diags diag.Diagnostics) {
+	result = tfsdk.Schema{
+		Description: "Ingress of a cluster. Excluded namespaces, wildcard " +
+			"policy and default certificate aren't supported yet.",
+		Attributes: map[string]tfsdk.Attribute{
+			"cluster": {
+				Description: "Identifier of the cluster.",
+				Type:        types.StringType,
+				Required:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"id": {
+				Description: "Unique identifier of the ingress.",
+				Type:        types.StringType,
+				Computed:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"default": {
+				Description: "If 'true' the resource manages the default " +
+					"ingress that is created together with the cluster. " +
+					"That ingress is modified but never deleted, " +
+					"destroying the resource only removes it from the " +
+					"Terraform state. If 'false' a new secondary ingress " +
+					"is created. Default value is 'false'.",
+				Type:     types.BoolType,
+				Optional: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"listening": {
+				Description: "Listening method of the ingress, can be " +
+					"'external' or 'internal'.",
+				Type:     types.StringType,
+				Optional: true,
+				Computed: true,
+				Validators: []tfsdk.AttributeValidator{
+					EnumValidator(
+						string(cmv1.ListeningMethodExternal),
+						string(cmv1.ListeningMethodInternal),
+					),
+				},
+			},
+			"route_selectors": {
+				Description: "Labels that routes must have in order to be " +
+					"served by the ingress.",
+				Type: types.MapType{
+					ElemType: types.StringType,
+				},
+				Optional: true,
+			},
+			"dns_name": {
+				Description: "DNS name of the ingress.",
+				Type:        types.StringType,
+				Computed:    true,
+			},
+			"timeouts": {
+				Description: "Timeouts of the create, update and delete operations.",
+				Attributes:  timeoutsResource(),
+				Optional:    true,
+			},
+		},
+	}
+	return
+}
+
+func (t *ClusterIngressResourceType) NewResource(ctx context.Context,
+	p tfsdk.Provider) (result tfsdk.Resource, diags diag.Diagnostics) {
+	// Cast the provider interface to the specific implementation: use it directly when needed.
+	parent := p.(*Provider)
+
+	// Get the collection of clusters:
+	collection := parent.connection.ClustersMgmt().V1().Clusters()
+
+	// Create the resource:
+	result = &ClusterIngressResource{
+		logger:     parent.logger,
+		collection: collection,
+	}
+
+	return
+}
+
+func (r *ClusterIngressResource) Create(ctx context.Context,
+	request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	// Get the plan:
+	state := &ClusterIngressState{}
+	diags := request.Plan.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Apply the create timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, state.Timeouts.CreateTimeout())
+	defer cancel()
+
+	// Wait till the cluster is ready:
+	resource := r.collection.Cluster(state.Cluster.Value)
+	_, err := waitTillClusterReady(ctx, resource, state.Timeouts.Interval())
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't poll cluster state",
+			fmt.Sprintf(
+				"Can't poll state of cluster with identifier '%s': %v",
+				state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+
+	// Build the ingress:
+	builder, err := buildIngress(state)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't build ingress",
+			fmt.Sprintf(
+				"Can't build ingress for cluster '%s': %v",
+				state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+	if !state.RouteSelectors.Unknown && !state.RouteSelectors.Null {
+		builder.RouteSelectors(ingressRouteSelectors(state.RouteSelectors))
+	}
+	object, err := builder.Build()
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't build ingress",
+			fmt.Sprintf(
+				"Can't build ingress for cluster '%s': %v",
+				state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+
+	// The default ingress is created by the server together with the cluster, so in that
+	// case we adopt and update it instead of creating a new one:
+	collection := resource.Ingresses()
+	if state.Default.Value {
+		current, err := findDefaultIngress(ctx, collection)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't find default ingress",
+				fmt.Sprintf(
+					"Can't find default ingress of cluster '%s': %v",
+					state.Cluster.Value, err,
+				),
+			)
+			return
+		}
+		update, err := collection.Ingress(current.ID()).Update().Body(object).SendContext(ctx)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't update default ingress",
+				fmt.Sprintf(
+					"Can't update default ingress '%s' of cluster '%s': %v",
+					current.ID(), state.Cluster.Value, err,
+				),
+			)
+			return
+		}
+		object = update.Body()
+	} else {
+		add, err := collection.Add().Body(object).SendContext(ctx)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't create ingress",
+				fmt.Sprintf(
+					"Can't create ingress for cluster '%s': %v",
+					state.Cluster.Value, err,
+				),
+			)
+			return
+		}
+		object = add.Body()
+	}
+
+	// Save the state:
+	r.populateState(object, state)
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *ClusterIngressResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest,
+	response *tfsdk.ReadResourceResponse) {
+	// Get the current state:
+	state := &ClusterIngressState{}
+	diags := request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Find the ingress:
+	get, err := r.collection.Cluster(state.Cluster.Value).
+		Ingresses().
+		Ingress(state.ID.Value).
+		Get().
+		SendContext(ctx)
+	if err != nil && get != nil && get.Status() == http.StatusNotFound {
+		r.logger.Warn(
+			ctx,
+			"Ingress '%s' of cluster '%s' not found, removing from state",
+			state.ID.Value, state.Cluster.Value,
+		)
+		response.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't find ingress",
+			fmt.Sprintf(
+				"Can't find ingress with identifier '%s' for "+
+					"cluster '%s': %v",
+				state.ID.Value, state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+	object := get.Body()
+
+	// Save the state:
+	r.populateState(object, state)
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *ClusterIngressResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest,
+	response *tfsdk.UpdateResourceResponse) {
+	var diags diag.Diagnostics
+
+	// Get the state:
+	state := &ClusterIngressState{}
+	diags = request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Get the plan:
+	plan := &ClusterIngressState{}
+	diags = request.Plan.Get(ctx, plan)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Apply the update timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, plan.Timeouts.UpdateTimeout())
+	defer cancel()
+
+	// Send the patch. Note that the route selectors are always sent, so that removing them
+	// from the configuration also removes them from the ingress.
+	builder, err := buildIngress(plan)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't update ingress",
+			fmt.Sprintf(
+				"Can't update ingress '%s' of cluster '%s': %v",
+				state.ID.Value, state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+	builder.RouteSelectors(ingressRouteSelectors(plan.RouteSelectors))
+	patch, err := builder.Build()
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't build ingress patch",
+			fmt.Sprintf(
+				"Can't build patch for ingress '%s' of cluster '%s': %v",
+				state.ID.Value, state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+	update, err := r.collection.Cluster(state.Cluster.Value).
+		Ingresses().
+		Ingress(state.ID.Value).
+		Update().
+		Body(patch).
+		SendContext(ctx)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't update ingress",
+			fmt.Sprintf(
+				"Can't update ingress '%s' of cluster '%s': %v",
+				state.ID.Value, state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+	object := update.Body()
+
+	// Save the state:
+	state.RouteSelectors = plan.RouteSelectors
+	state.Timeouts = plan.Timeouts
+	r.populateState(object, state)
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *ClusterIngressResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest,
+	response *tfsdk.DeleteResourceResponse) {
+	// Get the state:
+	state := &ClusterIngressState{}
+	diags := request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Apply the delete timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, state.Timeouts.DeleteTimeout())
+	defer cancel()
+
+	// The default ingress can't be deleted, it is only removed from the state:
+	if state.Default.Value {
+		r.logger.Info(
+			ctx,
+			"Ingress '%s' is the default ingress of cluster '%s', it will "+
+				"only be removed from the state",
+			state.ID.Value, state.Cluster.Value,
+		)
+		response.State.RemoveResource(ctx)
+		return
+	}
+
+	// Send the request to delete the ingress:
+	_, err := r.collection.Cluster(state.Cluster.Value).
+		Ingresses().
+		Ingress(state.ID.Value).
+		Delete().
+		SendContext(ctx)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't delete ingress",
+			fmt.Sprintf(
+				"Can't delete ingress with identifier '%s' for "+
+					"cluster '%s': %v",
+				state.ID.Value, state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+
+	// Remove the state:
+	response.State.RemoveResource(ctx)
+}
+
+func (r *ClusterIngressResource) ImportState(ctx context.Context,
+	request tfsdk.ImportResourceStateRequest, response *tfsdk.ImportResourceStateResponse) {
+	// The identifier of the ingress is only unique within the cluster, so the import
+	// identifier must contain both:
+	parts, err := parseImportID(request.ID, "cluster_id", "ingress_id")
+	if err != nil {
+		response.Diagnostics.AddError("Invalid import identifier", err.Error())
+		return
+	}
+	diags := response.State.SetAttribute(
+		ctx,
+		tftypes.NewAttributePath().WithAttributeName("cluster"),
+		parts[0],
+	)
+	response.Diagnostics.Append(diags...)
+	diags = response.State.SetAttribute(
+		ctx,
+		tftypes.NewAttributePath().WithAttributeName("id"),
+		parts[1],
+	)
+	response.Diagnostics.Append(diags...)
+}
+
+// populateState copies the data from the API object to the Terraform state.
+func (r *ClusterIngressResource) populateState(object *cmv1.Ingress, state *ClusterIngressState) {
+	state.ID = types.String{
+		Value: object.ID(),
+	}
+	if object.Default() || !state.Default.Null {
+		state.Default = types.Bool{
+			Value: object.Default(),
+		}
+	}
+	state.Listening = types.String{
+		Value: string(object.Listening()),
+	}
+	state.DNSName = types.String{
+		Value: object.DNSName(),
+	}
+	selectors := object.RouteSelectors()
+	if len(selectors) == 0 && (state.RouteSelectors.Unknown || state.RouteSelectors.Null) {
+		state.RouteSelectors = types.Map{
+			ElemType: types.StringType,
+			Null:     true,
+		}
+		return
+	}
+	state.RouteSelectors = types.Map{
+		ElemType: types.StringType,
+		Elems:    map[string]attr.Value{},
+	}
+	for k, v := range selectors {
+		state.RouteSelectors.Elems[k] = types.String{
+			Value: v,
+		}
+	}
+}
+
+// buildIngress creates the builder for the ingress described by the given state, without the
+// route selectors.
+func buildIngress(state *ClusterIngressState) (builder *cmv1.IngressBuilder, err error) {
+	builder = cmv1.NewIngress()
+	if !state.Default.Value {
+		builder.Default(false)
+	}
+	if !state.Listening.Unknown && !state.Listening.Null {
+		listening := cmv1.ListeningMethod(state.Listening.Value)
+		switch listening {
+		case cmv1.ListeningMethodExternal, cmv1.ListeningMethodInternal:
+			builder.Listening(listening)
+		default:
+			err = fmt.Errorf(
+				"listening method should be '%s' or '%s' but it is '%s'",
+				cmv1.ListeningMethodExternal, cmv1.ListeningMethodInternal,
+				state.Listening.Value,
+			)
+		}
+	}
+	return
+}
+
+// ingressRouteSelectors converts the Terraform map of route selectors into a Go map.
+func ingressRouteSelectors(value types.Map) map[string]string {
+	result := map[string]string{}
+	if value.Unknown || value.Null {
+		return result
+	}
+	for k, v := range value.Elems {
+		result[k] = v.(types.String).Value
+	}
+	return result
+}
+
+// findDefaultIngress returns the default ingress of a cluster.
+func findDefaultIngress(ctx context.Context, collection *cmv1.IngressesClient) (
+	result *cmv1.Ingress, err error) {
+	list, err := collection.List().SendContext(ctx)
+	if err != nil {
+		return
+	}
+	list.Items().Each(func(item *cmv1.Ingress) bool {
+		if item.Default() {
+			result = item
+			return false
+		}
+		return true
+	})
+	if result == nil {
+		err = fmt.Errorf("cluster doesn't have a default ingress")
+	}
+	return
+}
@@ -19,15 +19,12 @@ package provider
 import (
 	"context"
 	"fmt"
-	"net/http"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
-	"github.com/openshift-online/ocm-sdk-go/errors"
 	"github.com/openshift-online/ocm-sdk-go/logging"
 )
 
@@ -221,6 +218,11 @@ func (t *ClusterResourceType) GetSchema(ctx context.Context) (result tfsdk.Schem
 				Type:        types.BoolType,
 				Optional:    true,
 			},
+			"timeouts": {
+				Description: "Timeouts of the create, update and delete operations.",
+				Attributes:  timeoutsResource(),
+				Optional:    true,
+			},
 		},
 	}
 	return
@@ -361,6 +363,10 @@ func (r *ClusterResource) Create(ctx context.Context,
 		return
 	}
 
+	// Apply the create timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, state.Timeouts.CreateTimeout())
+	defer cancel()
+
 	object, err := createClusterObject(ctx, state, diags)
 	if err != nil {
 		response.Diagnostics.AddError(
@@ -390,21 +396,15 @@ func (r *ClusterResource) Create(ctx context.Context,
 	wait := state.Wait.Unknown || state.Wait.Null || state.Wait.Value
 	ready := object.State() == cmv1.ClusterStateReady
 	if wait && !ready {
-		pollCtx, cancel := context.WithTimeout(ctx, 1*time.Hour)
-		defer cancel()
-		_, err := r.collection.Cluster(object.ID()).Poll().
-			Interval(30 * time.Second).
-			Predicate(func(get *cmv1.ClusterGetResponse) bool {
-				object = get.Body()
-				return object.State() == cmv1.ClusterStateReady
-			}).
-			StartContext(pollCtx)
+		object, err = waitTillClusterReady(
+			ctx, r.collection.Cluster(object.ID()), state.Timeouts.Interval(),
+		)
 		if err != nil {
 			response.Diagnostics.AddError(
 				"Can't poll cluster state",
 				fmt.Sprintf(
 					"Can't poll state of cluster with identifier '%s': %v",
-					object.ID(), err,
+					add.Body().ID(), err,
 				),
 			)
 			return
@@ -467,6 +467,10 @@ func (r *ClusterResource) Update(ctx context.Context, request tfsdk.UpdateResour
 		return
 	}
 
+	// Apply the update timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, plan.Timeouts.UpdateTimeout())
+	defer cancel()
+
 	// Send request to update the cluster:
 	builder := cmv1.NewCluster()
 	var nodes *cmv1.ClusterNodesBuilder
@@ -504,6 +508,7 @@ func (r *ClusterResource) Update(ctx context.Context, request tfsdk.UpdateResour
 	object := update.Body()
 
 	// Update the state:
+	state.Timeouts = plan.Timeouts
 	populateClusterState(object, state)
 	diags = response.State.Set(ctx, state)
 	response.Diagnostics.Append(diags...)
@@ -519,6 +524,10 @@ func (r *ClusterResource) Delete(ctx context.Context, request tfsdk.DeleteResour
 		return
 	}
 
+	// Apply the delete timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, state.Timeouts.DeleteTimeout())
+	defer cancel()
+
 	// Send the request to delete the cluster:
 	resource := r.collection.Cluster(state.ID.Value)
 	_, err := resource.Delete().SendContext(ctx)
@@ -535,16 +544,7 @@ func (r *ClusterResource) Delete(ctx context.Context, request tfsdk.DeleteResour
 
 	// Wait till the cluster has been effectively deleted:
 	if state.Wait.Unknown || state.Wait.Null || state.Wait.Value {
-		pollCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
-		defer cancel()
-		_, err := resource.Poll().
-			Interval(30 * time.Second).
-			Status(http.StatusNotFound).
-			StartContext(pollCtx)
-		sdkErr, ok := err.(*errors.Error)
-		if ok && sdkErr.Status() == http.StatusNotFound {
-			err = nil
-		}
+		err := waitTillClusterDeleted(ctx, resource, state.Timeouts.Interval())
 		if err != nil {
 			response.Diagnostics.AddError(
 				"Can't poll cluster deletion",
@@ -16,16 +16,9 @@ limitations under the License.
 package provider
 
 import (
-	"bytes"
 	"context"
-	"crypto/sha1"
-	"encoding/hex"
 	"errors"
 	"fmt"
-	"os"
-
-	"net/http"
-	"net/url"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
@@ -34,6 +27,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/openshift-online/ocm-sdk-go/logging"
 )
@@ -50,7 +45,10 @@ type ClusterRosaClassicResourceType struct {
 
 type ClusterRosaClassicResource struct {
 	logger     logging.Logger
+	connection *sdk.Connection
 	collection *cmv1.ClustersClient
+	versions   *cmv1.VersionsClient
+	httpClient HttpClient
 }
 
 func (t *ClusterRosaClassicResourceType) GetSchema(ctx context.Context) (result tfsdk.Schema,
@@ -234,16 +232,35 @@ func (t *ClusterRosaClassicResourceType) GetSchema(ctx context.Context) (result
 				Computed:    true,
 			},
 			"version": {
-				Description: "Identifier of the version of OpenShift, for example 'openshift-v4.1.0'.",
-				Type:        types.StringType,
-				Optional:    true,
-				Computed:    true,
+				Description: "Identifier of the version of OpenShift, for example " +
+					"'openshift-v4.1.0'. Changing the version of an existing " +
+					"cluster schedules an upgrade to that version, which must " +
+					"be one of the available upgrades of the current version. " +
+					"While the upgrade is pending the state keeps the requested " +
+					"version.",
+				Type:     types.StringType,
+				Optional: true,
+				Computed: true,
 			},
 			"state": {
 				Description: "State of the cluster.",
 				Type:        types.StringType,
 				Computed:    true,
 			},
+			"wait": {
+				Description: "Wait till the cluster is ready on create, till version " +
+					"upgrades complete on update, and till it has been " +
+					"uninstalled on delete. Default value is 'false', " +
+					"because STS clusters don't start installing till the operator " +
+					"roles and the OIDC provider have been created.",
+				Type:     types.BoolType,
+				Optional: true,
+			},
+			"timeouts": {
+				Description: "Timeouts of the create, update and delete operations.",
+				Attributes:  timeoutsResource(),
+				Optional:    true,
+			},
 		},
 	}
 	return
@@ -254,13 +271,20 @@ func (t *ClusterRosaClassicResourceType) NewResource(ctx context.Context,
 	// Cast the provider interface to the specific implementation:
 	parent := p.(*Provider)
 
-	// Get the collection:
+	// Get the collections:
 	collection := parent.connection.ClustersMgmt().V1().Clusters()
+	versions := parent.connection.ClustersMgmt().V1().Versions()
 
 	// Create the resource:
 	result = &ClusterRosaClassicResource{
 		logger:     parent.logger,
+		connection: parent.connection,
 		collection: collection,
+		versions:   versions,
+		httpClient: DefaultHttpClient{
+			Timeout:    defaultThumbprintTimeout,
+			TrustedCAs: parent.trustedCAs,
+		},
 	}
 
 	return
@@ -435,6 +459,10 @@ func (r *ClusterRosaClassicResource) Create(ctx context.Context,
 		return
 	}
 
+	// Apply the create timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, state.Timeouts.CreateTimeout())
+	defer cancel()
+
 	object, err := createClassicClusterObject(ctx, state, r.logger, diags)
 	if err != nil {
 		response.Diagnostics.AddError(
@@ -446,7 +474,7 @@ func (r *ClusterRosaClassicResource) Create(ctx context.Context,
 		)
 		return
 	}
-	add, err := r.collection.Add().Body(object).SendContext(ctx)
+	object, err = r.create(ctx, object, state)
 	if err != nil {
 		response.Diagnostics.AddError(
 			"Can't create cluster",
@@ -457,14 +485,48 @@ func (r *ClusterRosaClassicResource) Create(ctx context.Context,
 		)
 		return
 	}
-	object = add.Body()
+
+	// Wait till the cluster is ready if explicitly requested:
+	wait := !state.Wait.Unknown && !state.Wait.Null && state.Wait.Value
+	ready := object.State() == cmv1.ClusterStateReady
+	if wait && !ready {
+		object, err = waitTillClusterReady(
+			ctx, r.collection.Cluster(object.ID()), state.Timeouts.Interval(),
+		)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't poll cluster state",
+				fmt.Sprintf(
+					"Can't poll state of cluster with identifier '%s': %v",
+					object.ID(), err,
+				),
+			)
+			return
+		}
+	}
 
 	// Save the state:
-	populateRosaClassicClusterState(ctx, object, state, r.logger, DefaultHttpClient{})
+	populateRosaClassicClusterState(ctx, object, state, r.httpClient, &response.Diagnostics)
 	diags = response.State.Set(ctx, state)
 	response.Diagnostics.Append(diags...)
 }
 
+// create sends the request to create the cluster. The SDK doesn't support OIDC configurations
+// yet, so when the cluster uses one the request is sent as raw JSON.
+func (r *ClusterRosaClassicResource) create(ctx context.Context, object *cmv1.Cluster,
+	state *ClusterRosaClassicState) (*cmv1.Cluster, error) {
+	if state.Sts != nil && !state.Sts.OIDCConfigID.Unknown && !state.Sts.OIDCConfigID.Null {
+		return createClusterWithOIDCConfig(
+			ctx, r.connection, object, state.Sts.OIDCConfigID.Value,
+		)
+	}
+	add, err := r.collection.Add().Body(object).SendContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return add.Body(), nil
+}
+
 func (r *ClusterRosaClassicResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest,
 	response *tfsdk.ReadResourceResponse) {
 	// Get the current state:
@@ -475,8 +537,9 @@ func (r *ClusterRosaClassicResource) Read(ctx context.Context, request tfsdk.Rea
 		return
 	}
 
-	// Find the cluster:
-	get, err := r.collection.Cluster(state.ID.Value).Get().SendContext(ctx)
+	// Find the cluster. This uses raw JSON because the SDK doesn't support the OIDC
+	// configuration of the cluster yet.
+	object, oidcConfigID, err := getClusterWithOIDCConfig(ctx, r.connection, state.ID.Value)
 	if err != nil {
 		response.Diagnostics.AddError(
 			"Can't find cluster",
@@ -487,10 +550,38 @@ func (r *ClusterRosaClassicResource) Read(ctx context.Context, request tfsdk.Rea
 		)
 		return
 	}
-	object := get.Body()
 
 	// Save the state:
-	populateRosaClassicClusterState(ctx, object, state, r.logger, DefaultHttpClient{})
+	requested := state.Version
+	populateRosaClassicClusterState(ctx, object, state, r.httpClient, &response.Diagnostics)
+
+	// While an upgrade is pending the server still reports the old version. In that case the
+	// state keeps the version requested by the user, otherwise every plan would try to upgrade
+	// the cluster again:
+	if !requested.Unknown && !requested.Null &&
+		rawVersion(requested.Value) != rawVersion(state.Version.Value) {
+		policy, err := findClusterUpgrade(
+			ctx, r.collection.Cluster(state.ID.Value), rawVersion(requested.Value),
+		)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't get cluster upgrades",
+				fmt.Sprintf(
+					"Can't get upgrade policies of cluster with identifier '%s': %v",
+					state.ID.Value, err,
+				),
+			)
+			return
+		}
+		if policy != nil {
+			state.Version = requested
+		}
+	}
+	if state.Sts != nil && oidcConfigID != "" {
+		state.Sts.OIDCConfigID = types.String{
+			Value: oidcConfigID,
+		}
+	}
 	diags = response.State.Set(ctx, state)
 	response.Diagnostics.Append(diags...)
 }
@@ -515,6 +606,21 @@ func (r *ClusterRosaClassicResource) Update(ctx context.Context, request tfsdk.U
 		return
 	}
 
+	// Apply the update timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, plan.Timeouts.UpdateTimeout())
+	defer cancel()
+
+	// Upgrade the cluster if the version has changed:
+	upgradePending := false
+	_, ok := shouldPatchString(state.Version, plan.Version)
+	if ok {
+		upgradePending, diags = r.upgradeCluster(ctx, state, plan)
+		response.Diagnostics.Append(diags...)
+		if response.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	// Send request to update the cluster:
 	updateNodes := false
 	clusterBuilder := cmv1.NewCluster()
@@ -583,15 +689,111 @@ func (r *ClusterRosaClassicResource) Update(ctx context.Context, request tfsdk.U
 	state.AutoScalingEnabled = plan.AutoScalingEnabled
 	// update the ComputeNodes with the plan value (important for nil and zero value cases)
 	state.ComputeNodes = plan.ComputeNodes
+	state.Wait = plan.Wait
+	state.Timeouts = plan.Timeouts
 
 	object := update.Body()
 
 	// Update the state:
-	populateRosaClassicClusterState(ctx, object, state, r.logger, DefaultHttpClient{})
+	populateRosaClassicClusterState(ctx, object, state, r.httpClient, &response.Diagnostics)
+
+	// If the upgrade has been scheduled but it hasn't completed yet the server still reports the
+	// old version, but the state should contain the version requested by the user:
+	if upgradePending {
+		state.Version = plan.Version
+	}
+
 	diags = response.State.Set(ctx, state)
 	response.Diagnostics.Append(diags...)
 }
 
+// upgradeCluster schedules the upgrade of the cluster to the version in the plan, and waits till it
+// completes if requested. It returns true if the upgrade has been scheduled but hasn't completed.
+func (r *ClusterRosaClassicResource) upgradeCluster(ctx context.Context,
+	state, plan *ClusterRosaClassicState) (pending bool, diags diag.Diagnostics) {
+	// Get the version that the cluster is currently running:
+	resource := r.collection.Cluster(state.ID.Value)
+	get, err := resource.Get().SendContext(ctx)
+	if err != nil {
+		diags.AddError(
+			"Can't find cluster",
+			fmt.Sprintf(
+				"Can't find cluster with identifier '%s': %v",
+				state.ID.Value, err,
+			),
+		)
+		return
+	}
+	currentID := get.Body().Version().ID()
+	current := rawVersion(currentID)
+	target := rawVersion(plan.Version.Value)
+	if current == target {
+		return
+	}
+
+	// Check that the requested version is one of the available upgrades:
+	available, err := getAvailableUpgrades(ctx, r.versions, currentID)
+	if err != nil {
+		diags.AddError(
+			"Can't get available upgrades",
+			fmt.Sprintf(
+				"Can't get available upgrades for version '%s': %v",
+				currentID, err,
+			),
+		)
+		return
+	}
+	err = checkUpgradeVersion(current, target, available)
+	if err != nil {
+		diags.AddAttributeError(
+			tftypes.NewAttributePath().WithAttributeName("version"),
+			"Can't upgrade cluster",
+			fmt.Sprintf(
+				"Can't upgrade cluster with identifier '%s': %v",
+				state.ID.Value, err,
+			),
+		)
+		return
+	}
+
+	// Schedule the upgrade:
+	policy, err := scheduleClusterUpgrade(ctx, resource, target)
+	if err != nil {
+		diags.AddError(
+			"Can't schedule cluster upgrade",
+			fmt.Sprintf(
+				"Can't schedule upgrade of cluster with identifier '%s' to "+
+					"version '%s': %v",
+				state.ID.Value, target, err,
+			),
+		)
+		return
+	}
+	r.logger.Debug(
+		ctx,
+		"Upgrade policy '%s' will upgrade cluster '%s' to version '%s' at %s",
+		policy.ID(), state.ID.Value, target, policy.NextRun(),
+	)
+
+	// Wait till the upgrade completes if explicitly requested:
+	if plan.Wait.Unknown || plan.Wait.Null || !plan.Wait.Value {
+		pending = true
+		return
+	}
+	_, err = waitTillClusterUpgraded(ctx, resource, target, plan.Timeouts.Interval())
+	if err != nil {
+		diags.AddError(
+			"Can't poll cluster upgrade",
+			fmt.Sprintf(
+				"Can't poll upgrade of cluster with identifier '%s' to "+
+					"version '%s': %v",
+				state.ID.Value, target, err,
+			),
+		)
+	}
+	return
+}
+
 func (r *ClusterRosaClassicResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest,
 	response *tfsdk.DeleteResourceResponse) {
 	// Get the state:
@@ -602,6 +804,10 @@ func (r *ClusterRosaClassicResource) Delete(ctx context.Context, request tfsdk.D
 		return
 	}
 
+	// Apply the delete timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, state.Timeouts.DeleteTimeout())
+	defer cancel()
+
 	// Send the request to delete the cluster:
 	resource := r.collection.Cluster(state.ID.Value)
 	_, err := resource.Delete().SendContext(ctx)
@@ -616,6 +822,21 @@ func (r *ClusterRosaClassicResource) Delete(ctx context.Context, request tfsdk.D
 		return
 	}
 
+	// Wait till the cluster has been effectively uninstalled if explicitly requested:
+	if !state.Wait.Unknown && !state.Wait.Null && state.Wait.Value {
+		err := waitTillClusterDeleted(ctx, resource, state.Timeouts.Interval())
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't poll cluster deletion",
+				fmt.Sprintf(
+					"Can't poll deletion of cluster with identifier '%s': %v",
+					state.ID.Value, err,
+				),
+			)
+			return
+		}
+	}
+
 	// Remove the state:
 	response.State.RemoveResource(ctx)
 }
@@ -638,7 +859,7 @@ func (r *ClusterRosaClassicResource) ImportState(ctx context.Context, request tf
 
 	// Save the state:
 	state := &ClusterRosaClassicState{}
-	err = populateRosaClassicClusterState(ctx, object, state, r.logger, DefaultHttpClient{})
+	err = populateRosaClassicClusterState(ctx, object, state, r.httpClient, &response.Diagnostics)
 	if err != nil {
 		response.Diagnostics.AddError(
 			"Can't populate cluster state",
@@ -654,7 +875,7 @@ func (r *ClusterRosaClassicResource) ImportState(ctx context.Context, request tf
 }
 
 // populateRosaClassicClusterState copies the data from the API object to the Terraform state.
-func populateRosaClassicClusterState(ctx context.Context, object *cmv1.Cluster, state *ClusterRosaClassicState, logger logging.Logger, httpClient HttpClient) error {
+func populateRosaClassicClusterState(ctx context.Context, object *cmv1.Cluster, state *ClusterRosaClassicState, httpClient HttpClient, diags *diag.Diagnostics) error {
 	state.ID = types.String{
 		Value: object.ID(),
 	}
@@ -759,13 +980,24 @@ func populateRosaClassicClusterState(ctx context.Context, object *cmv1.Cluster,
 	sts, ok := object.AWS().GetSTS()
 	if ok {
 		if state.Sts == nil {
-			state.Sts = &Sts{}
+			state.Sts = &Sts{
+				OIDCConfigID: types.String{
+					Null: true,
+				},
+			}
 		}
 		oidc_endpoint_url := sts.OIDCEndpointURL()
 		if strings.HasPrefix(oidc_endpoint_url, "https://") {
 			oidc_endpoint_url = strings.TrimPrefix(oidc_endpoint_url, "https://")
 		}
 
+		// The thumbprint only depends on the OIDC endpoint, so there is no need to connect
+		// to the endpoint again if it hasn't changed. That would slow down every refresh,
+		// specially when the endpoint isn't reachable and the connection is retried.
+		reuseThumbprint := !state.Sts.Thumbprint.Unknown && !state.Sts.Thumbprint.Null &&
+			state.Sts.Thumbprint.Value != "" &&
+			state.Sts.OIDCEndpointURL.Value == oidc_endpoint_url
+
 		state.Sts.OIDCEndpointURL = types.String{
 			Value: oidc_endpoint_url,
 		}
@@ -794,9 +1026,20 @@ func populateRosaClassicClusterState(ctx context.Context, object *cmv1.Cluster,
 				}
 			}
 		}
-		thumbprint, err := getThumbprint(sts.OIDCEndpointURL(), httpClient)
+		thumbprint := state.Sts.Thumbprint.Value
+		var err error
+		if !reuseThumbprint {
+			thumbprint, err = getThumbprint(ctx, sts.OIDCEndpointURL(), httpClient)
+		}
 		if err != nil {
-			logger.Error(ctx, "cannot get thumbprint", err)
+			diags.AddWarning(
+				"Can't get OIDC thumbprint",
+				fmt.Sprintf(
+					"Can't get the thumbprint of OIDC endpoint '%s', the "+
+						"'sts.thumbprint' attribute will be empty: %v",
+					sts.OIDCEndpointURL(), err,
+				),
+			)
 			state.Sts.Thumbprint = types.String{
 				Value: "",
 			}
@@ -883,68 +1126,6 @@ func populateRosaClassicClusterState(ctx context.Context, object *cmv1.Cluster,
 	return nil
 }
 
-type HttpClient interface {
-	Get(url string) (resp *http.Response, err error)
-}
-
-type DefaultHttpClient struct {
-}
-
-func (c DefaultHttpClient) Get(url string) (resp *http.Response, err error) {
-	return http.Get(url)
-}
-
-func getThumbprint(oidcEndpointURL string, httpClient HttpClient) (thumbprint string, err error) {
-	defer func() {
-		if panicErr := recover(); panicErr != nil {
-			fmt.Fprintf(os.Stderr, "recovering from: %q\n", panicErr)
-			thumbprint = ""
-			err = fmt.Errorf("recovering from: %q", panicErr)
-		}
-	}()
-
-	connect, err := url.ParseRequestURI(oidcEndpointURL)
-	if err != nil {
-		return "", err
-	}
-
-	response, err := httpClient.Get(fmt.Sprintf("https://%s:443", connect.Host))
-	if err != nil {
-		return "", err
-	}
-
-	certChain := response.TLS.PeerCertificates
-
-	// Grab the CA in the chain
-	for _, cert := range certChain {
-		if cert.IsCA {
-			if bytes.Equal(cert.RawIssuer, cert.RawSubject) {
-				hash, err := sha1Hash(cert.Raw)
-				if err != nil {
-					return "", err
-				}
-				return hash, nil
-			}
-		}
-	}
-
-	// Fall back to using the last certficiate in the chain
-	cert := certChain[len(certChain)-1]
-	return sha1Hash(cert.Raw)
-}
-
-// sha1Hash computes the SHA1 of the byte array and returns the hex encoding as a string.
-func sha1Hash(data []byte) (string, error) {
-	// nolint:gosec
-	hasher := sha1.New()
-	_, err := hasher.Write(data)
-	if err != nil {
-		return "", fmt.Errorf("Couldn't calculate hash:\n %v", err)
-	}
-	hashed := hasher.Sum(nil)
-	return hex.EncodeToString(hashed), nil
-}
-
 func checkSupportedVersion(clusterVersion string) (bool, error) {
 	rawID := strings.Replace(clusterVersion, "openshift-v", "", 1)
 	v1, err := semver.NewVersion(rawID)
@@ -36,7 +36,7 @@ type MockHttpClient struct {
 	response *http.Response
 }
 
-func (c MockHttpClient) Get(url string) (resp *http.Response, err error) {
+func (c MockHttpClient) Get(ctx context.Context, url string) (resp *http.Response, err error) {
 	return c.response, nil
 }
 
@@ -210,7 +210,7 @@ var _ = Describe("Rosa Classic Sts cluster", func() {
 			clusterObject, err := cmv1.UnmarshalCluster(clusterJsonString)
 			Expect(err).To(BeNil())
 
-			populateRosaClassicClusterState(context.Background(), clusterObject, clusterState, &logging.StdLogger{}, mockHttpClient)
+			populateRosaClassicClusterState(context.Background(), clusterObject, clusterState, mockHttpClient, &diag.Diagnostics{})
 
 			Expect(clusterState.ID.Value).To(Equal(clusterId))
 			Expect(clusterState.CloudRegion.Value).To(Equal(regionId))
@@ -241,7 +241,7 @@ var _ = Describe("Rosa Classic Sts cluster", func() {
 			clusterObject, err := cmv1.UnmarshalCluster(clusterJsonString)
 			Expect(err).To(BeNil())
 
-			err = populateRosaClassicClusterState(context.Background(), clusterObject, clusterState, &logging.StdLogger{}, mockHttpClient)
+			err = populateRosaClassicClusterState(context.Background(), clusterObject, clusterState, mockHttpClient, &diag.Diagnostics{})
 			Expect(err).To(BeNil())
 			Expect(clusterState.Sts.OIDCEndpointURL.Value).To(Equal("nonce.com"))
 		})
@@ -257,9 +257,33 @@ var _ = Describe("Rosa Classic Sts cluster", func() {
 			clusterObject, err := cmv1.UnmarshalCluster(clusterJsonString)
 			Expect(err).To(BeNil())
 
-			err = populateRosaClassicClusterState(context.Background(), clusterObject, clusterState, &logging.StdLogger{}, mockHttpClient)
+			diags := &diag.Diagnostics{}
+			err = populateRosaClassicClusterState(context.Background(), clusterObject, clusterState, mockHttpClient, diags)
 			Expect(err).To(BeNil())
 			Expect(clusterState.Sts.Thumbprint.Value).To(Equal(""))
+			Expect(diags.HasError()).To(BeFalse())
+			Expect(*diags).To(HaveLen(1))
+		})
+
+		It("Reuses the thumbprint when the oidc_endpoint_url doesn't change", func() {
+			clusterState := &ClusterRosaClassicState{
+				Sts: &Sts{
+					OIDCEndpointURL: types.String{Value: oidcEndpointUrl},
+					Thumbprint:      types.String{Value: "my-thumbprint"},
+				},
+			}
+			clusterJson := generateBasicRosaClassicClusterJson()
+			clusterJsonString, err := json.Marshal(clusterJson)
+			Expect(err).To(BeNil())
+
+			clusterObject, err := cmv1.UnmarshalCluster(clusterJsonString)
+			Expect(err).To(BeNil())
+
+			client := &FlakyHttpClient{failures: 3}
+			err = populateRosaClassicClusterState(context.Background(), clusterObject, clusterState, client, &diag.Diagnostics{})
+			Expect(err).To(BeNil())
+			Expect(clusterState.Sts.Thumbprint.Value).To(Equal("my-thumbprint"))
+			Expect(client.calls).To(BeEmpty())
 		})
 	})
 })
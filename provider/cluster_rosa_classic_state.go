@@ -48,10 +48,13 @@ type ClusterRosaClassicState struct {
 	Proxy              *Proxy       `tfsdk:"proxy"`
 	State              types.String `tfsdk:"state"`
 	Version            types.String `tfsdk:"version"`
+	Wait               types.Bool   `tfsdk:"wait"`
+	Timeouts           *Timeouts    `tfsdk:"timeouts"`
 }
 
 type Sts struct {
 	OIDCEndpointURL    types.String    `tfsdk:"oidc_endpoint_url"`
+	OIDCConfigID       types.String    `tfsdk:"oidc_config_id"`
 	Thumbprint         types.String    `tfsdk:"thumbprint"`
 	RoleARN            types.String    `tfsdk:"role_arn"`
 	SupportRoleArn     types.String    `tfsdk:"support_role_arn"`
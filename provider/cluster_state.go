@@ -47,6 +47,7 @@ type ClusterState struct {
 	State              types.String `tfsdk:"state"`
 	Version            types.String `tfsdk:"version"`
 	Wait               types.Bool   `tfsdk:"wait"`
+	Timeouts           *Timeouts    `tfsdk:"timeouts"`
 }
 
 type Proxy struct {
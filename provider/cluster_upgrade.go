@@ -0,0 +1,161 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	semver "github.com/hashicorp/go-version"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+const (
+	// versionPrefix is the prefix that the server adds to the raw versions to build the
+	// identifiers of the versions, for example `openshift-v4.10.5`.
+	versionPrefix = "openshift-v"
+
+	// upgradeScheduleDelay is the time between the creation of an upgrade policy and the time
+	// when the upgrade starts. The server rejects policies that are scheduled too close to the
+	// current time.
+	upgradeScheduleDelay = 10 * time.Minute
+
+	// Values of the schedule and upgrade types of upgrade policies:
+	upgradeScheduleTypeManual    = "manual"
+	upgradeScheduleTypeAutomatic = "automatic"
+	upgradeTypeOSD               = "OSD"
+)
+
+// rawVersion returns the raw version corresponding to the given version identifier, for example
+// for `openshift-v4.10.5` it returns `4.10.5`. Values that don't have the prefix are returned
+// without changes.
+func rawVersion(id string) string {
+	return strings.TrimPrefix(id, versionPrefix)
+}
+
+// checkUpgradeVersion checks that a cluster can be upgraded from the current version to the target
+// version. All the versions are raw versions, like `4.10.5`, and the available list contains the
+// versions that the server says that the current version can be upgraded to.
+func checkUpgradeVersion(current, target string, available []string) error {
+	currentVersion, err := semver.NewVersion(current)
+	if err != nil {
+		return fmt.Errorf("can't parse current version '%s': %v", current, err)
+	}
+	targetVersion, err := semver.NewVersion(target)
+	if err != nil {
+		return fmt.Errorf("can't parse target version '%s': %v", target, err)
+	}
+	if targetVersion.LessThan(currentVersion) {
+		return fmt.Errorf(
+			"version '%s' is older than the current version '%s', downgrades "+
+				"aren't supported",
+			target, current,
+		)
+	}
+	for _, candidate := range available {
+		if candidate == target {
+			return nil
+		}
+	}
+	if len(available) == 0 {
+		return fmt.Errorf(
+			"there are no upgrades available for current version '%s'",
+			current,
+		)
+	}
+	return fmt.Errorf(
+		"version '%s' isn't an available upgrade for current version '%s', "+
+			"available upgrades are %s",
+		target, current, strings.Join(available, ", "),
+	)
+}
+
+// getAvailableUpgrades returns the list of raw versions that the given version can be upgraded to.
+func getAvailableUpgrades(ctx context.Context, versions *cmv1.VersionsClient,
+	versionID string) (result []string, err error) {
+	get, err := versions.Version(versionID).Get().SendContext(ctx)
+	if err != nil {
+		return
+	}
+	result = get.Body().AvailableUpgrades()
+	return
+}
+
+// scheduleClusterUpgrade creates a manual upgrade policy that will upgrade the cluster to the given
+// raw version. If there is already a policy that upgrades the cluster to that same version then it
+// is returned instead of creating a new one. If there is a policy for a different version an error
+// is returned, as the server doesn't allow more than one.
+func scheduleClusterUpgrade(ctx context.Context, resource *cmv1.ClusterClient,
+	version string) (result *cmv1.UpgradePolicy, err error) {
+	collection := resource.UpgradePolicies()
+	list, err := collection.List().SendContext(ctx)
+	if err != nil {
+		return
+	}
+	list.Items().Each(func(policy *cmv1.UpgradePolicy) bool {
+		if policy.UpgradeType() != upgradeTypeOSD {
+			return true
+		}
+		if policy.Version() == version {
+			result = policy
+		} else {
+			err = fmt.Errorf(
+				"there is already an upgrade policy '%s' for version '%s'",
+				policy.ID(), policy.Version(),
+			)
+		}
+		return false
+	})
+	if result != nil || err != nil {
+		return
+	}
+	object, err := cmv1.NewUpgradePolicy().
+		ScheduleType(upgradeScheduleTypeManual).
+		UpgradeType(upgradeTypeOSD).
+		Version(version).
+		NextRun(time.Now().UTC().Add(upgradeScheduleDelay)).
+		Build()
+	if err != nil {
+		return
+	}
+	add, err := collection.Add().Body(object).SendContext(ctx)
+	if err != nil {
+		return
+	}
+	result = add.Body()
+	return
+}
+
+// findClusterUpgrade returns the upgrade policy that will upgrade the cluster to the given raw
+// version, or nil if there is no such policy.
+func findClusterUpgrade(ctx context.Context, resource *cmv1.ClusterClient,
+	version string) (result *cmv1.UpgradePolicy, err error) {
+	list, err := resource.UpgradePolicies().List().SendContext(ctx)
+	if err != nil {
+		return
+	}
+	list.Items().Each(func(policy *cmv1.UpgradePolicy) bool {
+		if policy.UpgradeType() == upgradeTypeOSD && policy.Version() == version {
+			result = policy
+			return false
+		}
+		return true
+	})
+	return
+}
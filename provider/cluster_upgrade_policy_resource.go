@@ -0,0 +1,834 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+// nodeDrainGracePeriodUnit is the unit used for the node drain grace period of clusters.
+const nodeDrainGracePeriodUnit = "minutes"
+
+type ClusterUpgradePolicyResourceType struct {
+	logger logging.Logger
+}
+
+type ClusterUpgradePolicyResource struct {
+	logger     logging.Logger
+	collection *cmv1.ClustersClient
+	versions   *cmv1.VersionsClient
+}
+
+func (t *ClusterUpgradePolicyResourceType) GetSchema(ctx context.Context) (result tfsdk.Schema,
+	diags diag.Diagnostics) {
+	result = tfsdk.Schema{
+		Description: "Upgrade policy of a cluster.",
+		Attributes: map[string]tfsdk.Attribute{
+			"cluster": {
+				Description: "Identifier of the cluster.",
+				Type:        types.StringType,
+				Required:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"id": {
+				Description: "Unique identifier of the upgrade policy.",
+				Type:        types.StringType,
+				Computed:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"schedule_type": {
+				Description: "Schedule type, can be 'manual' for a one-time " +
+					"upgrade at the time given in 'next_run', or 'automatic' " +
+					"for recurring upgrades following the cron expression " +
+					"given in 'schedule'.",
+				Type:     types.StringType,
+				Required: true,
+				Validators: []tfsdk.AttributeValidator{
+					EnumValidator(
+						upgradeScheduleTypeManual,
+						upgradeScheduleTypeAutomatic,
+					),
+				},
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"version": {
+				Description: "Version that the cluster will be upgraded to, for " +
+					"example `4.10.5`. Required for manual upgrades. For " +
+					"automatic upgrades it is calculated by the server.",
+				Type:     types.StringType,
+				Optional: true,
+				Computed: true,
+			},
+			"next_run": {
+				Description: "Time of the next upgrade, in RFC3339 format, for " +
+					"example `2022-06-01T20:00:00Z`. For manual upgrades the " +
+					"default is ten minutes after the creation of the policy. " +
+					"For automatic upgrades it is calculated by the server.",
+				Type:     types.StringType,
+				Optional: true,
+				Computed: true,
+			},
+			"schedule": {
+				Description: "Cron expression that defines when automatic " +
+					"upgrades will run, for example `0 20 * * 6`. Required " +
+					"for automatic upgrades.",
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"enable_minor_version_upgrades": {
+				Description: "Indicates if automatic upgrades can move the " +
+					"cluster to a new minor version.",
+				Type:     types.BoolType,
+				Optional: true,
+				Computed: true,
+			},
+			"node_drain_grace_period": {
+				Description: "Time in minutes that the upgrade will wait " +
+					"for pods protected by pod disruption budgets to be " +
+					"drained from each node. Note that this is a setting " +
+					"of the cluster, so it applies to all the upgrades, and " +
+					"it isn't reverted when the policy is deleted.",
+				Type:     types.Int64Type,
+				Optional: true,
+			},
+			"timeouts": {
+				Description: "Timeouts of the create, update and delete operations.",
+				Attributes:  timeoutsResource(),
+				Optional:    true,
+			},
+		},
+	}
+	return
+}
+
+func (t *ClusterUpgradePolicyResourceType) NewResource(ctx context.Context,
+	p tfsdk.Provider) (result tfsdk.Resource, diags diag.Diagnostics) {
+	// Cast the provider interface to the specific implementation: use it directly when needed.
+	parent := p.(*Provider)
+
+	// Get the collections of clusters and versions:
+	collection := parent.connection.ClustersMgmt().V1().Clusters()
+	versions := parent.connection.ClustersMgmt().V1().Versions()
+
+	// Create the resource:
+	result = &ClusterUpgradePolicyResource{
+		logger:     parent.logger,
+		collection: collection,
+		versions:   versions,
+	}
+
+	return
+}
+
+func (r *ClusterUpgradePolicyResource) Create(ctx context.Context,
+	request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	// Get the plan:
+	state := &ClusterUpgradePolicyState{}
+	diags := request.Plan.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Apply the create timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, state.Timeouts.CreateTimeout())
+	defer cancel()
+
+	// Wait till the cluster is ready:
+	resource := r.collection.Cluster(state.Cluster.Value)
+	cluster, err := waitTillClusterReady(ctx, resource, state.Timeouts.Interval())
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't poll cluster state",
+			fmt.Sprintf(
+				"Can't poll state of cluster with identifier '%s': %v",
+				state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+
+	// Build the upgrade policy:
+	builder, err := buildUpgradePolicy(state)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't build upgrade policy",
+			fmt.Sprintf(
+				"Can't build upgrade policy for cluster '%s': %v",
+				state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+
+	// For manual upgrades check that the version is one of the upgrades available for the
+	// current version of the cluster, as otherwise the error returned by the server isn't
+	// very helpful:
+	if state.ScheduleType.Value == upgradeScheduleTypeManual {
+		r.checkVersion(ctx, cluster, state.Version.Value, &response.Diagnostics)
+		if response.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Set the node drain grace period of the cluster:
+	if !state.NodeDrainGracePeriod.Unknown && !state.NodeDrainGracePeriod.Null {
+		err = updateNodeDrainGracePeriod(ctx, resource, state.NodeDrainGracePeriod.Value)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't update node drain grace period",
+				fmt.Sprintf(
+					"Can't update node drain grace period of cluster '%s': %v",
+					state.Cluster.Value, err,
+				),
+			)
+			return
+		}
+	}
+
+	// Create the upgrade policy:
+	object, err := builder.Build()
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't build upgrade policy",
+			fmt.Sprintf(
+				"Can't build upgrade policy for cluster '%s': %v",
+				state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+	add, err := resource.UpgradePolicies().Add().Body(object).SendContext(ctx)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't create upgrade policy",
+			fmt.Sprintf(
+				"Can't create upgrade policy for cluster '%s': %v",
+				state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+	object = add.Body()
+
+	// Save the state:
+	r.populateState(object, state)
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *ClusterUpgradePolicyResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest,
+	response *tfsdk.ReadResourceResponse) {
+	// Get the current state:
+	state := &ClusterUpgradePolicyState{}
+	diags := request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Find the upgrade policy:
+	resource := r.collection.Cluster(state.Cluster.Value)
+	get, err := resource.UpgradePolicies().
+		UpgradePolicy(state.ID.Value).
+		Get().
+		SendContext(ctx)
+	if err != nil && get != nil && get.Status() == http.StatusNotFound {
+		// The server deletes manual upgrade policies once the upgrade has been completed.
+		// In that case we keep the state as it is, otherwise Terraform would try to create
+		// the policy again. In any other case the policy has been removed outside of
+		// Terraform, so we remove it from the state.
+		completed, err := r.upgradeCompleted(ctx, resource, state)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't find cluster",
+				fmt.Sprintf(
+					"Can't find cluster with identifier '%s': %v",
+					state.Cluster.Value, err,
+				),
+			)
+			return
+		}
+		if !completed {
+			r.logger.Warn(
+				ctx,
+				"Upgrade policy '%s' of cluster '%s' not found, removing from state",
+				state.ID.Value, state.Cluster.Value,
+			)
+			response.State.RemoveResource(ctx)
+		}
+		return
+	}
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't find upgrade policy",
+			fmt.Sprintf(
+				"Can't find upgrade policy with identifier '%s' for "+
+					"cluster '%s': %v",
+				state.ID.Value, state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+	object := get.Body()
+
+	// Read the node drain grace period from the cluster, but only if it is managed by this
+	// resource:
+	if !state.NodeDrainGracePeriod.Null {
+		value, err := readNodeDrainGracePeriod(ctx, resource)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't find cluster",
+				fmt.Sprintf(
+					"Can't find cluster with identifier '%s': %v",
+					state.Cluster.Value, err,
+				),
+			)
+			return
+		}
+		state.NodeDrainGracePeriod = value
+	}
+
+	// Save the state:
+	r.populateState(object, state)
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *ClusterUpgradePolicyResource) ModifyPlan(ctx context.Context,
+	request tfsdk.ModifyResourcePlanRequest, response *tfsdk.ModifyResourcePlanResponse) {
+	// Nothing to do when the policy is being created or deleted:
+	if request.State.Raw.IsNull() || request.Plan.Raw.IsNull() {
+		return
+	}
+
+	// Get the state and the plan:
+	state := &ClusterUpgradePolicyState{}
+	diags := request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+	plan := &ClusterUpgradePolicyState{}
+	diags = request.Plan.Get(ctx, plan)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// When the version of a manual policy changes the update may need to create a new policy,
+	// because the server deletes manual policies once the upgrade has been completed, so the
+	// identifier is only known after the update:
+	if plan.ScheduleType.Value == upgradeScheduleTypeManual && !plan.Version.Unknown &&
+		!plan.Version.Equal(state.Version) {
+		diags = response.Plan.SetAttribute(
+			ctx,
+			tftypes.NewAttributePath().WithAttributeName("id"),
+			types.String{
+				Unknown: true,
+			},
+		)
+		response.Diagnostics.Append(diags...)
+	}
+}
+
+// checkVersion checks that the given version is one of the upgrades available for the current
+// version of the cluster, as otherwise the error returned by the server isn't very helpful.
+func (r *ClusterUpgradePolicyResource) checkVersion(ctx context.Context, cluster *cmv1.Cluster,
+	version string, diags *diag.Diagnostics) {
+	available, err := getAvailableUpgrades(ctx, r.versions, cluster.Version().ID())
+	if err != nil {
+		diags.AddError(
+			"Can't get available upgrades",
+			fmt.Sprintf(
+				"Can't get available upgrades for version '%s': %v",
+				cluster.Version().ID(), err,
+			),
+		)
+		return
+	}
+	err = checkUpgradeVersion(rawVersion(cluster.Version().ID()), version, available)
+	if err != nil {
+		diags.AddAttributeError(
+			tftypes.NewAttributePath().WithAttributeName("version"),
+			"Can't upgrade cluster",
+			fmt.Sprintf(
+				"Can't upgrade cluster '%s': %v",
+				cluster.ID(), err,
+			),
+		)
+	}
+}
+
+func (r *ClusterUpgradePolicyResource) Update(ctx context.Context,
+	request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	var diags diag.Diagnostics
+
+	// Get the state:
+	state := &ClusterUpgradePolicyState{}
+	diags = request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Get the plan:
+	plan := &ClusterUpgradePolicyState{}
+	diags = request.Plan.Get(ctx, plan)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Apply the update timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, plan.Timeouts.UpdateTimeout())
+	defer cancel()
+
+	// Check that the plan is consistent with the schedule type:
+	replacement, err := buildUpgradePolicy(plan)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't update upgrade policy",
+			fmt.Sprintf(
+				"Can't update upgrade policy '%s' for cluster '%s': %v",
+				state.ID.Value, state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+
+	// For manual upgrades check that the new version is one of the upgrades available for the
+	// current version of the cluster, like when the policy is created. This needs to happen
+	// before anything is changed, so that a rejected version doesn't leave the cluster
+	// partially updated.
+	resource := r.collection.Cluster(state.Cluster.Value)
+	version, versionChanged := shouldPatchString(state.Version, plan.Version)
+	if versionChanged && state.ScheduleType.Value == upgradeScheduleTypeManual {
+		get, err := resource.Get().SendContext(ctx)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't find cluster",
+				fmt.Sprintf(
+					"Can't find cluster with identifier '%s': %v",
+					state.Cluster.Value, err,
+				),
+			)
+			return
+		}
+		r.checkVersion(ctx, get.Body(), version, &response.Diagnostics)
+		if response.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Update the node drain grace period of the cluster:
+	value, ok := shouldPatchInt(state.NodeDrainGracePeriod, plan.NodeDrainGracePeriod)
+	if ok {
+		err = updateNodeDrainGracePeriod(ctx, resource, value)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't update node drain grace period",
+				fmt.Sprintf(
+					"Can't update node drain grace period of cluster '%s': %v",
+					state.Cluster.Value, err,
+				),
+			)
+			return
+		}
+	}
+
+	// Send the patch for the attributes of the policy that have changed:
+	builder := cmv1.NewUpgradePolicy()
+	changed := false
+	if versionChanged {
+		builder.Version(version)
+		changed = true
+	}
+	schedule, ok := shouldPatchString(state.Schedule, plan.Schedule)
+	if ok {
+		builder.Schedule(schedule)
+		changed = true
+	}
+	if !plan.NextRun.Unknown && !plan.NextRun.Null &&
+		!sameTime(state.NextRun.Value, plan.NextRun.Value) {
+		nextRun, err := time.Parse(time.RFC3339, plan.NextRun.Value)
+		if err != nil {
+			response.Diagnostics.AddAttributeError(
+				tftypes.NewAttributePath().WithAttributeName("next_run"),
+				"Invalid next run time",
+				fmt.Sprintf("Can't parse next run time '%s': %v", plan.NextRun.Value, err),
+			)
+			return
+		}
+		builder.NextRun(nextRun)
+		changed = true
+	}
+	if !plan.EnableMinorVersionUpgrades.Unknown && !plan.EnableMinorVersionUpgrades.Null &&
+		plan.EnableMinorVersionUpgrades.Value != state.EnableMinorVersionUpgrades.Value {
+		builder.EnableMinorVersionUpgrades(plan.EnableMinorVersionUpgrades.Value)
+		changed = true
+	}
+	// The server deletes manual upgrade policies once the upgrade has been completed, and
+	// then the state still contains the deleted policy. When that happens and something has
+	// changed, usually the version for the next upgrade, a new policy is created instead.
+	manual := state.ScheduleType.Value == upgradeScheduleTypeManual
+	policy := resource.UpgradePolicies().UpgradePolicy(state.ID.Value)
+	var object *cmv1.UpgradePolicy
+	if changed {
+		patch, err := builder.Build()
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't build upgrade policy patch",
+				fmt.Sprintf(
+					"Can't build patch for upgrade policy '%s' of cluster '%s': %v",
+					state.ID.Value, state.Cluster.Value, err,
+				),
+			)
+			return
+		}
+		update, err := policy.Update().Body(patch).SendContext(ctx)
+		switch {
+		case err != nil && manual && update != nil &&
+			update.Status() == http.StatusNotFound:
+			object, err = r.createReplacement(ctx, resource, replacement)
+			if err != nil {
+				response.Diagnostics.AddError(
+					"Can't create upgrade policy",
+					fmt.Sprintf(
+						"Can't create upgrade policy to replace the completed "+
+							"policy '%s' of cluster '%s': %v",
+						state.ID.Value, state.Cluster.Value, err,
+					),
+				)
+				return
+			}
+		case err != nil:
+			response.Diagnostics.AddError(
+				"Can't update upgrade policy",
+				fmt.Sprintf(
+					"Can't update upgrade policy '%s' of cluster '%s': %v",
+					state.ID.Value, state.Cluster.Value, err,
+				),
+			)
+			return
+		default:
+			object = update.Body()
+		}
+	} else {
+		get, err := policy.Get().SendContext(ctx)
+		if err != nil && manual && get != nil && get.Status() == http.StatusNotFound {
+			// The upgrade has been completed and nothing in the policy has changed, so
+			// there is nothing to update:
+			state.NodeDrainGracePeriod = plan.NodeDrainGracePeriod
+			state.Timeouts = plan.Timeouts
+			diags = response.State.Set(ctx, state)
+			response.Diagnostics.Append(diags...)
+			return
+		}
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't find upgrade policy",
+				fmt.Sprintf(
+					"Can't find upgrade policy with identifier '%s' for "+
+						"cluster '%s': %v",
+					state.ID.Value, state.Cluster.Value, err,
+				),
+			)
+			return
+		}
+		object = get.Body()
+	}
+
+	// Save the state:
+	state.Schedule = plan.Schedule
+	state.NextRun = plan.NextRun
+	state.NodeDrainGracePeriod = plan.NodeDrainGracePeriod
+	state.Timeouts = plan.Timeouts
+	r.populateState(object, state)
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+// createReplacement creates a new upgrade policy to replace a manual policy that has been deleted
+// by the server because the upgrade has been completed.
+func (r *ClusterUpgradePolicyResource) createReplacement(ctx context.Context,
+	resource *cmv1.ClusterClient, builder *cmv1.UpgradePolicyBuilder) (result *cmv1.UpgradePolicy,
+	err error) {
+	object, err := builder.Build()
+	if err != nil {
+		return
+	}
+	add, err := resource.UpgradePolicies().Add().Body(object).SendContext(ctx)
+	if err != nil {
+		return
+	}
+	result = add.Body()
+	return
+}
+
+func (r *ClusterUpgradePolicyResource) Delete(ctx context.Context,
+	request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	// Get the state:
+	state := &ClusterUpgradePolicyState{}
+	diags := request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Apply the delete timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, state.Timeouts.DeleteTimeout())
+	defer cancel()
+
+	// Send the request to delete the upgrade policy. Note that the policy may have already
+	// been removed by the server if it was a manual upgrade that has been completed.
+	resource := r.collection.Cluster(state.Cluster.Value).
+		UpgradePolicies().
+		UpgradePolicy(state.ID.Value)
+	remove, err := resource.Delete().SendContext(ctx)
+	if err != nil && (remove == nil || remove.Status() != http.StatusNotFound) {
+		response.Diagnostics.AddError(
+			"Can't delete upgrade policy",
+			fmt.Sprintf(
+				"Can't delete upgrade policy with identifier '%s' for "+
+					"cluster '%s': %v",
+				state.ID.Value, state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+
+	// Remove the state:
+	response.State.RemoveResource(ctx)
+}
+
+func (r *ClusterUpgradePolicyResource) ImportState(ctx context.Context,
+	request tfsdk.ImportResourceStateRequest, response *tfsdk.ImportResourceStateResponse) {
+	// The identifier of the policy is only unique within the cluster, so the import
+	// identifier must contain both:
+	parts, err := parseImportID(request.ID, "cluster_id", "policy_id")
+	if err != nil {
+		response.Diagnostics.AddError("Invalid import identifier", err.Error())
+		return
+	}
+	diags := response.State.SetAttribute(
+		ctx,
+		tftypes.NewAttributePath().WithAttributeName("cluster"),
+		parts[0],
+	)
+	response.Diagnostics.Append(diags...)
+	diags = response.State.SetAttribute(
+		ctx,
+		tftypes.NewAttributePath().WithAttributeName("id"),
+		parts[1],
+	)
+	response.Diagnostics.Append(diags...)
+}
+
+// upgradeCompleted checks if the cluster is already running the version of a manual upgrade
+// policy.
+func (r *ClusterUpgradePolicyResource) upgradeCompleted(ctx context.Context,
+	resource *cmv1.ClusterClient, state *ClusterUpgradePolicyState) (result bool, err error) {
+	if state.ScheduleType.Value != upgradeScheduleTypeManual {
+		return
+	}
+	get, err := resource.Get().SendContext(ctx)
+	if err != nil {
+		return
+	}
+	result = rawVersion(get.Body().Version().ID()) == state.Version.Value
+	return
+}
+
+// populateState copies the data from the API object to the Terraform state.
+func (r *ClusterUpgradePolicyResource) populateState(object *cmv1.UpgradePolicy,
+	state *ClusterUpgradePolicyState) {
+	state.ID = types.String{
+		Value: object.ID(),
+	}
+	clusterID, ok := object.GetClusterID()
+	if ok && clusterID != "" {
+		state.Cluster = types.String{
+			Value: clusterID,
+		}
+	}
+	state.ScheduleType = types.String{
+		Value: object.ScheduleType(),
+	}
+	version, ok := object.GetVersion()
+	if ok && version != "" {
+		state.Version = types.String{
+			Value: version,
+		}
+	} else {
+		state.Version = types.String{
+			Null: true,
+		}
+	}
+	schedule, ok := object.GetSchedule()
+	if ok && schedule != "" {
+		state.Schedule = types.String{
+			Value: schedule,
+		}
+	} else {
+		state.Schedule = types.String{
+			Null: true,
+		}
+	}
+	state.EnableMinorVersionUpgrades = types.Bool{
+		Value: object.EnableMinorVersionUpgrades(),
+	}
+
+	// Preserve the time written by the user if it is the same instant returned by the server,
+	// as it may use a different time zone or format:
+	nextRun, ok := object.GetNextRun()
+	if ok && !nextRun.IsZero() {
+		value := nextRun.UTC().Format(time.RFC3339)
+		if state.NextRun.Unknown || state.NextRun.Null ||
+			!sameTime(state.NextRun.Value, value) {
+			state.NextRun = types.String{
+				Value: value,
+			}
+		}
+	} else {
+		state.NextRun = types.String{
+			Null: true,
+		}
+	}
+}
+
+// buildUpgradePolicy checks that the attributes given by the user are consistent with the
+// schedule type and creates the builder for the corresponding upgrade policy.
+func buildUpgradePolicy(state *ClusterUpgradePolicyState) (builder *cmv1.UpgradePolicyBuilder,
+	err error) {
+	builder = cmv1.NewUpgradePolicy().
+		ScheduleType(state.ScheduleType.Value).
+		UpgradeType(upgradeTypeOSD)
+	hasVersion := !state.Version.Unknown && !state.Version.Null
+	hasSchedule := !state.Schedule.Unknown && !state.Schedule.Null
+	hasNextRun := !state.NextRun.Unknown && !state.NextRun.Null
+	switch state.ScheduleType.Value {
+	case upgradeScheduleTypeManual:
+		if !hasVersion {
+			err = errors.New("manual upgrades require a 'version'")
+			return
+		}
+		if hasSchedule {
+			err = errors.New("manual upgrades can't have a 'schedule'")
+			return
+		}
+		builder.Version(state.Version.Value)
+		nextRun := time.Now().UTC().Add(upgradeScheduleDelay)
+		if hasNextRun {
+			nextRun, err = time.Parse(time.RFC3339, state.NextRun.Value)
+			if err != nil {
+				err = fmt.Errorf("can't parse next run time '%s': %v", state.NextRun.Value, err)
+				return
+			}
+		}
+		builder.NextRun(nextRun)
+	case upgradeScheduleTypeAutomatic:
+		if !hasSchedule {
+			err = errors.New("automatic upgrades require a 'schedule'")
+			return
+		}
+		if hasVersion {
+			err = errors.New("automatic upgrades can't have a 'version'")
+			return
+		}
+		if hasNextRun {
+			err = errors.New("automatic upgrades can't have a 'next_run'")
+			return
+		}
+		builder.Schedule(state.Schedule.Value)
+	default:
+		err = fmt.Errorf(
+			"schedule type should be '%s' or '%s' but it is '%s'",
+			upgradeScheduleTypeManual, upgradeScheduleTypeAutomatic,
+			state.ScheduleType.Value,
+		)
+		return
+	}
+	if !state.EnableMinorVersionUpgrades.Unknown && !state.EnableMinorVersionUpgrades.Null {
+		builder.EnableMinorVersionUpgrades(state.EnableMinorVersionUpgrades.Value)
+	}
+	return
+}
+
+// sameTime checks if the given RFC3339 times represent the same instant. Values that can't be
+// parsed are compared as strings.
+func sameTime(a, b string) bool {
+	ta, err := time.Parse(time.RFC3339, a)
+	if err != nil {
+		return a == b
+	}
+	tb, err := time.Parse(time.RFC3339, b)
+	if err != nil {
+		return a == b
+	}
+	return ta.Equal(tb)
+}
+
+// updateNodeDrainGracePeriod sets the node drain grace period of the cluster, in minutes.
+func updateNodeDrainGracePeriod(ctx context.Context, resource *cmv1.ClusterClient,
+	minutes int64) error {
+	patch, err := cmv1.NewCluster().
+		NodeDrainGracePeriod(
+			cmv1.NewValue().
+				Value(float64(minutes)).
+				Unit(nodeDrainGracePeriodUnit),
+		).
+		Build()
+	if err != nil {
+		return err
+	}
+	_, err = resource.Update().Body(patch).SendContext(ctx)
+	return err
+}
+
+// readNodeDrainGracePeriod returns the node drain grace period of the cluster, in minutes.
+func readNodeDrainGracePeriod(ctx context.Context, resource *cmv1.ClusterClient) (
+	result types.Int64, err error) {
+	get, err := resource.Get().SendContext(ctx)
+	if err != nil {
+		return
+	}
+	period, ok := get.Body().GetNodeDrainGracePeriod()
+	if !ok {
+		result.Null = true
+		return
+	}
+	result.Value = int64(period.Value())
+	return
+}
@@ -0,0 +1,122 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	. "github.com/onsi/ginkgo/v2/dsl/core"  // nolint
+	. "github.com/onsi/ginkgo/v2/dsl/table" // nolint
+	. "github.com/onsi/gomega"              // nolint
+)
+
+var _ = Describe("Upgrade policy", func() {
+	DescribeTable("Checks the attributes for the schedule type",
+		func(state *ClusterUpgradePolicyState, valid bool) {
+			_, err := buildUpgradePolicy(state)
+			if valid {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry(
+			"Manual with version",
+			&ClusterUpgradePolicyState{
+				ScheduleType: types.String{Value: "manual"},
+				Version:      types.String{Value: "4.10.3"},
+				NextRun:      types.String{Value: "2040-01-01T20:00:00Z"},
+				Schedule:     types.String{Null: true},
+			},
+			true,
+		),
+		Entry(
+			"Manual without next run",
+			&ClusterUpgradePolicyState{
+				ScheduleType: types.String{Value: "manual"},
+				Version:      types.String{Value: "4.10.3"},
+				NextRun:      types.String{Unknown: true},
+				Schedule:     types.String{Null: true},
+			},
+			true,
+		),
+		Entry(
+			"Manual without version",
+			&ClusterUpgradePolicyState{
+				ScheduleType: types.String{Value: "manual"},
+				Version:      types.String{Unknown: true},
+				NextRun:      types.String{Unknown: true},
+				Schedule:     types.String{Null: true},
+			},
+			false,
+		),
+		Entry(
+			"Manual with invalid next run",
+			&ClusterUpgradePolicyState{
+				ScheduleType: types.String{Value: "manual"},
+				Version:      types.String{Value: "4.10.3"},
+				NextRun:      types.String{Value: "tomorrow"},
+				Schedule:     types.String{Null: true},
+			},
+			false,
+		),
+		Entry(
+			"Automatic with schedule",
+			&ClusterUpgradePolicyState{
+				ScheduleType: types.String{Value: "automatic"},
+				Version:      types.String{Unknown: true},
+				NextRun:      types.String{Unknown: true},
+				Schedule:     types.String{Value: "0 20 * * 6"},
+			},
+			true,
+		),
+		Entry(
+			"Automatic with version",
+			&ClusterUpgradePolicyState{
+				ScheduleType: types.String{Value: "automatic"},
+				Version:      types.String{Value: "4.10.3"},
+				NextRun:      types.String{Unknown: true},
+				Schedule:     types.String{Value: "0 20 * * 6"},
+			},
+			false,
+		),
+		Entry(
+			"Automatic without schedule",
+			&ClusterUpgradePolicyState{
+				ScheduleType: types.String{Value: "automatic"},
+				Version:      types.String{Unknown: true},
+				NextRun:      types.String{Unknown: true},
+				Schedule:     types.String{Null: true},
+			},
+			false,
+		),
+		Entry(
+			"Unknown schedule type",
+			&ClusterUpgradePolicyState{
+				ScheduleType: types.String{Value: "weekly"},
+				Version:      types.String{Unknown: true},
+				NextRun:      types.String{Unknown: true},
+				Schedule:     types.String{Value: "0 20 * * 6"},
+			},
+			false,
+		),
+	)
+
+	It("Compares times in different time zones", func() {
+		Expect(sameTime("2040-01-01T20:00:00Z", "2040-01-01T22:00:00+02:00")).To(BeTrue())
+		Expect(sameTime("2040-01-01T20:00:00Z", "2040-01-01T20:00:00+02:00")).To(BeFalse())
+	})
+})
@@ -0,0 +1,58 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	. "github.com/onsi/ginkgo/v2/dsl/core" // nolint
+	. "github.com/onsi/gomega"             // nolint
+)
+
+var _ = Describe("Cluster upgrade", func() {
+	available := []string{"4.10.5", "4.10.6", "4.11.0"}
+
+	It("Accepts an available upgrade", func() {
+		Expect(checkUpgradeVersion("4.10.4", "4.10.6", available)).To(Succeed())
+		Expect(checkUpgradeVersion("4.10.4", "4.11.0", available)).To(Succeed())
+	})
+
+	It("Rejects a downgrade", func() {
+		err := checkUpgradeVersion("4.10.4", "4.10.3", available)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("downgrades"))
+	})
+
+	It("Rejects a version that isn't an available upgrade", func() {
+		err := checkUpgradeVersion("4.10.4", "4.12.0", available)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("4.10.5, 4.10.6, 4.11.0"))
+	})
+
+	It("Rejects any version when there are no upgrades available", func() {
+		err := checkUpgradeVersion("4.10.4", "4.10.5", nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Rejects a version that can't be parsed", func() {
+		err := checkUpgradeVersion("4.10.4", "junk", available)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Removes the prefix from version identifiers", func() {
+		Expect(rawVersion("openshift-v4.10.5")).To(Equal("4.10.5"))
+		Expect(rawVersion("4.10.5")).To(Equal("4.10.5"))
+	})
+})
@@ -0,0 +1,67 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type enumValidator struct {
+	values []string
+}
+
+// EnumValidator returns an attribute validator that checks that the value of a string attribute
+// is one of the given values.
+func EnumValidator(values ...string) tfsdk.AttributeValidator {
+	return enumValidator{
+		values: values,
+	}
+}
+
+func (v enumValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("The value must be one of %s.", quoteNames(v.values))
+}
+
+func (v enumValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v enumValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest,
+	resp *tfsdk.ValidateAttributeResponse) {
+	value, ok := req.AttributeConfig.(types.String)
+	if !ok || value.Unknown || value.Null {
+		return
+	}
+	for _, allowed := range v.values {
+		if value.Value == allowed {
+			return
+		}
+	}
+	resp.Diagnostics.AddAttributeError(
+		req.AttributePath,
+		"Invalid value",
+		fmt.Sprintf(
+			"Value '%s' isn't valid, it must be one of %s",
+			value.Value, strings.Join(v.values, ", "),
+		),
+	)
+}
@@ -0,0 +1,46 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	. "github.com/onsi/ginkgo/v2/dsl/table" // nolint
+	. "github.com/onsi/gomega"              // nolint
+)
+
+var _ = DescribeTable("Validates enumerated values",
+	func(value attr.Value, valid bool) {
+		request := tfsdk.ValidateAttributeRequest{
+			AttributePath:   tftypes.NewAttributePath().WithAttributeName("mapping_method"),
+			AttributeConfig: value,
+		}
+		response := &tfsdk.ValidateAttributeResponse{}
+		EnumValidator("claim", "lookup").Validate(context.Background(), request, response)
+		Expect(response.Diagnostics.HasError()).To(Equal(!valid))
+	},
+	Entry("First value", types.String{Value: "claim"}, true),
+	Entry("Second value", types.String{Value: "lookup"}, true),
+	Entry("Null", types.String{Null: true}, true),
+	Entry("Unknown", types.String{Unknown: true}, true),
+	Entry("Different case", types.String{Value: "Claim"}, false),
+	Entry("Other value", types.String{Value: "junk"}, false),
+)
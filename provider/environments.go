@@ -0,0 +1,166 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// OCMEnvironment contains the URLs of one of the well known OCM environments.
+type OCMEnvironment struct {
+	URL      string
+	TokenURL string
+}
+
+// defaultTokenURL is the URL of the Red Hat single sign on service. It is used by all the well
+// known environments.
+const defaultTokenURL = "https://sso.redhat.com/auth/realms/redhat-external/protocol/" +
+	"openid-connect/token"
+
+// ocmEnvironments contains the well known OCM environments, indexed by the names accepted by
+// the `environment` attribute of the provider.
+var ocmEnvironments = map[string]OCMEnvironment{
+	"production": {
+		URL:      "https://api.openshift.com",
+		TokenURL: defaultTokenURL,
+	},
+	"staging": {
+		URL:      "https://api.stage.openshift.com",
+		TokenURL: defaultTokenURL,
+	},
+	"integration": {
+		URL:      "https://api.integration.openshift.com",
+		TokenURL: defaultTokenURL,
+	},
+}
+
+// ocmEnvironmentNames returns the sorted names of the well known OCM environments.
+func ocmEnvironmentNames() []string {
+	names := make([]string, 0, len(ocmEnvironments))
+	for name := range ocmEnvironments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ocmURLs calculates the URLs of the API and token servers from the `url`, `token_url` and
+// `environment` attributes and the corresponding environment variables. Attributes take
+// precedence over environment variables, and within each of them explicit URLs take precedence
+// over the URLs of the named environment. The results are empty if they aren't set anywhere.
+func ocmURLs(url, tokenURL, environment types.String) (apiURL string, ssoURL string,
+	err error) {
+	sources := []struct {
+		url         func() (string, bool)
+		tokenURL    func() (string, bool)
+		environment func() (string, bool)
+		description string
+	}{
+		{
+			url:         func() (string, bool) { return attributeSetting(url) },
+			tokenURL:    func() (string, bool) { return attributeSetting(tokenURL) },
+			environment: func() (string, bool) { return attributeSetting(environment) },
+			description: "value of the 'environment' attribute",
+		},
+		{
+			url:         func() (string, bool) { return os.LookupEnv("OCM_URL") },
+			tokenURL:    func() (string, bool) { return os.LookupEnv("OCM_TOKEN_URL") },
+			environment: func() (string, bool) { return os.LookupEnv("OCM_ENVIRONMENT") },
+			description: "value of the 'OCM_ENVIRONMENT' environment variable",
+		},
+	}
+	for _, source := range sources {
+		if value, ok := source.url(); ok && apiURL == "" {
+			apiURL = value
+		}
+		if value, ok := source.tokenURL(); ok && ssoURL == "" {
+			ssoURL = value
+		}
+		name, ok := source.environment()
+		if !ok {
+			continue
+		}
+		known, ok := ocmEnvironments[name]
+		if !ok {
+			err = fmt.Errorf(
+				"%s '%s' isn't a known environment, valid values are %s",
+				source.description, name, quoteNames(ocmEnvironmentNames()),
+			)
+			return
+		}
+		if apiURL == "" {
+			apiURL = known.URL
+		}
+		if ssoURL == "" {
+			ssoURL = known.TokenURL
+		}
+	}
+	return
+}
+
+// attributeSetting returns the value of a string attribute of the provider configuration, if
+// it is set.
+func attributeSetting(value types.String) (result string, ok bool) {
+	if value.Unknown || value.Null {
+		return
+	}
+	return value.Value, true
+}
+
+// stringSetting returns the value of a string attribute of the provider configuration or, if
+// it isn't set, the value of the given environment variable. The source is a description of
+// where the value was found, intended for log messages.
+func stringSetting(value types.String, env string) (result string, source string, ok bool) {
+	if !value.Unknown && !value.Null {
+		result = value.Value
+		source = "provider configuration"
+		ok = true
+		return
+	}
+	result, ok = os.LookupEnv(env)
+	if ok {
+		source = fmt.Sprintf("'%s' environment variable", env)
+	}
+	return
+}
+
+// boolSetting is like stringSetting, but for boolean attributes. It fails if the value of the
+// environment variable isn't a valid boolean.
+func boolSetting(value types.Bool, env string) (result bool, ok bool, err error) {
+	if !value.Unknown && !value.Null {
+		result = value.Value
+		ok = true
+		return
+	}
+	text, ok := os.LookupEnv(env)
+	if !ok {
+		return
+	}
+	result, err = strconv.ParseBool(text)
+	if err != nil {
+		err = fmt.Errorf(
+			"value '%s' of the '%s' environment variable isn't a valid boolean",
+			text, env,
+		)
+	}
+	return
+}
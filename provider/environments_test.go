@@ -0,0 +1,127 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	. "github.com/onsi/ginkgo/v2/dsl/core" // nolint
+	. "github.com/onsi/gomega"             // nolint
+)
+
+var _ = Describe("Provider settings", func() {
+	const env = "OCM_TEST_SETTING"
+
+	AfterEach(func() {
+		os.Unsetenv(env)
+		os.Unsetenv("OCM_URL")
+		os.Unsetenv("OCM_TOKEN_URL")
+		os.Unsetenv("OCM_ENVIRONMENT")
+	})
+
+	It("Prefers the attribute to the environment variable", func() {
+		os.Setenv(env, "from-env")
+		value, source, ok := stringSetting(types.String{Value: "from-attribute"}, env)
+		Expect(ok).To(BeTrue())
+		Expect(value).To(Equal("from-attribute"))
+		Expect(source).To(Equal("provider configuration"))
+	})
+
+	It("Falls back to the environment variable", func() {
+		os.Setenv(env, "from-env")
+		value, source, ok := stringSetting(types.String{Null: true}, env)
+		Expect(ok).To(BeTrue())
+		Expect(value).To(Equal("from-env"))
+		Expect(source).To(Equal("'OCM_TEST_SETTING' environment variable"))
+	})
+
+	It("Reports missing values", func() {
+		_, _, ok := stringSetting(types.String{Null: true}, env)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("Parses boolean environment variables", func() {
+		os.Setenv(env, "true")
+		value, ok, err := boolSetting(types.Bool{Null: true}, env)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(value).To(BeTrue())
+	})
+
+	It("Rejects invalid boolean environment variables", func() {
+		os.Setenv(env, "junk")
+		_, _, err := boolSetting(types.Bool{Null: true}, env)
+		Expect(err).To(MatchError(ContainSubstring("isn't a valid boolean")))
+	})
+
+	It("Knows the well known environments", func() {
+		Expect(ocmEnvironmentNames()).To(Equal([]string{
+			"integration",
+			"production",
+			"staging",
+		}))
+		Expect(ocmEnvironments["staging"].URL).To(Equal("https://api.stage.openshift.com"))
+	})
+
+	It("Prefers the environment attribute to the URL environment variables", func() {
+		os.Setenv("OCM_URL", "https://my.example.com")
+		os.Setenv("OCM_TOKEN_URL", "https://my-sso.example.com")
+		url, tokenURL, err := ocmURLs(
+			types.String{Null: true},
+			types.String{Null: true},
+			types.String{Value: "staging"},
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(url).To(Equal("https://api.stage.openshift.com"))
+		Expect(tokenURL).To(Equal(defaultTokenURL))
+	})
+
+	It("Prefers the URL attributes to the environment attribute", func() {
+		url, tokenURL, err := ocmURLs(
+			types.String{Value: "https://my.example.com"},
+			types.String{Null: true},
+			types.String{Value: "staging"},
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(url).To(Equal("https://my.example.com"))
+		Expect(tokenURL).To(Equal(defaultTokenURL))
+	})
+
+	It("Prefers the URL environment variables to the environment variable", func() {
+		os.Setenv("OCM_URL", "https://my.example.com")
+		os.Setenv("OCM_ENVIRONMENT", "staging")
+		url, tokenURL, err := ocmURLs(
+			types.String{Null: true},
+			types.String{Null: true},
+			types.String{Null: true},
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(url).To(Equal("https://my.example.com"))
+		Expect(tokenURL).To(Equal(defaultTokenURL))
+	})
+
+	It("Rejects unknown environments", func() {
+		os.Setenv("OCM_ENVIRONMENT", "junk")
+		_, _, err := ocmURLs(
+			types.String{Null: true},
+			types.String{Null: true},
+			types.String{Null: true},
+		)
+		Expect(err).To(MatchError(ContainSubstring("'OCM_ENVIRONMENT'")))
+	})
+})
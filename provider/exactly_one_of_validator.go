@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+type exactlyOneOfValidator struct {
+	names []string
+}
+
+// ExactlyOneOfValidator returns a resource configuration validator that checks that exactly one
+// of the given top level attributes is set. Attributes whose values aren't known yet are assumed
+// to be set.
+func ExactlyOneOfValidator(names ...string) tfsdk.ResourceConfigValidator {
+	return exactlyOneOfValidator{
+		names: names,
+	}
+}
+
+func (v exactlyOneOfValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf(
+		"Exactly one of the attributes %s must be set.",
+		v.quotedNames(),
+	)
+}
+
+func (v exactlyOneOfValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v exactlyOneOfValidator) Validate(ctx context.Context,
+	req tfsdk.ValidateResourceConfigRequest, resp *tfsdk.ValidateResourceConfigResponse) {
+	var set []string
+	for _, name := range v.names {
+		value, _, err := tftypes.WalkAttributePath(
+			req.Config.Raw,
+			tftypes.NewAttributePath().WithAttributeName(name),
+		)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Can't check attributes",
+				fmt.Sprintf("Can't get value of attribute '%s': %v", name, err),
+			)
+			return
+		}
+		if !value.(tftypes.Value).IsKnown() {
+			return
+		}
+		if !value.(tftypes.Value).IsNull() {
+			set = append(set, name)
+		}
+	}
+	switch len(set) {
+	case 1:
+	case 0:
+		resp.Diagnostics.AddError(
+			"Missing attribute",
+			fmt.Sprintf(
+				"Exactly one of the attributes %s must be set, but none is",
+				v.quotedNames(),
+			),
+		)
+	default:
+		resp.Diagnostics.AddError(
+			"Conflicting attributes",
+			fmt.Sprintf(
+				"Exactly one of the attributes %s must be set, but %s are set",
+				v.quotedNames(), quoteNames(set),
+			),
+		)
+	}
+}
+
+func (v exactlyOneOfValidator) quotedNames() string {
+	return quoteNames(v.names)
+}
+
+// quoteNames returns a string containing the given names surrounded by single quotes and
+// separated by commas.
+func quoteNames(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "'" + name + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
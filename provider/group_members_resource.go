@@ -0,0 +1,438 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+type GroupMembersResourceType struct {
+}
+
+type GroupMembersResource struct {
+	logger     logging.Logger
+	collection *cmv1.ClustersClient
+}
+
+func (t *GroupMembersResourceType) GetSchema(ctx context.Context) (result tfsdk.Schema,
+	diags diag.Diagnostics) {
+	result = tfsdk.Schema{
+		Description: "Manages the complete list of members of a group. Users " +
+			"that are members of the group but aren't in the configuration " +
+			"are removed from the group.",
+		Attributes: map[string]tfsdk.Attribute{
+			"cluster": {
+				Description: "Identifier of the cluster.",
+				Type:        types.StringType,
+				Required:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"group": {
+				Description: "Identifier of the group, for example " +
+					"'dedicated-admins'. Use the 'ocm_groups' data source " +
+					"to find the groups of the cluster.",
+				Type:     types.StringType,
+				Required: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"id": {
+				Description: "Identifier of the group.",
+				Type:        types.StringType,
+				Computed:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"users": {
+				Description: "Identifiers of the users that are members " +
+					"of the group.",
+				Type: types.SetType{
+					ElemType: types.StringType,
+				},
+				Required: true,
+			},
+			"timeouts": {
+				Description: "Timeouts of the create, update and delete operations.",
+				Attributes:  timeoutsResource(),
+				Optional:    true,
+			},
+		},
+	}
+	return
+}
+
+func (t *GroupMembersResourceType) NewResource(ctx context.Context,
+	p tfsdk.Provider) (result tfsdk.Resource, diags diag.Diagnostics) {
+	// Cast the provider interface to the specific implementation: use it directly when needed.
+	parent := p.(*Provider)
+
+	// Get the collection of clusters:
+	collection := parent.connection.ClustersMgmt().V1().Clusters()
+
+	// Create the resource:
+	result = &GroupMembersResource{
+		logger:     parent.logger,
+		collection: collection,
+	}
+
+	return
+}
+
+func (r *GroupMembersResource) Create(ctx context.Context,
+	request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	// Get the plan:
+	state := &GroupMembersState{}
+	diags := request.Plan.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Apply the create timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, state.Timeouts.CreateTimeout())
+	defer cancel()
+
+	// Wait till the cluster is ready:
+	resource := r.collection.Cluster(state.Cluster.Value)
+	_, err := waitTillClusterReady(ctx, resource, state.Timeouts.Interval())
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't poll cluster state",
+			fmt.Sprintf(
+				"Can't poll state of cluster with identifier '%s': %v",
+				state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+
+	// Check that the group exists, so that we can return a meaningful error message if it
+	// doesn't:
+	err = checkGroupExists(ctx, resource.Groups(), state.Group.Value)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't find group",
+			fmt.Sprintf(
+				"Can't find group '%s' in cluster '%s': %v",
+				state.Group.Value, state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+
+	// Make the members of the group match the configuration:
+	err = syncGroupMembers(ctx, resource.Groups().Group(state.Group.Value).Users(), state.Users)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't update group members",
+			fmt.Sprintf(
+				"Can't update members of group '%s' in cluster '%s': %v",
+				state.Group.Value, state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+
+	// Save the state:
+	state.ID = state.Group
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *GroupMembersResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest,
+	response *tfsdk.ReadResourceResponse) {
+	// Get the current state:
+	state := &GroupMembersState{}
+	diags := request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Find the group:
+	group := r.collection.Cluster(state.Cluster.Value).Groups().Group(state.ID.Value)
+	get, err := group.Get().SendContext(ctx)
+	if err != nil && get != nil && get.Status() == http.StatusNotFound {
+		r.logger.Warn(
+			ctx,
+			"Group '%s' doesn't exist in cluster '%s', removing from state",
+			state.ID.Value, state.Cluster.Value,
+		)
+		response.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't find group",
+			fmt.Sprintf(
+				"Can't find group '%s' in cluster '%s': %v",
+				state.ID.Value, state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+
+	// Get the current members of the group:
+	users, err := listGroupMembers(ctx, group.Users())
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't list group members",
+			fmt.Sprintf(
+				"Can't list members of group '%s' in cluster '%s': %v",
+				state.ID.Value, state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+
+	// Save the state:
+	state.Group = state.ID
+	state.Users = users
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *GroupMembersResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest,
+	response *tfsdk.UpdateResourceResponse) {
+	var diags diag.Diagnostics
+
+	// Get the state:
+	state := &GroupMembersState{}
+	diags = request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Get the plan:
+	plan := &GroupMembersState{}
+	diags = request.Plan.Get(ctx, plan)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Apply the update timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, plan.Timeouts.UpdateTimeout())
+	defer cancel()
+
+	// Make the members of the group match the plan. Note that the cluster and the group can't
+	// be changed, changing them forces the replacement of the resource.
+	users := r.collection.Cluster(state.Cluster.Value).
+		Groups().
+		Group(state.ID.Value).
+		Users()
+	err := syncGroupMembers(ctx, users, plan.Users)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't update group members",
+			fmt.Sprintf(
+				"Can't update members of group '%s' in cluster '%s': %v",
+				state.ID.Value, state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+
+	// Save the state:
+	plan.ID = state.ID
+	diags = response.State.Set(ctx, plan)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *GroupMembersResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest,
+	response *tfsdk.DeleteResourceResponse) {
+	// Get the state:
+	state := &GroupMembersState{}
+	diags := request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Apply the delete timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, state.Timeouts.DeleteTimeout())
+	defer cancel()
+
+	// Remove from the group the users that are in the state. The group itself isn't removed
+	// because groups can't be created or deleted.
+	users := r.collection.Cluster(state.Cluster.Value).
+		Groups().
+		Group(state.ID.Value).
+		Users()
+	for _, user := range state.Users {
+		remove, err := users.User(user).Delete().SendContext(ctx)
+		if err != nil && !(remove != nil && remove.Status() == http.StatusNotFound) {
+			response.Diagnostics.AddError(
+				"Can't delete group member",
+				fmt.Sprintf(
+					"Can't remove user '%s' from group '%s' in cluster '%s': %v",
+					user, state.ID.Value, state.Cluster.Value, err,
+				),
+			)
+			return
+		}
+	}
+
+	// Remove the state:
+	response.State.RemoveResource(ctx)
+}
+
+func (r *GroupMembersResource) ImportState(ctx context.Context,
+	request tfsdk.ImportResourceStateRequest, response *tfsdk.ImportResourceStateResponse) {
+	// The identifier of the group is only unique within the cluster, so the import identifier
+	// must contain both:
+	parts, err := parseImportID(request.ID, "cluster_id", "group_id")
+	if err != nil {
+		response.Diagnostics.AddError("Invalid import identifier", err.Error())
+		return
+	}
+	diags := response.State.SetAttribute(
+		ctx,
+		tftypes.NewAttributePath().WithAttributeName("cluster"),
+		parts[0],
+	)
+	response.Diagnostics.Append(diags...)
+	diags = response.State.SetAttribute(
+		ctx,
+		tftypes.NewAttributePath().WithAttributeName("id"),
+		parts[1],
+	)
+	response.Diagnostics.Append(diags...)
+}
+
+// checkGroupExists checks that the group with the given identifier exists. If it doesn't exist
+// the returned error contains the identifiers of the groups that do exist.
+func checkGroupExists(ctx context.Context, client *cmv1.GroupsClient, id string) error {
+	get, err := client.Group(id).Get().SendContext(ctx)
+	if err == nil {
+		return nil
+	}
+	if get == nil || get.Status() != http.StatusNotFound {
+		return err
+	}
+	list, err := client.List().SendContext(ctx)
+	if err != nil {
+		return fmt.Errorf("group doesn't exist")
+	}
+	var ids []string
+	list.Items().Each(func(group *cmv1.Group) bool {
+		ids = append(ids, group.ID())
+		return true
+	})
+	sort.Strings(ids)
+	return fmt.Errorf(
+		"group doesn't exist, the groups of the cluster are %s",
+		quoteNames(ids),
+	)
+}
+
+// listGroupMembers returns the sorted identifiers of the users that are members of a group.
+func listGroupMembers(ctx context.Context, client *cmv1.UsersClient) (result []string,
+	err error) {
+	result = []string{}
+	listSize := 100
+	listPage := 1
+	listRequest := client.List().Size(listSize)
+	for {
+		var listResponse *cmv1.UsersListResponse
+		listResponse, err = listRequest.SendContext(ctx)
+		if err != nil {
+			return
+		}
+		listResponse.Items().Each(func(listItem *cmv1.User) bool {
+			result = append(result, listItem.ID())
+			return true
+		})
+		if listResponse.Size() < listSize {
+			break
+		}
+		listPage++
+		listRequest.Page(listPage)
+	}
+	sort.Strings(result)
+	return
+}
+
+// syncGroupMembers adds to the group the users that are in the given list but aren't members yet,
+// and removes from the group the members that aren't in the list.
+func syncGroupMembers(ctx context.Context, client *cmv1.UsersClient, users []string) error {
+	current, err := listGroupMembers(ctx, client)
+	if err != nil {
+		return err
+	}
+	desired := map[string]bool{}
+	for _, user := range users {
+		desired[user] = true
+	}
+	existing := map[string]bool{}
+	for _, user := range current {
+		existing[user] = true
+	}
+
+	// Remove the members that aren't in the list:
+	var failures []string
+	for _, user := range current {
+		if desired[user] {
+			continue
+		}
+		_, err = client.User(user).Delete().SendContext(ctx)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("can't remove user '%s': %v", user, err))
+		}
+	}
+
+	// Add the users that aren't members yet:
+	sorted := make([]string, 0, len(desired))
+	for user := range desired {
+		sorted = append(sorted, user)
+	}
+	sort.Strings(sorted)
+	for _, user := range sorted {
+		if existing[user] {
+			continue
+		}
+		body, err := cmv1.NewUser().ID(user).Build()
+		if err != nil {
+			return err
+		}
+		_, err = client.Add().Body(body).SendContext(ctx)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("can't add user '%s': %v", user, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return nil
+}
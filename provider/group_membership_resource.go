@@ -19,7 +19,7 @@ package provider
 import (
 	"context"
 	"fmt"
-	"time"
+	"net/http"
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
@@ -46,21 +46,38 @@ func (t *GroupMembershipResourceType) GetSchema(ctx context.Context) (result tfs
 				Description: "Identifier of the cluster.",
 				Type:        types.StringType,
 				Required:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
 			},
 			"group": {
 				Description: "Identifier of the group.",
 				Type:        types.StringType,
 				Required:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
 			},
 			"id": {
 				Description: "Identifier of the membership.",
 				Type:        types.StringType,
 				Computed:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.UseStateForUnknown(),
+				},
 			},
 			"user": {
 				Description: "user name.",
 				Type:        types.StringType,
 				Required:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"timeouts": {
+				Description: "Timeouts of the create, update and delete operations.",
+				Attributes:  timeoutsResource(),
+				Optional:    true,
 			},
 		},
 	}
@@ -94,16 +111,13 @@ func (r *GroupMembershipResource) Create(ctx context.Context,
 		return
 	}
 
+	// Apply the create timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, state.Timeouts.CreateTimeout())
+	defer cancel()
+
 	// Wait till the cluster is ready:
 	resource := r.collection.Cluster(state.Cluster.Value)
-	pollCtx, cancel := context.WithTimeout(ctx, 1*time.Hour)
-	defer cancel()
-	_, err := resource.Poll().
-		Interval(30 * time.Second).
-		Predicate(func(get *cmv1.ClusterGetResponse) bool {
-			return get.Body().State() == cmv1.ClusterStateReady
-		}).
-		StartContext(pollCtx)
+	_, err := waitTillClusterReady(ctx, resource, state.Timeouts.Interval())
 	if err != nil {
 		response.Diagnostics.AddError(
 			"Can't poll cluster state",
@@ -131,6 +145,14 @@ func (r *GroupMembershipResource) Create(ctx context.Context,
 	}
 	collection := resource.Groups().Group(state.Group.Value).Users()
 	add, err := collection.Add().Body(object).SendContext(ctx)
+	if err != nil && add != nil && add.Status() == http.StatusNotFound {
+		// Explain if the problem is that the group doesn't exist, as the error returned
+		// by the server doesn't say it:
+		groupErr := checkGroupExists(ctx, resource.Groups(), state.Group.Value)
+		if groupErr != nil {
+			err = groupErr
+		}
+	}
 	if err != nil {
 		response.Diagnostics.AddError(
 			"Can't create group membership",
@@ -185,6 +207,30 @@ func (r *GroupMembershipResource) Read(ctx context.Context, request tfsdk.ReadRe
 
 func (r *GroupMembershipResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest,
 	response *tfsdk.UpdateResourceResponse) {
+	var diags diag.Diagnostics
+
+	// Get the state:
+	state := &GroupMembershipState{}
+	diags = request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Get the plan:
+	plan := &GroupMembershipState{}
+	diags = request.Plan.Get(ctx, plan)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// The timeouts are the only attributes that are updated, and they don't require sending
+	// anything to the server. Changes to the cluster, the group or the user force the
+	// replacement of the membership.
+	state.Timeouts = plan.Timeouts
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
 }
 
 func (r *GroupMembershipResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest,
@@ -197,6 +243,10 @@ func (r *GroupMembershipResource) Delete(ctx context.Context, request tfsdk.Dele
 		return
 	}
 
+	// Apply the delete timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, state.Timeouts.DeleteTimeout())
+	defer cancel()
+
 	// Send the request to delete group membership:
 	resource := r.collection.Cluster(state.Cluster.Value).Groups().Group(state.Group.Value).
 		Users().
@@ -220,12 +270,31 @@ func (r *GroupMembershipResource) Delete(ctx context.Context, request tfsdk.Dele
 
 func (r *GroupMembershipResource) ImportState(ctx context.Context, request tfsdk.ImportResourceStateRequest,
 	response *tfsdk.ImportResourceStateResponse) {
-	tfsdk.ResourceImportStatePassthroughID(
+	// The identifier of the membership is only unique within the group, so the import
+	// identifier must contain the identifiers of the cluster and the group as well:
+	parts, err := parseImportID(request.ID, "cluster_id", "group_id", "user_id")
+	if err != nil {
+		response.Diagnostics.AddError("Invalid import identifier", err.Error())
+		return
+	}
+	diags := response.State.SetAttribute(
+		ctx,
+		tftypes.NewAttributePath().WithAttributeName("cluster"),
+		parts[0],
+	)
+	response.Diagnostics.Append(diags...)
+	diags = response.State.SetAttribute(
+		ctx,
+		tftypes.NewAttributePath().WithAttributeName("group"),
+		parts[1],
+	)
+	response.Diagnostics.Append(diags...)
+	diags = response.State.SetAttribute(
 		ctx,
 		tftypes.NewAttributePath().WithAttributeName("id"),
-		request,
-		response,
+		parts[2],
 	)
+	response.Diagnostics.Append(diags...)
 }
 
 // populateState copies the data from the API object to the Terraform state.
@@ -21,8 +21,9 @@ import (
 )
 
 type GroupMembershipState struct {
-	Cluster types.String `tfsdk:"cluster"`
-	Group   types.String `tfsdk:"group"`
-	ID      types.String `tfsdk:"id"`
-	User    types.String `tfsdk:"user"`
+	Cluster  types.String `tfsdk:"cluster"`
+	Group    types.String `tfsdk:"group"`
+	ID       types.String `tfsdk:"id"`
+	User     types.String `tfsdk:"user"`
+	Timeouts *Timeouts    `tfsdk:"timeouts"`
 }
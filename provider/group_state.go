@@ -21,6 +21,7 @@ import (
 )
 
 type GroupState struct {
-	ID   types.String `tfsdk:"id"`
-	Name types.String `tfsdk:"name"`
+	ID    types.String `tfsdk:"id"`
+	Name  types.String `tfsdk:"name"`
+	Users []string     `tfsdk:"users"`
 }
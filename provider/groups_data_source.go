@@ -18,6 +18,7 @@ package provider
 
 import (
 	"context"
+	"sort"
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
@@ -71,6 +72,14 @@ func (t *GroupsDataSourceType) itemSchema() tfsdk.NestedAttributes {
 				Type:     types.StringType,
 				Computed: true,
 			},
+			"users": {
+				Description: "Identifiers of the users that are members " +
+					"of the group.",
+				Type: types.ListType{
+					ElemType: types.StringType,
+				},
+				Computed: true,
+			},
 		},
 		tfsdk.ListNestedAttributesOptions{},
 	)
@@ -140,6 +149,7 @@ func (s *GroupsDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourc
 			Name: types.String{
 				Value: listItem.ID(),
 			},
+			Users: groupUsers(listItem),
 		}
 	}
 
@@ -147,3 +157,14 @@ func (s *GroupsDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourc
 	diags = response.State.Set(ctx, state)
 	response.Diagnostics.Append(diags...)
 }
+
+// groupUsers returns the sorted identifiers of the users that are members of the given group.
+func groupUsers(group *cmv1.Group) []string {
+	result := []string{}
+	group.Users().Each(func(user *cmv1.User) bool {
+		result = append(result, user.ID())
+		return true
+	})
+	sort.Strings(result)
+	return result
+}
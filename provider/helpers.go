@@ -17,6 +17,9 @@ limitations under the License.
 package provider
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -55,3 +58,37 @@ func shouldPatchString(state, plan types.String) (value string, ok bool) {
 	}
 	return
 }
+
+// parseImportID splits an import identifier that contains several values separated by commas,
+// for example `cluster_id,policy_id`. The names are the names of the expected values, and are
+// used to build the error message when the identifier doesn't have the right format.
+func parseImportID(id string, names ...string) (values []string, err error) {
+	values = strings.Split(id, ",")
+	valid := len(values) == len(names)
+	for _, value := range values {
+		if value == "" {
+			valid = false
+		}
+	}
+	if !valid {
+		values = nil
+		err = fmt.Errorf(
+			"import identifier '%s' should have the format '%s'",
+			id, strings.Join(names, ","),
+		)
+	}
+	return
+}
+
+// stringMap converts a Terraform map of strings into a Go map. Null and unknown values are
+// converted into an empty map.
+func stringMap(value types.Map) map[string]string {
+	result := map[string]string{}
+	if value.Unknown || value.Null {
+		return result
+	}
+	for k, v := range value.Elems {
+		result[k] = v.(types.String).Value
+	}
+	return result
+}
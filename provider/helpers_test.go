@@ -0,0 +1,42 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	. "github.com/onsi/ginkgo/v2/dsl/core" // nolint
+	. "github.com/onsi/gomega"             // nolint
+)
+
+var _ = Describe("Import identifiers", func() {
+	It("Splits the values", func() {
+		values, err := parseImportID("123,456", "cluster_id", "policy_id")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(values).To(Equal([]string{"123", "456"}))
+	})
+
+	It("Fails if the number of values is wrong", func() {
+		_, err := parseImportID("123", "cluster_id", "policy_id")
+		Expect(err).To(MatchError(
+			"import identifier '123' should have the format 'cluster_id,policy_id'",
+		))
+	})
+
+	It("Fails if a value is empty", func() {
+		_, err := parseImportID("123,", "cluster_id", "policy_id")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,368 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+type HTPasswdUserResourceType struct {
+}
+
+type HTPasswdUserResource struct {
+	logger     logging.Logger
+	collection *cmv1.ClustersClient
+}
+
+func (t *HTPasswdUserResourceType) GetSchema(ctx context.Context) (result tfsdk.Schema,
+	diags diag.Diagnostics) {
+	result = tfsdk.Schema{
+		Description: "Manages a user of an 'htpasswd' identity provider.",
+		Attributes: map[string]tfsdk.Attribute{
+			"cluster": {
+				Description: "Identifier of the cluster.",
+				Type:        types.StringType,
+				Required:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"identity_provider": {
+				Description: "Identifier of the 'htpasswd' identity provider.",
+				Type:        types.StringType,
+				Required:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"id": {
+				Description: "Unique identifier of the user.",
+				Type:        types.StringType,
+				Computed:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"username": {
+				Description: "User name. Changing this forces the creation " +
+					"of a new user.",
+				Type:     types.StringType,
+				Required: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"password": {
+				Description: "User password. The server never returns it, " +
+					"so changes made outside of Terraform aren't detected.",
+				Type:      types.StringType,
+				Required:  true,
+				Sensitive: true,
+				Validators: []tfsdk.AttributeValidator{
+					PasswordValidator(),
+				},
+			},
+			"timeouts": {
+				Description: "Timeouts of the create, update and delete operations.",
+				Attributes:  timeoutsResource(),
+				Optional:    true,
+			},
+		},
+	}
+	return
+}
+
+func (t *HTPasswdUserResourceType) NewResource(ctx context.Context,
+	p tfsdk.Provider) (result tfsdk.Resource, diags diag.Diagnostics) {
+	// Cast the provider interface to the specific implementation: use it directly when needed.
+	parent := p.(*Provider)
+
+	// Get the collection of clusters:
+	collection := parent.connection.ClustersMgmt().V1().Clusters()
+
+	// Create the resource:
+	result = &HTPasswdUserResource{
+		logger:     parent.logger,
+		collection: collection,
+	}
+
+	return
+}
+
+func (r *HTPasswdUserResource) Create(ctx context.Context,
+	request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	// Get the plan:
+	state := &HTPasswdUserState{}
+	diags := request.Plan.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Apply the create timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, state.Timeouts.CreateTimeout())
+	defer cancel()
+
+	// Wait till the cluster is ready:
+	resource := r.collection.Cluster(state.Cluster.Value)
+	_, err := waitTillClusterReady(ctx, resource, state.Timeouts.Interval())
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't poll cluster state",
+			fmt.Sprintf(
+				"Can't poll state of cluster with identifier '%s': %v",
+				state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+
+	// Create the user:
+	object, err := cmv1.NewHTPasswdUser().
+		Username(state.Username.Value).
+		Password(state.Password.Value).
+		Build()
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't build user",
+			fmt.Sprintf(
+				"Can't build user '%s' for cluster '%s' and identity provider '%s': %v",
+				state.Username.Value, state.Cluster.Value,
+				state.IdentityProvider.Value, err,
+			),
+		)
+		return
+	}
+	add, err := resource.IdentityProviders().
+		IdentityProvider(state.IdentityProvider.Value).
+		HtpasswdUsers().
+		Add().
+		Body(object).
+		SendContext(ctx)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't create user",
+			fmt.Sprintf(
+				"Can't create user '%s' for cluster '%s' and identity provider '%s': %v",
+				state.Username.Value, state.Cluster.Value,
+				state.IdentityProvider.Value, err,
+			),
+		)
+		return
+	}
+	object = add.Body()
+
+	// Save the state:
+	r.populateState(object, state)
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *HTPasswdUserResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest,
+	response *tfsdk.ReadResourceResponse) {
+	// Get the current state:
+	state := &HTPasswdUserState{}
+	diags := request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Find the user:
+	get, err := r.userClient(state).Get().SendContext(ctx)
+	if err != nil && get != nil && get.Status() == http.StatusNotFound {
+		r.logger.Warn(
+			ctx,
+			"User '%s' doesn't exist in identity provider '%s' of cluster '%s', "+
+				"removing from state",
+			state.ID.Value, state.IdentityProvider.Value, state.Cluster.Value,
+		)
+		response.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't find user",
+			fmt.Sprintf(
+				"Can't find user with identifier '%s' for cluster '%s' and "+
+					"identity provider '%s': %v",
+				state.ID.Value, state.Cluster.Value, state.IdentityProvider.Value, err,
+			),
+		)
+		return
+	}
+	object := get.Body()
+
+	// Save the state:
+	r.populateState(object, state)
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *HTPasswdUserResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest,
+	response *tfsdk.UpdateResourceResponse) {
+	var diags diag.Diagnostics
+
+	// Get the state:
+	state := &HTPasswdUserState{}
+	diags = request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Get the plan:
+	plan := &HTPasswdUserState{}
+	diags = request.Plan.Get(ctx, plan)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Apply the update timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, plan.Timeouts.UpdateTimeout())
+	defer cancel()
+
+	// The password is the only attribute that can be updated, the rest force the replacement
+	// of the user:
+	if plan.Password.Value != state.Password.Value {
+		patch, err := cmv1.NewHTPasswdUser().
+			Password(plan.Password.Value).
+			Build()
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't build user patch",
+				fmt.Sprintf(
+					"Can't build patch for user with identifier '%s': %v",
+					state.ID.Value, err,
+				),
+			)
+			return
+		}
+		_, err = r.userClient(state).Update().Body(patch).SendContext(ctx)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't update user",
+				fmt.Sprintf(
+					"Can't update user with identifier '%s' for cluster '%s' "+
+						"and identity provider '%s': %v",
+					state.ID.Value, state.Cluster.Value,
+					state.IdentityProvider.Value, err,
+				),
+			)
+			return
+		}
+		state.Password = plan.Password
+	}
+
+	// Save the state:
+	state.Timeouts = plan.Timeouts
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *HTPasswdUserResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest,
+	response *tfsdk.DeleteResourceResponse) {
+	// Get the state:
+	state := &HTPasswdUserState{}
+	diags := request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Apply the delete timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, state.Timeouts.DeleteTimeout())
+	defer cancel()
+
+	// Send the request to delete the user:
+	remove, err := r.userClient(state).Delete().SendContext(ctx)
+	if err != nil && !(remove != nil && remove.Status() == http.StatusNotFound) {
+		response.Diagnostics.AddError(
+			"Can't delete user",
+			fmt.Sprintf(
+				"Can't delete user with identifier '%s' for cluster '%s' and "+
+					"identity provider '%s': %v",
+				state.ID.Value, state.Cluster.Value, state.IdentityProvider.Value, err,
+			),
+		)
+		return
+	}
+
+	// Remove the state:
+	response.State.RemoveResource(ctx)
+}
+
+func (r *HTPasswdUserResource) ImportState(ctx context.Context,
+	request tfsdk.ImportResourceStateRequest, response *tfsdk.ImportResourceStateResponse) {
+	// The identifier of the user is only unique within the identity provider, so the import
+	// identifier must contain the identifiers of the cluster and the identity provider as well.
+	// Note that the password can't be imported because the server doesn't return it.
+	parts, err := parseImportID(request.ID, "cluster_id", "identity_provider_id", "user_id")
+	if err != nil {
+		response.Diagnostics.AddError("Invalid import identifier", err.Error())
+		return
+	}
+	diags := response.State.SetAttribute(
+		ctx,
+		tftypes.NewAttributePath().WithAttributeName("cluster"),
+		parts[0],
+	)
+	response.Diagnostics.Append(diags...)
+	diags = response.State.SetAttribute(
+		ctx,
+		tftypes.NewAttributePath().WithAttributeName("identity_provider"),
+		parts[1],
+	)
+	response.Diagnostics.Append(diags...)
+	diags = response.State.SetAttribute(
+		ctx,
+		tftypes.NewAttributePath().WithAttributeName("id"),
+		parts[2],
+	)
+	response.Diagnostics.Append(diags...)
+}
+
+// userClient returns the client for the user described by the given state.
+func (r *HTPasswdUserResource) userClient(state *HTPasswdUserState) *cmv1.HTPasswdUserClient {
+	return r.collection.Cluster(state.Cluster.Value).
+		IdentityProviders().
+		IdentityProvider(state.IdentityProvider.Value).
+		HtpasswdUsers().
+		HtpasswdUser(state.ID.Value)
+}
+
+// populateState copies the data from the API object to the Terraform state. The password isn't
+// copied because the server never returns it.
+func (r *HTPasswdUserResource) populateState(object *cmv1.HTPasswdUser,
+	state *HTPasswdUserState) {
+	state.ID = types.String{
+		Value: object.ID(),
+	}
+	state.Username = types.String{
+		Value: object.Username(),
+	}
+}
@@ -0,0 +1,323 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// IAMClient is the subset of the AWS IAM API used by the resources that manage ROSA roles. It
+// is an interface so that tests can replace the real AWS API with a fake.
+type IAMClient interface {
+	// GetCallerIdentity returns the identity of the AWS account used to manage the roles.
+	GetCallerIdentity(ctx context.Context) (identity *IAMCallerIdentity, err error)
+
+	// GetRole returns the role with the given name, or nil if it doesn't exist.
+	GetRole(ctx context.Context, name string) (role *IAMRole, err error)
+
+	// CreateRole creates the role and returns it with the ARN assigned by AWS.
+	CreateRole(ctx context.Context, role *IAMRole) (result *IAMRole, err error)
+
+	// UpdateRole replaces the trust policy and the tags of an existing role.
+	UpdateRole(ctx context.Context, role *IAMRole) error
+
+	// DeleteRole deletes the role. It doesn't fail if the role doesn't exist.
+	DeleteRole(ctx context.Context, name string) error
+
+	// GetRolePolicy returns the document of an inline permission policy of a role, or an
+	// empty string if it doesn't exist.
+	GetRolePolicy(ctx context.Context, role, name string) (document string, err error)
+
+	// PutRolePolicy creates or replaces an inline permission policy of a role.
+	PutRolePolicy(ctx context.Context, role, name, document string) error
+
+	// DeleteRolePolicy deletes an inline permission policy of a role. It doesn't fail if the
+	// policy doesn't exist.
+	DeleteRolePolicy(ctx context.Context, role, name string) error
+}
+
+// IAMCallerIdentity contains the details of the AWS account used to manage the roles.
+type IAMCallerIdentity struct {
+	AccountID string
+	Partition string
+}
+
+// IAMRole contains the details of an IAM role.
+type IAMRole struct {
+	Name                string
+	Path                string
+	ARN                 string
+	AssumeRolePolicy    string
+	PermissionsBoundary string
+	Tags                map[string]string
+}
+
+// DefaultIAMClient is the implementation of the IAMClient interface that uses the AWS API. The
+// credentials and the region are loaded from the usual AWS environment variables and shared
+// configuration files the first time that the client is used.
+type DefaultIAMClient struct {
+	once sync.Once
+	iam  *iam.Client
+	sts  *sts.Client
+	err  error
+}
+
+// NewDefaultIAMClient creates a new IAM client that uses the AWS API.
+func NewDefaultIAMClient() *DefaultIAMClient {
+	return &DefaultIAMClient{}
+}
+
+func (c *DefaultIAMClient) init(ctx context.Context) error {
+	c.once.Do(func() {
+		var cfg aws.Config
+		cfg, c.err = config.LoadDefaultConfig(ctx)
+		if c.err != nil {
+			return
+		}
+		c.iam = iam.NewFromConfig(cfg)
+		c.sts = sts.NewFromConfig(cfg)
+	})
+	return c.err
+}
+
+func (c *DefaultIAMClient) GetCallerIdentity(ctx context.Context) (identity *IAMCallerIdentity,
+	err error) {
+	err = c.init(ctx)
+	if err != nil {
+		return
+	}
+	output, err := c.sts.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return
+	}
+	identity = &IAMCallerIdentity{
+		AccountID: aws.ToString(output.Account),
+		Partition: arnPartition(aws.ToString(output.Arn)),
+	}
+	return
+}
+
+func (c *DefaultIAMClient) GetRole(ctx context.Context, name string) (role *IAMRole, err error) {
+	err = c.init(ctx)
+	if err != nil {
+		return
+	}
+	output, err := c.iam.GetRole(ctx, &iam.GetRoleInput{
+		RoleName: aws.String(name),
+	})
+	if isIAMNotFound(err) {
+		err = nil
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	// The trust policy returned by AWS is URL encoded:
+	trust, err := url.QueryUnescape(aws.ToString(output.Role.AssumeRolePolicyDocument))
+	if err != nil {
+		return
+	}
+	role = &IAMRole{
+		Name:             aws.ToString(output.Role.RoleName),
+		Path:             aws.ToString(output.Role.Path),
+		ARN:              aws.ToString(output.Role.Arn),
+		AssumeRolePolicy: trust,
+		Tags:             map[string]string{},
+	}
+	if output.Role.PermissionsBoundary != nil {
+		role.PermissionsBoundary = aws.ToString(
+			output.Role.PermissionsBoundary.PermissionsBoundaryArn,
+		)
+	}
+	for _, tag := range output.Role.Tags {
+		role.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return
+}
+
+func (c *DefaultIAMClient) CreateRole(ctx context.Context, role *IAMRole) (result *IAMRole,
+	err error) {
+	err = c.init(ctx)
+	if err != nil {
+		return
+	}
+	input := &iam.CreateRoleInput{
+		RoleName:                 aws.String(role.Name),
+		AssumeRolePolicyDocument: aws.String(role.AssumeRolePolicy),
+		Tags:                     iamTags(role.Tags),
+	}
+	if role.Path != "" {
+		input.Path = aws.String(role.Path)
+	}
+	if role.PermissionsBoundary != "" {
+		input.PermissionsBoundary = aws.String(role.PermissionsBoundary)
+	}
+	output, err := c.iam.CreateRole(ctx, input)
+	if err != nil {
+		return
+	}
+	created := *role
+	created.ARN = aws.ToString(output.Role.Arn)
+	result = &created
+	return
+}
+
+func (c *DefaultIAMClient) UpdateRole(ctx context.Context, role *IAMRole) error {
+	err := c.init(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = c.iam.UpdateAssumeRolePolicy(ctx, &iam.UpdateAssumeRolePolicyInput{
+		RoleName:       aws.String(role.Name),
+		PolicyDocument: aws.String(role.AssumeRolePolicy),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Remove the tags that are no longer wanted, and then add or replace the rest:
+	current, err := c.GetRole(ctx, role.Name)
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		var keys []string
+		for key := range current.Tags {
+			if _, ok := role.Tags[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) > 0 {
+			_, err = c.iam.UntagRole(ctx, &iam.UntagRoleInput{
+				RoleName: aws.String(role.Name),
+				TagKeys:  keys,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if len(role.Tags) > 0 {
+		_, err = c.iam.TagRole(ctx, &iam.TagRoleInput{
+			RoleName: aws.String(role.Name),
+			Tags:     iamTags(role.Tags),
+		})
+	}
+	return err
+}
+
+func (c *DefaultIAMClient) DeleteRole(ctx context.Context, name string) error {
+	err := c.init(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = c.iam.DeleteRole(ctx, &iam.DeleteRoleInput{
+		RoleName: aws.String(name),
+	})
+	if isIAMNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (c *DefaultIAMClient) GetRolePolicy(ctx context.Context, role,
+	name string) (document string, err error) {
+	err = c.init(ctx)
+	if err != nil {
+		return
+	}
+	output, err := c.iam.GetRolePolicy(ctx, &iam.GetRolePolicyInput{
+		RoleName:   aws.String(role),
+		PolicyName: aws.String(name),
+	})
+	if isIAMNotFound(err) {
+		err = nil
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	// The policy document returned by AWS is URL encoded:
+	document, err = url.QueryUnescape(aws.ToString(output.PolicyDocument))
+	return
+}
+
+func (c *DefaultIAMClient) PutRolePolicy(ctx context.Context, role, name,
+	document string) error {
+	err := c.init(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = c.iam.PutRolePolicy(ctx, &iam.PutRolePolicyInput{
+		RoleName:       aws.String(role),
+		PolicyName:     aws.String(name),
+		PolicyDocument: aws.String(document),
+	})
+	return err
+}
+
+func (c *DefaultIAMClient) DeleteRolePolicy(ctx context.Context, role, name string) error {
+	err := c.init(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = c.iam.DeleteRolePolicy(ctx, &iam.DeleteRolePolicyInput{
+		RoleName:   aws.String(role),
+		PolicyName: aws.String(name),
+	})
+	if isIAMNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// isIAMNotFound checks if the given error is the one returned by the AWS API when the requested
+// role or policy doesn't exist.
+func isIAMNotFound(err error) bool {
+	var notFound *iamtypes.NoSuchEntityException
+	return errors.As(err, &notFound)
+}
+
+// iamTags converts a map of tags into the type used by the AWS API. The result is sorted by key
+// so that requests are predictable.
+func iamTags(tags map[string]string) []iamtypes.Tag {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	result := make([]iamtypes.Tag, len(keys))
+	for i, key := range keys {
+		result[i] = iamtypes.Tag{
+			Key:   aws.String(key),
+			Value: aws.String(tags[key]),
+		}
+	}
+	return result
+}
@@ -19,8 +19,8 @@ package provider
 import (
 	"context"
 	"fmt"
-	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -46,49 +46,182 @@ func (t *IdentityProviderResourceType) GetSchema(ctx context.Context) (result tf
 				Description: "Identifier of the cluster.",
 				Type:        types.StringType,
 				Required:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
 			},
 			"id": {
 				Description: "Unique identifier of the identity provider.",
 				Type:        types.StringType,
 				Computed:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.UseStateForUnknown(),
+				},
 			},
 			"name": {
-				Description: "Name of the identity provider.",
-				Type:        types.StringType,
-				Required:    true,
+				Description: "Name of the identity provider. Changing this " +
+					"forces the creation of a new identity provider.",
+				Type:     types.StringType,
+				Required: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"mapping_method": {
+				Description: "Method used to map the identities returned by " +
+					"the identity provider to users, can be 'claim', " +
+					"'lookup', 'generate' or 'add'. Default value is " +
+					"'claim'. Changing this updates the identity provider " +
+					"in place.",
+				Type:     types.StringType,
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.UseStateForUnknown(),
+				},
+				Validators: []tfsdk.AttributeValidator{
+					EnumValidator(
+						string(cmv1.IdentityProviderMappingMethodClaim),
+						string(cmv1.IdentityProviderMappingMethodLookup),
+						string(cmv1.IdentityProviderMappingMethodGenerate),
+						string(cmv1.IdentityProviderMappingMethodAdd),
+					),
+				},
+			},
+			"login": {
+				Description: "Indicates if the identity provider is offered " +
+					"as a choice in the login page of the console. " +
+					"Changing this updates the identity provider in place.",
+				Type:     types.BoolType,
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"challenge": {
+				Description: "Indicates if the identity provider accepts " +
+					"challenges from command line tools, for example " +
+					"'oc login'. Changing this updates the identity " +
+					"provider in place.",
+				Type:     types.BoolType,
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.UseStateForUnknown(),
+				},
 			},
 			"htpasswd": {
 				Description: "Details of the 'htpasswd' identity provider.",
 				Attributes:  t.htpasswdSchema(),
 				Optional:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					identityProviderTypeModifier(),
+				},
 			},
 			"ldap": {
 				Description: "Details of the LDAP identity provider.",
 				Attributes:  t.ldapSchema(),
 				Optional:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					identityProviderTypeModifier(),
+				},
 			},
 			"openid": {
 				Description: "Details of the OpenID identity provider.",
 				Attributes:  t.openidSchema(),
 				Optional:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					identityProviderTypeModifier(),
+				},
+			},
+			"github": {
+				Description: "Details of the GitHub identity provider.",
+				Attributes:  t.githubSchema(),
+				Optional:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					identityProviderTypeModifier(),
+				},
+			},
+			"gitlab": {
+				Description: "Details of the GitLab identity provider.",
+				Attributes:  t.gitlabSchema(),
+				Optional:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					identityProviderTypeModifier(),
+				},
+			},
+			"google": {
+				Description: "Details of the Google identity provider.",
+				Attributes:  t.googleSchema(),
+				Optional:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					identityProviderTypeModifier(),
+				},
+			},
+			"timeouts": {
+				Description: "Timeouts of the create, update and delete operations.",
+				Attributes:  timeoutsResource(),
+				Optional:    true,
 			},
 		},
 	}
 	return
 }
 
+// identityProviderTypeModifier returns the plan modifier that forces the replacement of the
+// identity provider when its type changes.
+func identityProviderTypeModifier() tfsdk.AttributePlanModifier {
+	return tfsdk.RequiresReplaceIf(
+		identityProviderTypeChanged,
+		"Changing the type of the identity provider forces the creation of a new one.",
+		"Changing the type of the identity provider forces the creation of a new one.",
+	)
+}
+
 func (t *IdentityProviderResourceType) htpasswdSchema() tfsdk.NestedAttributes {
 	return tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
 		"username": {
-			Description: "User name.",
+			Description: "User name. Mutually exclusive with 'users'.",
 			Type:        types.StringType,
-			Required:    true,
+			Optional:    true,
 		},
 		"password": {
-			Description: "User password.",
+			Description: "User password. Mutually exclusive with 'users'.",
 			Type:        types.StringType,
-			Required:    true,
+			Optional:    true,
 			Sensitive:   true,
+			Validators: []tfsdk.AttributeValidator{
+				PasswordValidator(),
+			},
+		},
+		"users": {
+			Description: "List of users. Users added or removed from this list " +
+				"are added or removed from the identity provider without " +
+				"replacing it. Mutually exclusive with 'username' and " +
+				"'password'.",
+			Attributes: tfsdk.ListNestedAttributes(
+				map[string]tfsdk.Attribute{
+					"username": {
+						Description: "User name.",
+						Type:        types.StringType,
+						Required:    true,
+					},
+					"password": {
+						Description: "User password. The server never " +
+							"returns it, so changes made outside of " +
+							"Terraform aren't detected.",
+						Type:      types.StringType,
+						Required:  true,
+						Sensitive: true,
+						Validators: []tfsdk.AttributeValidator{
+							PasswordValidator(),
+						},
+					},
+				},
+				tfsdk.ListNestedAttributesOptions{},
+			),
+			Optional: true,
 		},
 	})
 }
@@ -107,6 +240,9 @@ func (t *IdentityProviderResourceType) ldapSchema() tfsdk.NestedAttributes {
 		"ca": {
 			Type:     types.StringType,
 			Optional: true,
+			Validators: []tfsdk.AttributeValidator{
+				PEMValidator(),
+			},
 		},
 		"insecure": {
 			Type:     types.BoolType,
@@ -116,6 +252,9 @@ func (t *IdentityProviderResourceType) ldapSchema() tfsdk.NestedAttributes {
 		"url": {
 			Type:     types.StringType,
 			Required: true,
+			Validators: []tfsdk.AttributeValidator{
+				URLValidator("ldap", "ldaps"),
+			},
 		},
 		"attributes": {
 			Attributes: t.ldapAttributesSchema(),
@@ -158,10 +297,16 @@ func (t *IdentityProviderResourceType) openidSchema() tfsdk.NestedAttributes {
 		"ca": {
 			Type:     types.StringType,
 			Optional: true,
+			Validators: []tfsdk.AttributeValidator{
+				PEMValidator(),
+			},
 		},
 		"claims": {
 			Attributes: t.openidClaimsSchema(),
 			Required:   true,
+			Validators: []tfsdk.AttributeValidator{
+				NotEmptyObjectValidator(),
+			},
 		},
 		"client_id": {
 			Type:     types.StringType,
@@ -179,7 +324,7 @@ func (t *IdentityProviderResourceType) openidSchema() tfsdk.NestedAttributes {
 			Optional: true,
 		},
 		"extra_authorize_parameters": {
-			Type: types.ListType{
+			Type: types.MapType{
 				ElemType: types.StringType,
 			},
 			Optional: true,
@@ -187,6 +332,9 @@ func (t *IdentityProviderResourceType) openidSchema() tfsdk.NestedAttributes {
 		"issuer": {
 			Type:     types.StringType,
 			Required: true,
+			Validators: []tfsdk.AttributeValidator{
+				URLValidator("https"),
+			},
 		},
 	})
 }
@@ -198,24 +346,140 @@ func (t *IdentityProviderResourceType) openidClaimsSchema() tfsdk.NestedAttribut
 				ElemType: types.StringType,
 			},
 			Optional: true,
+			Validators: []tfsdk.AttributeValidator{
+				NotEmptyListValidator(),
+			},
 		},
 		"groups": {
 			Type: types.ListType{
 				ElemType: types.StringType,
 			},
 			Optional: true,
+			Validators: []tfsdk.AttributeValidator{
+				NotEmptyListValidator(),
+			},
 		},
 		"name": {
 			Type: types.ListType{
 				ElemType: types.StringType,
 			},
 			Optional: true,
+			Validators: []tfsdk.AttributeValidator{
+				NotEmptyListValidator(),
+			},
 		},
 		"preferred_username": {
 			Type: types.ListType{
 				ElemType: types.StringType,
 			},
 			Optional: true,
+			Validators: []tfsdk.AttributeValidator{
+				NotEmptyListValidator(),
+			},
+		},
+	})
+}
+
+func (t *IdentityProviderResourceType) githubSchema() tfsdk.NestedAttributes {
+	return tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
+		"ca": {
+			Description: "Certificate of the authority used to verify the " +
+				"server certificate of a GitHub Enterprise instance, in PEM " +
+				"format.",
+			Type:     types.StringType,
+			Optional: true,
+			Validators: []tfsdk.AttributeValidator{
+				PEMValidator(),
+			},
+		},
+		"client_id": {
+			Description: "Client identifier of the OAuth application.",
+			Type:        types.StringType,
+			Required:    true,
+		},
+		"client_secret": {
+			Description: "Client secret of the OAuth application.",
+			Type:        types.StringType,
+			Required:    true,
+			Sensitive:   true,
+		},
+		"hostname": {
+			Description: "Host name of a GitHub Enterprise instance.",
+			Type:        types.StringType,
+			Optional:    true,
+		},
+		"organizations": {
+			Description: "Organizations that users must belong to in order " +
+				"to log in. Mutually exclusive with 'teams'.",
+			Type: types.ListType{
+				ElemType: types.StringType,
+			},
+			Optional: true,
+		},
+		"teams": {
+			Description: "Teams that users must belong to in order to log " +
+				"in, in the 'organization/team' format. Mutually " +
+				"exclusive with 'organizations'.",
+			Type: types.ListType{
+				ElemType: types.StringType,
+			},
+			Optional: true,
+		},
+	})
+}
+
+func (t *IdentityProviderResourceType) gitlabSchema() tfsdk.NestedAttributes {
+	return tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
+		"ca": {
+			Description: "Certificate of the authority used to verify the " +
+				"server certificate of the GitLab instance, in PEM format.",
+			Type:     types.StringType,
+			Optional: true,
+			Validators: []tfsdk.AttributeValidator{
+				PEMValidator(),
+			},
+		},
+		"client_id": {
+			Description: "Client identifier of the OAuth application.",
+			Type:        types.StringType,
+			Required:    true,
+		},
+		"client_secret": {
+			Description: "Client secret of the OAuth application.",
+			Type:        types.StringType,
+			Required:    true,
+			Sensitive:   true,
+		},
+		"url": {
+			Description: "URL of the GitLab instance, for example " +
+				"`https://gitlab.com`. Must use the `https` scheme.",
+			Type:     types.StringType,
+			Required: true,
+			Validators: []tfsdk.AttributeValidator{
+				URLValidator("https"),
+			},
+		},
+	})
+}
+
+func (t *IdentityProviderResourceType) googleSchema() tfsdk.NestedAttributes {
+	return tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
+		"client_id": {
+			Description: "Client identifier of the OAuth application.",
+			Type:        types.StringType,
+			Required:    true,
+		},
+		"client_secret": {
+			Description: "Client secret of the OAuth application.",
+			Type:        types.StringType,
+			Required:    true,
+			Sensitive:   true,
+		},
+		"hosted_domain": {
+			Description: "Domain that users must belong to in order to " +
+				"log in.",
+			Type:     types.StringType,
+			Optional: true,
 		},
 	})
 }
@@ -238,6 +502,87 @@ func (t *IdentityProviderResourceType) NewResource(ctx context.Context,
 	return
 }
 
+func (r *IdentityProviderResource) ConfigValidators(
+	ctx context.Context) []tfsdk.ResourceConfigValidator {
+	return []tfsdk.ResourceConfigValidator{
+		ExactlyOneOfValidator("htpasswd", "ldap", "openid", "github", "gitlab", "google"),
+	}
+}
+
+func (r *IdentityProviderResource) ValidateConfig(ctx context.Context,
+	request tfsdk.ValidateResourceConfigRequest, response *tfsdk.ValidateResourceConfigResponse) {
+	// Values that aren't known yet can't be checked, so in that case there is nothing to
+	// validate till the apply phase, where the server will do it:
+	var htpasswd *HTPasswdIdentityProvider
+	diags := request.Config.GetAttribute(
+		ctx,
+		tftypes.NewAttributePath().WithAttributeName("htpasswd"),
+		&htpasswd,
+	)
+	if !diags.HasError() && htpasswd != nil {
+		validateHTPasswdIdentityProvider(htpasswd, &response.Diagnostics)
+	}
+	var github *GitHubIdentityProvider
+	diags = request.Config.GetAttribute(
+		ctx,
+		tftypes.NewAttributePath().WithAttributeName("github"),
+		&github,
+	)
+	if !diags.HasError() && github != nil {
+		validateGitHubIdentityProvider(github, &response.Diagnostics)
+	}
+}
+
+// validateHTPasswdIdentityProvider checks that the 'htpasswd' identity provider has either a
+// single user name and password or a list of users.
+func validateHTPasswdIdentityProvider(htpasswd *HTPasswdIdentityProvider,
+	diags *diag.Diagnostics) {
+	switch {
+	case htpasswd.Users != nil && (!htpasswd.Username.Null || !htpasswd.Password.Null):
+		diags.AddError(
+			"Conflicting attributes",
+			"Attribute 'htpasswd.users' can't be used together with "+
+				"'htpasswd.username' or 'htpasswd.password'",
+		)
+	case htpasswd.Users != nil && len(htpasswd.Users) == 0:
+		diags.AddError(
+			"Missing users",
+			"Attribute 'htpasswd.users' must contain at least one user",
+		)
+	case htpasswd.Users == nil && (htpasswd.Username.Null || htpasswd.Password.Null):
+		diags.AddError(
+			"Missing attribute",
+			"Attributes 'htpasswd.username' and 'htpasswd.password' are "+
+				"required unless 'htpasswd.users' is set",
+		)
+	}
+	usernames := map[string]bool{}
+	for _, user := range htpasswd.Users {
+		if usernames[user.Username.Value] {
+			diags.AddError(
+				"Duplicated user",
+				fmt.Sprintf(
+					"User '%s' appears more than once in 'htpasswd.users'",
+					user.Username.Value,
+				),
+			)
+		}
+		usernames[user.Username.Value] = true
+	}
+}
+
+// validateGitHubIdentityProvider checks that the 'github' identity provider doesn't have both
+// organizations and teams.
+func validateGitHubIdentityProvider(github *GitHubIdentityProvider, diags *diag.Diagnostics) {
+	if github.Organizations != nil && github.Teams != nil {
+		diags.AddError(
+			"Conflicting attributes",
+			"Attribute 'github.organizations' can't be used together with "+
+				"'github.teams'",
+		)
+	}
+}
+
 func (r *IdentityProviderResource) Create(ctx context.Context,
 	request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
 	// Get the plan:
@@ -248,16 +593,13 @@ func (r *IdentityProviderResource) Create(ctx context.Context,
 		return
 	}
 
+	// Apply the create timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, state.Timeouts.CreateTimeout())
+	defer cancel()
+
 	// Wait till the cluster is ready:
 	resource := r.collection.Cluster(state.Cluster.Value)
-	pollCtx, cancel := context.WithTimeout(ctx, 1*time.Hour)
-	defer cancel()
-	_, err := resource.Poll().
-		Interval(30 * time.Second).
-		Predicate(func(get *cmv1.ClusterGetResponse) bool {
-			return get.Body().State() == cmv1.ClusterStateReady
-		}).
-		StartContext(pollCtx)
+	_, err := waitTillClusterReady(ctx, resource, state.Timeouts.Interval())
 	if err != nil {
 		response.Diagnostics.AddError(
 			"Can't poll cluster state",
@@ -270,97 +612,8 @@ func (r *IdentityProviderResource) Create(ctx context.Context,
 	}
 
 	// Create the identity provider:
-	builder := cmv1.NewIdentityProvider()
+	builder := buildIdentityProvider(state)
 	builder.Name(state.Name.Value)
-	switch {
-	case state.HTPasswd != nil:
-		builder.Type(cmv1.IdentityProviderType("HTPasswdIdentityProvider"))
-		htpasswdBuilder := cmv1.NewHTPasswdIdentityProvider()
-		if !state.HTPasswd.Username.Null {
-			htpasswdBuilder.Username(state.HTPasswd.Username.Value)
-		}
-		if !state.HTPasswd.Password.Null {
-			htpasswdBuilder.Password(state.HTPasswd.Password.Value)
-		}
-		builder.Htpasswd(htpasswdBuilder)
-	case state.LDAP != nil:
-		builder.Type(cmv1.IdentityProviderType("LDAPIdentityProvider"))
-		ldapBuilder := cmv1.NewLDAPIdentityProvider()
-		if !state.LDAP.BindDN.Null {
-			ldapBuilder.BindDN(state.LDAP.BindDN.Value)
-		}
-		if !state.LDAP.BindPassword.Null {
-			ldapBuilder.BindPassword(state.LDAP.BindPassword.Value)
-		}
-		if !state.LDAP.CA.Null {
-			ldapBuilder.CA(state.LDAP.CA.Value)
-		}
-		if !state.LDAP.Insecure.Null {
-			ldapBuilder.Insecure(state.LDAP.Insecure.Value)
-		}
-		if !state.LDAP.URL.Null {
-			ldapBuilder.URL(state.LDAP.URL.Value)
-		}
-		if state.LDAP.Attributes != nil {
-			attributesBuilder := cmv1.NewLDAPAttributes()
-			if state.LDAP.Attributes.ID != nil {
-				attributesBuilder.ID(state.LDAP.Attributes.ID...)
-			}
-			if state.LDAP.Attributes.EMail != nil {
-				attributesBuilder.Email(state.LDAP.Attributes.EMail...)
-			}
-			if state.LDAP.Attributes.Name != nil {
-				attributesBuilder.Name(state.LDAP.Attributes.Name...)
-			}
-			if state.LDAP.Attributes.PreferredUsername != nil {
-				attributesBuilder.PreferredUsername(
-					state.LDAP.Attributes.PreferredUsername...,
-				)
-			}
-			ldapBuilder.Attributes(attributesBuilder)
-		}
-		builder.LDAP(ldapBuilder)
-	case state.OpenID != nil:
-		builder.Type(cmv1.IdentityProviderType("OpenIDIdentityProvider"))
-		openidBuilder := cmv1.NewOpenIDIdentityProvider()
-		if !state.OpenID.CA.Null {
-			openidBuilder.CA(state.OpenID.CA.Value)
-		}
-		if state.OpenID.Claims != nil {
-			claimsBuilder := cmv1.NewOpenIDClaims()
-
-			if state.OpenID.Claims.Groups != nil {
-				claimsBuilder.Groups(state.OpenID.Claims.Groups...)
-			}
-			if state.OpenID.Claims.EMail != nil {
-				claimsBuilder.Email(state.OpenID.Claims.EMail...)
-			}
-			if state.OpenID.Claims.Name != nil {
-				claimsBuilder.Name(state.OpenID.Claims.Name...)
-			}
-			if state.OpenID.Claims.PreferredUsername != nil {
-				claimsBuilder.PreferredUsername(state.OpenID.Claims.PreferredUsername...)
-			}
-
-			openidBuilder.Claims(claimsBuilder)
-		}
-		if !state.OpenID.ClientID.Null {
-			openidBuilder.ClientID(state.OpenID.ClientID.Value)
-		}
-		if !state.OpenID.ClientSecret.Null {
-			openidBuilder.ClientSecret(state.OpenID.ClientSecret.Value)
-		}
-		if state.OpenID.ExtraAuthorizeParameters != nil {
-			openidBuilder.ExtraAuthorizeParameters(state.OpenID.ExtraAuthorizeParameters)
-		}
-		if state.OpenID.ExtraScopes != nil {
-			openidBuilder.ExtraScopes(state.OpenID.ExtraScopes...)
-		}
-		if !state.OpenID.Issuer.Null {
-			openidBuilder.Issuer(state.OpenID.Issuer.Value)
-		}
-		builder.OpenID(openidBuilder)
-	}
 	object, err := builder.Build()
 	if err != nil {
 		response.Diagnostics.AddError(
@@ -388,27 +641,7 @@ func (r *IdentityProviderResource) Create(ctx context.Context,
 	object = add.Body()
 
 	// Set the computed attributes:
-	state.ID = types.String{
-		Value: object.ID(),
-	}
-	htpasswdObject := object.Htpasswd()
-	ldapObject := object.LDAP()
-	openidObject := object.OpenID()
-	switch {
-	case htpasswdObject != nil:
-		// Nothing, there are no computed attributes for `htpasswd` identity providers.
-	case ldapObject != nil:
-		if state.LDAP == nil {
-			state.LDAP = &LDAPIdentityProvider{}
-		}
-		insecure, ok := ldapObject.GetInsecure()
-		if ok {
-			state.LDAP.Insecure = types.Bool{
-				Value: insecure,
-			}
-		}
-	case openidObject != nil:
-	}
+	populateComputedIdentityProvider(object, state)
 
 	// Save the state:
 	diags = response.State.Set(ctx, state)
@@ -447,13 +680,57 @@ func (r *IdentityProviderResource) Read(ctx context.Context, request tfsdk.ReadR
 	state.Name = types.String{
 		Value: object.Name(),
 	}
+	mappingMethod, ok := object.GetMappingMethod()
+	if ok {
+		state.MappingMethod = types.String{
+			Value: string(mappingMethod),
+		}
+	} else if state.MappingMethod.Unknown || state.MappingMethod.Null {
+		state.MappingMethod = types.String{
+			Value: string(cmv1.IdentityProviderMappingMethodClaim),
+		}
+	}
+	populateLoginOptions(object, state)
+	// Note that when the identity provider has been imported the state doesn't contain the
+	// details yet, so the attributes that aren't returned by the server start as null:
 	htpasswdObject := object.Htpasswd()
 	ldapObject := object.LDAP()
 	openidObject := object.OpenID()
+	githubObject := object.Github()
+	gitlabObject := object.Gitlab()
+	googleObject := object.Google()
 	switch {
 	case htpasswdObject != nil:
 		if state.HTPasswd == nil {
-			state.HTPasswd = &HTPasswdIdentityProvider{}
+			state.HTPasswd = &HTPasswdIdentityProvider{
+				Username: types.String{Null: true},
+				Password: types.String{Null: true},
+			}
+		}
+		if state.HTPasswd.Users != nil {
+			// The server doesn't return the passwords, so we can only detect users
+			// that have been removed. Users that aren't in the state aren't added
+			// because they may be managed with the `ocm_htpasswd_user` resource.
+			ids, err := listHTPasswdUsers(ctx, resource.HtpasswdUsers())
+			if err != nil {
+				response.Diagnostics.AddError(
+					"Can't list 'htpasswd' users",
+					fmt.Sprintf(
+						"Can't list users of identity provider with "+
+							"identifier '%s' for cluster '%s': %v",
+						state.ID.Value, state.Cluster.Value, err,
+					),
+				)
+				return
+			}
+			users := []HTPasswdUser{}
+			for _, user := range state.HTPasswd.Users {
+				if _, ok := ids[user.Username.Value]; ok {
+					users = append(users, user)
+				}
+			}
+			state.HTPasswd.Users = users
+			break
 		}
 		username, ok := htpasswdObject.GetUsername()
 		if ok {
@@ -469,7 +746,13 @@ func (r *IdentityProviderResource) Read(ctx context.Context, request tfsdk.ReadR
 		}
 	case ldapObject != nil:
 		if state.LDAP == nil {
-			state.LDAP = &LDAPIdentityProvider{}
+			state.LDAP = &LDAPIdentityProvider{
+				BindDN:       types.String{Null: true},
+				BindPassword: types.String{Null: true},
+				CA:           types.String{Null: true},
+				Insecure:     types.Bool{Null: true},
+				URL:          types.String{Null: true},
+			}
 		}
 		bindDN, ok := ldapObject.GetBindDN()
 		if ok {
@@ -525,7 +808,12 @@ func (r *IdentityProviderResource) Read(ctx context.Context, request tfsdk.ReadR
 		}
 	case openidObject != nil:
 		if state.OpenID == nil {
-			state.OpenID = &OpenIDIdentityProvider{}
+			state.OpenID = &OpenIDIdentityProvider{
+				CA:           types.String{Null: true},
+				ClientID:     types.String{Null: true},
+				ClientSecret: types.String{Null: true},
+				Issuer:       types.String{Null: true},
+			}
 		}
 		ca, ok := openidObject.GetCA()
 		if ok {
@@ -573,12 +861,94 @@ func (r *IdentityProviderResource) Read(ctx context.Context, request tfsdk.ReadR
 				Value: issuer,
 			}
 		}
-	}
-	response.Diagnostics.Append(diags...)
-	if response.Diagnostics.HasError() {
-		return
-	}
-
+	case githubObject != nil:
+		if state.GitHub == nil {
+			state.GitHub = &GitHubIdentityProvider{
+				CA:           types.String{Null: true},
+				ClientID:     types.String{Null: true},
+				ClientSecret: types.String{Null: true},
+				Hostname:     types.String{Null: true},
+			}
+		}
+		ca, ok := githubObject.GetCA()
+		if ok {
+			state.GitHub.CA = types.String{
+				Value: ca,
+			}
+		}
+		clientID, ok := githubObject.GetClientID()
+		if ok {
+			state.GitHub.ClientID = types.String{
+				Value: clientID,
+			}
+		}
+		hostname, ok := githubObject.GetHostname()
+		if ok {
+			state.GitHub.Hostname = types.String{
+				Value: hostname,
+			}
+		}
+		organizations, ok := githubObject.GetOrganizations()
+		if ok {
+			state.GitHub.Organizations = organizations
+		}
+		teams, ok := githubObject.GetTeams()
+		if ok {
+			state.GitHub.Teams = teams
+		}
+	case gitlabObject != nil:
+		if state.GitLab == nil {
+			state.GitLab = &GitLabIdentityProvider{
+				CA:           types.String{Null: true},
+				ClientID:     types.String{Null: true},
+				ClientSecret: types.String{Null: true},
+				URL:          types.String{Null: true},
+			}
+		}
+		ca, ok := gitlabObject.GetCA()
+		if ok {
+			state.GitLab.CA = types.String{
+				Value: ca,
+			}
+		}
+		clientID, ok := gitlabObject.GetClientID()
+		if ok {
+			state.GitLab.ClientID = types.String{
+				Value: clientID,
+			}
+		}
+		url, ok := gitlabObject.GetURL()
+		if ok {
+			state.GitLab.URL = types.String{
+				Value: url,
+			}
+		}
+	case googleObject != nil:
+		if state.Google == nil {
+			state.Google = &GoogleIdentityProvider{
+				ClientID:     types.String{Null: true},
+				ClientSecret: types.String{Null: true},
+				HostedDomain: types.String{Null: true},
+			}
+		}
+		clientID, ok := googleObject.GetClientID()
+		if ok {
+			state.Google.ClientID = types.String{
+				Value: clientID,
+			}
+		}
+		hostedDomain, ok := googleObject.GetHostedDomain()
+		if ok {
+			state.Google.HostedDomain = types.String{
+				Value: hostedDomain,
+			}
+		}
+	}
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
 	// Save the state:
 	diags = response.State.Set(ctx, state)
 	response.Diagnostics.Append(diags...)
@@ -586,6 +956,112 @@ func (r *IdentityProviderResource) Read(ctx context.Context, request tfsdk.ReadR
 
 func (r *IdentityProviderResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest,
 	response *tfsdk.UpdateResourceResponse) {
+	var diags diag.Diagnostics
+
+	// Get the state:
+	state := &IdentityProviderState{}
+	diags = request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Get the plan:
+	plan := &IdentityProviderState{}
+	diags = request.Plan.Get(ctx, plan)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Apply the update timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, plan.Timeouts.UpdateTimeout())
+	defer cancel()
+
+	// The users of 'htpasswd' identity providers aren't part of the patch, instead the
+	// differences between the state and the plan are applied using the collection of users:
+	resource := r.collection.Cluster(state.Cluster.Value).
+		IdentityProviders().
+		IdentityProvider(state.ID.Value)
+	if plan.HTPasswd != nil && plan.HTPasswd.Users != nil {
+		// A single user created with the 'username' and 'password' attributes is
+		// handled as if it had been in the list of users:
+		var stateUsers []HTPasswdUser
+		if state.HTPasswd != nil {
+			stateUsers = state.HTPasswd.Users
+			if !state.HTPasswd.Username.Null {
+				stateUsers = append(stateUsers, HTPasswdUser{
+					Username: state.HTPasswd.Username,
+					Password: state.HTPasswd.Password,
+				})
+			}
+		}
+		err := updateHTPasswdUsers(ctx, resource.HtpasswdUsers(), stateUsers,
+			plan.HTPasswd.Users)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't update 'htpasswd' users",
+				fmt.Sprintf(
+					"Can't update users of identity provider with "+
+						"identifier '%s' for cluster '%s': %v",
+					state.ID.Value, state.Cluster.Value, err,
+				),
+			)
+			return
+		}
+
+		// If the rest of the attributes haven't changed there is nothing else to send:
+		if plan.MappingMethod.Equal(state.MappingMethod) &&
+			plan.Login.Equal(state.Login) &&
+			plan.Challenge.Equal(state.Challenge) {
+			plan.ID = state.ID
+			diags = response.State.Set(ctx, plan)
+			response.Diagnostics.Append(diags...)
+			return
+		}
+	}
+
+	// Send the complete details of the identity provider, as the server replaces them. Note
+	// that the name and the type can't be changed, changing them forces the replacement of
+	// the identity provider.
+	builder := buildIdentityProvider(plan)
+	if plan.HTPasswd != nil && plan.HTPasswd.Users != nil {
+		// The users have already been updated above, don't send them again:
+		builder.Htpasswd(cmv1.NewHTPasswdIdentityProvider())
+	}
+	patch, err := builder.Build()
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't build identity provider patch",
+			fmt.Sprintf(
+				"Can't build patch for identity provider with name '%s': %v",
+				state.Name.Value, err,
+			),
+		)
+		return
+	}
+	update, err := resource.Update().Body(patch).SendContext(ctx)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't update identity provider",
+			fmt.Sprintf(
+				"Can't update identity provider with identifier '%s' for "+
+					"cluster '%s': %v",
+				state.ID.Value, state.Cluster.Value, err,
+			),
+		)
+		return
+	}
+	object := update.Body()
+
+	// The server doesn't return the secrets, so the new state is the plan with the computed
+	// attributes copied from the response:
+	populateComputedIdentityProvider(object, plan)
+	plan.ID = state.ID
+
+	// Save the state:
+	diags = response.State.Set(ctx, plan)
+	response.Diagnostics.Append(diags...)
 }
 
 func (r *IdentityProviderResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest,
@@ -598,6 +1074,10 @@ func (r *IdentityProviderResource) Delete(ctx context.Context, request tfsdk.Del
 		return
 	}
 
+	// Apply the delete timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, state.Timeouts.DeleteTimeout())
+	defer cancel()
+
 	// Send the request to delete the identity provider:
 	resource := r.collection.Cluster(state.Cluster.Value).
 		IdentityProviders().
@@ -621,10 +1101,368 @@ func (r *IdentityProviderResource) Delete(ctx context.Context, request tfsdk.Del
 
 func (r *IdentityProviderResource) ImportState(ctx context.Context, request tfsdk.ImportResourceStateRequest,
 	response *tfsdk.ImportResourceStateResponse) {
-	tfsdk.ResourceImportStatePassthroughID(
+	// The identifier of the identity provider is only unique within the cluster, so the import
+	// identifier must contain both:
+	parts, err := parseImportID(request.ID, "cluster_id", "identity_provider_id")
+	if err != nil {
+		response.Diagnostics.AddError("Invalid import identifier", err.Error())
+		return
+	}
+	diags := response.State.SetAttribute(
+		ctx,
+		tftypes.NewAttributePath().WithAttributeName("cluster"),
+		parts[0],
+	)
+	response.Diagnostics.Append(diags...)
+	diags = response.State.SetAttribute(
 		ctx,
 		tftypes.NewAttributePath().WithAttributeName("id"),
-		request,
-		response,
+		parts[1],
 	)
+	response.Diagnostics.Append(diags...)
+}
+
+// buildIdentityProvider creates the builder for the type and details of the identity provider
+// described by the given state. The name isn't included, as it can't be changed once the identity
+// provider has been created.
+func buildIdentityProvider(state *IdentityProviderState) *cmv1.IdentityProviderBuilder {
+	builder := cmv1.NewIdentityProvider()
+	if !state.MappingMethod.Null && !state.MappingMethod.Unknown {
+		builder.MappingMethod(cmv1.IdentityProviderMappingMethod(state.MappingMethod.Value))
+	}
+	if !state.Login.Null && !state.Login.Unknown {
+		builder.Login(state.Login.Value)
+	}
+	if !state.Challenge.Null && !state.Challenge.Unknown {
+		builder.Challenge(state.Challenge.Value)
+	}
+	switch {
+	case state.HTPasswd != nil:
+		builder.Type(cmv1.IdentityProviderType("HTPasswdIdentityProvider"))
+		htpasswdBuilder := cmv1.NewHTPasswdIdentityProvider()
+		if !state.HTPasswd.Username.Null {
+			htpasswdBuilder.Username(state.HTPasswd.Username.Value)
+		}
+		if !state.HTPasswd.Password.Null {
+			htpasswdBuilder.Password(state.HTPasswd.Password.Value)
+		}
+		if state.HTPasswd.Users != nil {
+			usersBuilders := make([]*cmv1.HTPasswdUserBuilder, len(state.HTPasswd.Users))
+			for i, user := range state.HTPasswd.Users {
+				usersBuilders[i] = cmv1.NewHTPasswdUser().
+					Username(user.Username.Value).
+					Password(user.Password.Value)
+			}
+			htpasswdBuilder.Users(cmv1.NewHTPasswdUserList().Items(usersBuilders...))
+		}
+		builder.Htpasswd(htpasswdBuilder)
+	case state.LDAP != nil:
+		builder.Type(cmv1.IdentityProviderType("LDAPIdentityProvider"))
+		ldapBuilder := cmv1.NewLDAPIdentityProvider()
+		if !state.LDAP.BindDN.Null {
+			ldapBuilder.BindDN(state.LDAP.BindDN.Value)
+		}
+		if !state.LDAP.BindPassword.Null {
+			ldapBuilder.BindPassword(state.LDAP.BindPassword.Value)
+		}
+		if !state.LDAP.CA.Null {
+			ldapBuilder.CA(state.LDAP.CA.Value)
+		}
+		if !state.LDAP.Insecure.Null {
+			ldapBuilder.Insecure(state.LDAP.Insecure.Value)
+		}
+		if !state.LDAP.URL.Null {
+			ldapBuilder.URL(state.LDAP.URL.Value)
+		}
+		if state.LDAP.Attributes != nil {
+			attributesBuilder := cmv1.NewLDAPAttributes()
+			if state.LDAP.Attributes.ID != nil {
+				attributesBuilder.ID(state.LDAP.Attributes.ID...)
+			}
+			if state.LDAP.Attributes.EMail != nil {
+				attributesBuilder.Email(state.LDAP.Attributes.EMail...)
+			}
+			if state.LDAP.Attributes.Name != nil {
+				attributesBuilder.Name(state.LDAP.Attributes.Name...)
+			}
+			if state.LDAP.Attributes.PreferredUsername != nil {
+				attributesBuilder.PreferredUsername(
+					state.LDAP.Attributes.PreferredUsername...,
+				)
+			}
+			ldapBuilder.Attributes(attributesBuilder)
+		}
+		builder.LDAP(ldapBuilder)
+	case state.OpenID != nil:
+		builder.Type(cmv1.IdentityProviderType("OpenIDIdentityProvider"))
+		openidBuilder := cmv1.NewOpenIDIdentityProvider()
+		if !state.OpenID.CA.Null {
+			openidBuilder.CA(state.OpenID.CA.Value)
+		}
+		if state.OpenID.Claims != nil {
+			claimsBuilder := cmv1.NewOpenIDClaims()
+
+			if state.OpenID.Claims.Groups != nil {
+				claimsBuilder.Groups(state.OpenID.Claims.Groups...)
+			}
+			if state.OpenID.Claims.EMail != nil {
+				claimsBuilder.Email(state.OpenID.Claims.EMail...)
+			}
+			if state.OpenID.Claims.Name != nil {
+				claimsBuilder.Name(state.OpenID.Claims.Name...)
+			}
+			if state.OpenID.Claims.PreferredUsername != nil {
+				claimsBuilder.PreferredUsername(state.OpenID.Claims.PreferredUsername...)
+			}
+
+			openidBuilder.Claims(claimsBuilder)
+		}
+		if !state.OpenID.ClientID.Null {
+			openidBuilder.ClientID(state.OpenID.ClientID.Value)
+		}
+		if !state.OpenID.ClientSecret.Null {
+			openidBuilder.ClientSecret(state.OpenID.ClientSecret.Value)
+		}
+		if state.OpenID.ExtraAuthorizeParameters != nil {
+			openidBuilder.ExtraAuthorizeParameters(state.OpenID.ExtraAuthorizeParameters)
+		}
+		if state.OpenID.ExtraScopes != nil {
+			openidBuilder.ExtraScopes(state.OpenID.ExtraScopes...)
+		}
+		if !state.OpenID.Issuer.Null {
+			openidBuilder.Issuer(state.OpenID.Issuer.Value)
+		}
+		builder.OpenID(openidBuilder)
+	case state.GitHub != nil:
+		builder.Type(cmv1.IdentityProviderTypeGithub)
+		githubBuilder := cmv1.NewGithubIdentityProvider()
+		if !state.GitHub.CA.Null {
+			githubBuilder.CA(state.GitHub.CA.Value)
+		}
+		if !state.GitHub.ClientID.Null {
+			githubBuilder.ClientID(state.GitHub.ClientID.Value)
+		}
+		if !state.GitHub.ClientSecret.Null {
+			githubBuilder.ClientSecret(state.GitHub.ClientSecret.Value)
+		}
+		if !state.GitHub.Hostname.Null {
+			githubBuilder.Hostname(state.GitHub.Hostname.Value)
+		}
+		if state.GitHub.Organizations != nil {
+			githubBuilder.Organizations(state.GitHub.Organizations...)
+		}
+		if state.GitHub.Teams != nil {
+			githubBuilder.Teams(state.GitHub.Teams...)
+		}
+		builder.Github(githubBuilder)
+	case state.GitLab != nil:
+		builder.Type(cmv1.IdentityProviderTypeGitlab)
+		gitlabBuilder := cmv1.NewGitlabIdentityProvider()
+		if !state.GitLab.CA.Null {
+			gitlabBuilder.CA(state.GitLab.CA.Value)
+		}
+		if !state.GitLab.ClientID.Null {
+			gitlabBuilder.ClientID(state.GitLab.ClientID.Value)
+		}
+		if !state.GitLab.ClientSecret.Null {
+			gitlabBuilder.ClientSecret(state.GitLab.ClientSecret.Value)
+		}
+		if !state.GitLab.URL.Null {
+			gitlabBuilder.URL(state.GitLab.URL.Value)
+		}
+		builder.Gitlab(gitlabBuilder)
+	case state.Google != nil:
+		builder.Type(cmv1.IdentityProviderTypeGoogle)
+		googleBuilder := cmv1.NewGoogleIdentityProvider()
+		if !state.Google.ClientID.Null {
+			googleBuilder.ClientID(state.Google.ClientID.Value)
+		}
+		if !state.Google.ClientSecret.Null {
+			googleBuilder.ClientSecret(state.Google.ClientSecret.Value)
+		}
+		if !state.Google.HostedDomain.Null {
+			googleBuilder.HostedDomain(state.Google.HostedDomain.Value)
+		}
+		builder.Google(googleBuilder)
+	}
+	return builder
+}
+
+// populateComputedIdentityProvider copies the computed attributes from the API object to the
+// Terraform state.
+func populateComputedIdentityProvider(object *cmv1.IdentityProvider, state *IdentityProviderState) {
+	state.ID = types.String{
+		Value: object.ID(),
+	}
+	mappingMethod, ok := object.GetMappingMethod()
+	if ok {
+		state.MappingMethod = types.String{
+			Value: string(mappingMethod),
+		}
+	} else if state.MappingMethod.Unknown {
+		state.MappingMethod = types.String{
+			Value: string(cmv1.IdentityProviderMappingMethodClaim),
+		}
+	}
+	populateLoginOptions(object, state)
+	htpasswdObject := object.Htpasswd()
+	ldapObject := object.LDAP()
+	openidObject := object.OpenID()
+	switch {
+	case htpasswdObject != nil:
+		// Nothing, there are no computed attributes for `htpasswd` identity providers.
+	case ldapObject != nil:
+		if state.LDAP == nil {
+			state.LDAP = &LDAPIdentityProvider{}
+		}
+		insecure, ok := ldapObject.GetInsecure()
+		if ok {
+			state.LDAP.Insecure = types.Bool{
+				Value: insecure,
+			}
+		} else if state.LDAP.Insecure.Unknown {
+			state.LDAP.Insecure = types.Bool{
+				Value: false,
+			}
+		}
+	case openidObject != nil:
+	}
+}
+
+// populateLoginOptions copies the 'login' and 'challenge' flags from the API object to the
+// Terraform state. When the server doesn't return them and they haven't been set in the
+// configuration they are set to null, as the actual value depends on the identity provider.
+func populateLoginOptions(object *cmv1.IdentityProvider, state *IdentityProviderState) {
+	login, ok := object.GetLogin()
+	if ok {
+		state.Login = types.Bool{
+			Value: login,
+		}
+	} else if state.Login.Unknown {
+		state.Login = types.Bool{
+			Null: true,
+		}
+	}
+	challenge, ok := object.GetChallenge()
+	if ok {
+		state.Challenge = types.Bool{
+			Value: challenge,
+		}
+	} else if state.Challenge.Unknown {
+		state.Challenge = types.Bool{
+			Null: true,
+		}
+	}
+}
+
+// identityProviderTypeChanged checks if the type of the identity provider has changed, which
+// happens when the attribute that contains the details of one type is added or removed.
+func identityProviderTypeChanged(ctx context.Context, state, config attr.Value,
+	path *tftypes.AttributePath) (result bool, diags diag.Diagnostics) {
+	stateValue, err := state.ToTerraformValue(ctx)
+	if err != nil {
+		diags.AddAttributeError(path, "Can't convert state value", err.Error())
+		return
+	}
+	configValue, err := config.ToTerraformValue(ctx)
+	if err != nil {
+		diags.AddAttributeError(path, "Can't convert configuration value", err.Error())
+		return
+	}
+	result = (stateValue == nil) != (configValue == nil)
+	return
+}
+
+// listHTPasswdUsers returns a map containing the identifiers of the users of an 'htpasswd'
+// identity provider, indexed by user name.
+func listHTPasswdUsers(ctx context.Context,
+	client *cmv1.HTPasswdUsersClient) (result map[string]string, err error) {
+	result = map[string]string{}
+	listSize := 100
+	listPage := 1
+	listRequest := client.List().Size(listSize)
+	for {
+		var listResponse *cmv1.HTPasswdUsersListResponse
+		listResponse, err = listRequest.SendContext(ctx)
+		if err != nil {
+			return
+		}
+		listResponse.Items().Each(func(listItem *cmv1.HTPasswdUser) bool {
+			result[listItem.Username()] = listItem.ID()
+			return true
+		})
+		if listResponse.Size() < listSize {
+			break
+		}
+		listPage++
+		listRequest.Page(listPage)
+	}
+	return
+}
+
+// updateHTPasswdUsers applies to the users of an 'htpasswd' identity provider the differences
+// between the users in the state and the users in the plan. Users are matched by user name, and
+// only the passwords of users that already exist are updated.
+func updateHTPasswdUsers(ctx context.Context, client *cmv1.HTPasswdUsersClient,
+	stateUsers, planUsers []HTPasswdUser) error {
+	ids, err := listHTPasswdUsers(ctx, client)
+	if err != nil {
+		return err
+	}
+	statePasswords := map[string]string{}
+	for _, user := range stateUsers {
+		statePasswords[user.Username.Value] = user.Password.Value
+	}
+	planUsernames := map[string]bool{}
+	for _, user := range planUsers {
+		planUsernames[user.Username.Value] = true
+	}
+
+	// Remove the users that are no longer in the plan:
+	for _, user := range stateUsers {
+		username := user.Username.Value
+		id, ok := ids[username]
+		if planUsernames[username] || !ok {
+			continue
+		}
+		_, err = client.HtpasswdUser(id).Delete().SendContext(ctx)
+		if err != nil {
+			return fmt.Errorf("can't delete user '%s': %v", username, err)
+		}
+	}
+
+	// Add the new users and update the passwords of the ones that changed:
+	for _, user := range planUsers {
+		username := user.Username.Value
+		password := user.Password.Value
+		id, exists := ids[username]
+		statePassword, managed := statePasswords[username]
+		switch {
+		case !exists:
+			body, err := cmv1.NewHTPasswdUser().
+				Username(username).
+				Password(password).
+				Build()
+			if err != nil {
+				return err
+			}
+			_, err = client.Add().Body(body).SendContext(ctx)
+			if err != nil {
+				return fmt.Errorf("can't add user '%s': %v", username, err)
+			}
+		case !managed || statePassword != password:
+			body, err := cmv1.NewHTPasswdUser().
+				Password(password).
+				Build()
+			if err != nil {
+				return err
+			}
+			_, err = client.HtpasswdUser(id).Update().Body(body).SendContext(ctx)
+			if err != nil {
+				return fmt.Errorf("can't update user '%s': %v", username, err)
+			}
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,145 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	. "github.com/onsi/ginkgo/v2/dsl/core"  // nolint
+	. "github.com/onsi/ginkgo/v2/dsl/table" // nolint
+	. "github.com/onsi/gomega"              // nolint
+)
+
+var _ = Describe("HTPasswd identity provider", func() {
+	user := func(username, password string) HTPasswdUser {
+		return HTPasswdUser{
+			Username: types.String{Value: username},
+			Password: types.String{Value: password},
+		}
+	}
+	null := types.String{Null: true}
+
+	DescribeTable("Validates the users",
+		func(htpasswd *HTPasswdIdentityProvider, expected string) {
+			var diags diag.Diagnostics
+			validateHTPasswdIdentityProvider(htpasswd, &diags)
+			if expected == "" {
+				Expect(diags.HasError()).To(BeFalse())
+			} else {
+				Expect(diags.HasError()).To(BeTrue())
+				Expect(diags[0].Detail()).To(ContainSubstring(expected))
+			}
+		},
+		Entry(
+			"Single user",
+			&HTPasswdIdentityProvider{
+				Username: types.String{Value: "my-user"},
+				Password: types.String{Value: "my-password"},
+			},
+			"",
+		),
+		Entry(
+			"List of users",
+			&HTPasswdIdentityProvider{
+				Username: null,
+				Password: null,
+				Users: []HTPasswdUser{
+					user("my-user", "my-password"),
+					user("your-user", "your-password"),
+				},
+			},
+			"",
+		),
+		Entry(
+			"Single user without password",
+			&HTPasswdIdentityProvider{
+				Username: types.String{Value: "my-user"},
+				Password: null,
+			},
+			"are required unless",
+		),
+		Entry(
+			"Single user and list of users",
+			&HTPasswdIdentityProvider{
+				Username: types.String{Value: "my-user"},
+				Password: types.String{Value: "my-password"},
+				Users: []HTPasswdUser{
+					user("your-user", "your-password"),
+				},
+			},
+			"can't be used together",
+		),
+		Entry(
+			"Empty list of users",
+			&HTPasswdIdentityProvider{
+				Username: null,
+				Password: null,
+				Users:    []HTPasswdUser{},
+			},
+			"at least one user",
+		),
+		Entry(
+			"Duplicated user",
+			&HTPasswdIdentityProvider{
+				Username: null,
+				Password: null,
+				Users: []HTPasswdUser{
+					user("my-user", "my-password"),
+					user("my-user", "your-password"),
+				},
+			},
+			"more than once",
+		),
+	)
+})
+
+var _ = Describe("GitHub identity provider", func() {
+	DescribeTable("Validates the organizations and teams",
+		func(github *GitHubIdentityProvider, expected string) {
+			var diags diag.Diagnostics
+			validateGitHubIdentityProvider(github, &diags)
+			if expected == "" {
+				Expect(diags.HasError()).To(BeFalse())
+			} else {
+				Expect(diags.HasError()).To(BeTrue())
+				Expect(diags[0].Detail()).To(ContainSubstring(expected))
+			}
+		},
+		Entry(
+			"Organizations",
+			&GitHubIdentityProvider{
+				Organizations: []string{"my-org"},
+			},
+			"",
+		),
+		Entry(
+			"Teams",
+			&GitHubIdentityProvider{
+				Teams: []string{"my-org/my-team"},
+			},
+			"",
+		),
+		Entry(
+			"Organizations and teams",
+			&GitHubIdentityProvider{
+				Organizations: []string{"my-org"},
+				Teams:         []string{"my-org/my-team"},
+			},
+			"can't be used together",
+		),
+	)
+})
@@ -21,15 +21,28 @@ import (
 )
 
 type IdentityProviderState struct {
-	Cluster  types.String              `tfsdk:"cluster"`
-	ID       types.String              `tfsdk:"id"`
-	Name     types.String              `tfsdk:"name"`
-	HTPasswd *HTPasswdIdentityProvider `tfsdk:"htpasswd"`
-	LDAP     *LDAPIdentityProvider     `tfsdk:"ldap"`
-	OpenID   *OpenIDIdentityProvider   `tfsdk:"openid"`
+	Cluster       types.String              `tfsdk:"cluster"`
+	ID            types.String              `tfsdk:"id"`
+	Name          types.String              `tfsdk:"name"`
+	MappingMethod types.String              `tfsdk:"mapping_method"`
+	Login         types.Bool                `tfsdk:"login"`
+	Challenge     types.Bool                `tfsdk:"challenge"`
+	HTPasswd      *HTPasswdIdentityProvider `tfsdk:"htpasswd"`
+	LDAP          *LDAPIdentityProvider     `tfsdk:"ldap"`
+	OpenID        *OpenIDIdentityProvider   `tfsdk:"openid"`
+	GitHub        *GitHubIdentityProvider   `tfsdk:"github"`
+	GitLab        *GitLabIdentityProvider   `tfsdk:"gitlab"`
+	Google        *GoogleIdentityProvider   `tfsdk:"google"`
+	Timeouts      *Timeouts                 `tfsdk:"timeouts"`
 }
 
 type HTPasswdIdentityProvider struct {
+	Username types.String   `tfsdk:"username"`
+	Password types.String   `tfsdk:"password"`
+	Users    []HTPasswdUser `tfsdk:"users"`
+}
+
+type HTPasswdUser struct {
 	Username types.String `tfsdk:"username"`
 	Password types.String `tfsdk:"password"`
 }
@@ -66,3 +79,25 @@ type OpenIDIdentityProviderClaims struct {
 	Name              []string `tfsdk:"name"`
 	PreferredUsername []string `tfsdk:"preferred_username"`
 }
+
+type GitHubIdentityProvider struct {
+	CA            types.String `tfsdk:"ca"`
+	ClientID      types.String `tfsdk:"client_id"`
+	ClientSecret  types.String `tfsdk:"client_secret"`
+	Hostname      types.String `tfsdk:"hostname"`
+	Organizations []string     `tfsdk:"organizations"`
+	Teams         []string     `tfsdk:"teams"`
+}
+
+type GitLabIdentityProvider struct {
+	CA           types.String `tfsdk:"ca"`
+	ClientID     types.String `tfsdk:"client_id"`
+	ClientSecret types.String `tfsdk:"client_secret"`
+	URL          types.String `tfsdk:"url"`
+}
+
+type GoogleIdentityProvider struct {
+	ClientID     types.String `tfsdk:"client_id"`
+	ClientSecret types.String `tfsdk:"client_secret"`
+	HostedDomain types.String `tfsdk:"hosted_domain"`
+}
@@ -0,0 +1,325 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+// redacted is the text that replaces the values of sensitive fields in the logs.
+const redacted = "REDACTED"
+
+// sensitiveFields contains the names of the fields of JSON and form bodies whose values are
+// never written to the logs.
+var sensitiveFields = map[string]bool{
+	"access_token":      true,
+	"bind_password":     true,
+	"client_secret":     true,
+	"id_token":          true,
+	"kubeconfig":        true,
+	"password":          true,
+	"private_key":       true,
+	"refresh_token":     true,
+	"secret":            true,
+	"secret_access_key": true,
+	"token":             true,
+}
+
+// sensitiveHeaders contains the names of the HTTP headers whose values are never written to the
+// logs.
+var sensitiveHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+}
+
+// TFLogLogger is an implementation of the logger interface of the SDK that sends the messages
+// to the logging infrastructure of Terraform, so that they are structured and tagged with the
+// details of the RPC that is being processed, like the resource type.
+type TFLogLogger struct {
+}
+
+// Make sure that we implement the interface:
+var _ logging.Logger = (*TFLogLogger)(nil)
+
+// DebugEnabled always returns false. The SDK uses it to decide if it should dump the HTTP
+// traffic, including secrets, and that is done instead by the LoggingTransportWrapper. Debug
+// messages are still sent to Terraform, which filters them according to the TF_LOG
+// environment variable.
+func (l *TFLogLogger) DebugEnabled() bool {
+	return false
+}
+
+func (l *TFLogLogger) InfoEnabled() bool {
+	return true
+}
+
+func (l *TFLogLogger) WarnEnabled() bool {
+	return true
+}
+
+func (l *TFLogLogger) ErrorEnabled() bool {
+	return true
+}
+
+func (l *TFLogLogger) Debug(ctx context.Context, format string, args ...interface{}) {
+	tflog.Debug(ctx, fmt.Sprintf(format, args...))
+}
+
+func (l *TFLogLogger) Info(ctx context.Context, format string, args ...interface{}) {
+	tflog.Info(ctx, fmt.Sprintf(format, args...))
+}
+
+func (l *TFLogLogger) Warn(ctx context.Context, format string, args ...interface{}) {
+	tflog.Warn(ctx, fmt.Sprintf(format, args...))
+}
+
+func (l *TFLogLogger) Error(ctx context.Context, format string, args ...interface{}) {
+	tflog.Error(ctx, fmt.Sprintf(format, args...))
+}
+
+func (l *TFLogLogger) Fatal(ctx context.Context, format string, args ...interface{}) {
+	tflog.Error(ctx, fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+// LoggingTransportWrapper wraps the HTTP transport used by the connection to the OCM API so
+// that every request is written to the Terraform log with its method, path, status code and
+// latency. The bodies, with the sensitive fields redacted, are written as well when the trace
+// level is enabled, and to the trace file if there is one.
+type LoggingTransportWrapper struct {
+	// Bodies indicates if the redacted bodies should be written to the log.
+	Bodies bool
+
+	// Trace receives a JSON document for each HTTP exchange. It is optional.
+	Trace io.Writer
+
+	lock sync.Mutex
+}
+
+type loggingRoundTripper struct {
+	wrapper   *LoggingTransportWrapper
+	transport http.RoundTripper
+}
+
+// traceRecord is the JSON document written to the trace file for each HTTP exchange.
+type traceRecord struct {
+	Time            string      `json:"time"`
+	Method          string      `json:"method"`
+	URL             string      `json:"url"`
+	Status          int         `json:"status,omitempty"`
+	Latency         string      `json:"latency"`
+	RequestHeaders  http.Header `json:"request_headers,omitempty"`
+	RequestBody     string      `json:"request_body,omitempty"`
+	ResponseHeaders http.Header `json:"response_headers,omitempty"`
+	ResponseBody    string      `json:"response_body,omitempty"`
+	Error           string      `json:"error,omitempty"`
+}
+
+// Wrap creates a round tripper that wraps the given one and adds the logging logic.
+func (w *LoggingTransportWrapper) Wrap(transport http.RoundTripper) http.RoundTripper {
+	return &loggingRoundTripper{
+		wrapper:   w,
+		transport: transport,
+	}
+}
+
+func (t *loggingRoundTripper) RoundTrip(request *http.Request) (response *http.Response,
+	err error) {
+	ctx := request.Context()
+	bodies := t.wrapper.Bodies || t.wrapper.Trace != nil
+
+	// Copy the request body, if we need it:
+	var requestBody []byte
+	if bodies && request.Body != nil {
+		requestBody, err = ioutil.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return
+		}
+		request.Body = ioutil.NopCloser(bytes.NewReader(requestBody))
+	}
+
+	// Send the request:
+	start := time.Now()
+	response, err = t.transport.RoundTrip(request)
+	latency := time.Since(start)
+
+	// Copy the response body, if we need it:
+	var responseBody []byte
+	if bodies && err == nil && response.Body != nil {
+		responseBody, err = ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			response = nil
+			return
+		}
+		response.Body = ioutil.NopCloser(bytes.NewReader(responseBody))
+	}
+
+	// Write the log:
+	fields := []interface{}{
+		"http_method", request.Method,
+		"http_path", request.URL.Path,
+		"latency_ms", latency.Milliseconds(),
+	}
+	if err != nil {
+		fields = append(fields, "error", err.Error())
+		tflog.Debug(ctx, "OCM API request failed", fields...)
+	} else {
+		fields = append(fields, "http_status", response.StatusCode)
+		tflog.Debug(ctx, "OCM API request", fields...)
+	}
+	if t.wrapper.Bodies {
+		fields = append(
+			fields,
+			"http_request_body", redactBody(request.Header, requestBody),
+		)
+		if err == nil {
+			fields = append(
+				fields,
+				"http_response_body", redactBody(response.Header, responseBody),
+			)
+		}
+		tflog.Trace(ctx, "OCM API request bodies", fields...)
+	}
+
+	// Write the trace:
+	if t.wrapper.Trace != nil {
+		record := &traceRecord{
+			Time:           start.UTC().Format(time.RFC3339Nano),
+			Method:         request.Method,
+			URL:            request.URL.String(),
+			Latency:        latency.String(),
+			RequestHeaders: redactHeaders(request.Header),
+			RequestBody:    redactBody(request.Header, requestBody),
+		}
+		if err != nil {
+			record.Error = err.Error()
+		} else {
+			record.Status = response.StatusCode
+			record.ResponseHeaders = redactHeaders(response.Header)
+			record.ResponseBody = redactBody(response.Header, responseBody)
+		}
+		t.wrapper.write(ctx, record)
+	}
+
+	return
+}
+
+func (w *LoggingTransportWrapper) write(ctx context.Context, record *traceRecord) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		tflog.Warn(ctx, "Can't serialize trace record", "error", err.Error())
+		return
+	}
+	data = append(data, '\n')
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	_, err = w.Trace.Write(data)
+	if err != nil {
+		tflog.Warn(ctx, "Can't write trace record", "error", err.Error())
+	}
+}
+
+// redactHeaders returns a copy of the given headers where the values of the sensitive ones
+// have been replaced.
+func redactHeaders(headers http.Header) http.Header {
+	result := http.Header{}
+	for name, values := range headers {
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			result[name] = []string{redacted}
+		} else {
+			result[name] = values
+		}
+	}
+	return result
+}
+
+// redactBody returns a representation of the given body, suitable for the logs, where the
+// values of the sensitive fields have been replaced. Only JSON and form bodies are supported,
+// for other kinds of bodies only the size is returned.
+func redactBody(headers http.Header, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	contentType, _, _ := mime.ParseMediaType(headers.Get("Content-Type"))
+	if contentType == "application/x-www-form-urlencoded" {
+		values, err := url.ParseQuery(string(body))
+		if err == nil {
+			for name := range values {
+				if sensitiveFields[strings.ToLower(name)] {
+					values[name] = []string{redacted}
+				}
+			}
+			return values.Encode()
+		}
+	}
+	var data interface{}
+	err := json.Unmarshal(body, &data)
+	if err == nil {
+		data, err := json.Marshal(redactValue(data))
+		if err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprintf("<%d bytes of '%s' omitted>", len(body), contentType)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for name, field := range typed {
+			if sensitiveFields[strings.ToLower(name)] {
+				typed[name] = redacted
+			} else {
+				typed[name] = redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range typed {
+			typed[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+// traceEnabled checks if the trace level has been requested for the provider logs.
+func traceEnabled() bool {
+	for _, name := range []string{"TF_LOG_PROVIDER", "TF_LOG"} {
+		level, ok := os.LookupEnv(name)
+		if ok && level != "" {
+			return strings.EqualFold(level, "TRACE")
+		}
+	}
+	return false
+}
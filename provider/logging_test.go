@@ -0,0 +1,173 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/onsi/gomega/ghttp"                   // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+)
+
+var _ = Describe("Logging", func() {
+	jsonHeaders := http.Header{
+		"Content-Type": []string{"application/json"},
+	}
+
+	It("Redacts sensitive fields of JSON bodies", func() {
+		body := redactBody(jsonHeaders, []byte(`{
+		  "name": "my-idp",
+		  "ldap": {
+		    "bind_dn": "cn=admin",
+		    "bind_password": "my-password"
+		  },
+		  "users": [
+		    {
+		      "username": "my-user",
+		      "password": "my-other-password"
+		    }
+		  ]
+		}`))
+		Expect(body).ToNot(ContainSubstring("my-password"))
+		Expect(body).ToNot(ContainSubstring("my-other-password"))
+		Expect(body).To(MatchJSON(`{
+		  "name": "my-idp",
+		  "ldap": {
+		    "bind_dn": "cn=admin",
+		    "bind_password": "REDACTED"
+		  },
+		  "users": [
+		    {
+		      "username": "my-user",
+		      "password": "REDACTED"
+		    }
+		  ]
+		}`))
+	})
+
+	It("Redacts sensitive fields of form bodies", func() {
+		headers := http.Header{
+			"Content-Type": []string{"application/x-www-form-urlencoded"},
+		}
+		body := redactBody(
+			headers,
+			[]byte("grant_type=client_credentials&client_id=my-id&client_secret=my-secret"),
+		)
+		Expect(body).ToNot(ContainSubstring("my-secret"))
+		Expect(body).To(ContainSubstring("client_id=my-id"))
+		Expect(body).To(ContainSubstring("client_secret=REDACTED"))
+	})
+
+	It("Omits bodies that it can't parse", func() {
+		headers := http.Header{
+			"Content-Type": []string{"text/plain"},
+		}
+		body := redactBody(headers, []byte("my-secret"))
+		Expect(body).To(Equal("<9 bytes of 'text/plain' omitted>"))
+	})
+
+	It("Redacts sensitive headers", func() {
+		headers := redactHeaders(http.Header{
+			"Authorization": []string{"Bearer my-token"},
+			"Accept":        []string{"application/json"},
+		})
+		Expect(headers.Get("Authorization")).To(Equal("REDACTED"))
+		Expect(headers.Get("Accept")).To(Equal("application/json"))
+	})
+
+	It("Doesn't let the SDK dump the HTTP traffic", func() {
+		logger := &TFLogLogger{}
+		Expect(logger.DebugEnabled()).To(BeFalse())
+	})
+
+	It("Writes redacted HTTP exchanges to the trace", func() {
+		// Prepare the server:
+		server := NewServer()
+		defer server.Close()
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/identity_providers",
+				),
+				RespondWithJSON(http.StatusCreated, `{
+				  "id": "456",
+				  "name": "my-idp"
+				}`),
+			),
+		)
+
+		// Send a request through a connection that uses the wrapper:
+		trace := &bytes.Buffer{}
+		wrapper := &LoggingTransportWrapper{
+			Bodies: true,
+			Trace:  trace,
+		}
+		ctx := context.Background()
+		connection, err := sdk.NewConnectionBuilder().
+			Logger(&TFLogLogger{}).
+			URL(server.URL()).
+			Tokens(MakeTokenString("Bearer", 10*time.Minute)).
+			TransportWrapper(wrapper.Wrap).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer connection.Close()
+		response, err := connection.Post().
+			Path("/api/clusters_mgmt/v1/clusters/123/identity_providers").
+			String(`{"name": "my-idp", "ldap": {"bind_password": "my-password"}}`).
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Status()).To(Equal(http.StatusCreated))
+
+		// Check that the body of the response is still available to the caller:
+		Expect(response.String()).To(MatchJSON(`{
+		  "id": "456",
+		  "name": "my-idp"
+		}`))
+
+		// Check the trace:
+		Expect(trace.String()).ToNot(ContainSubstring("my-password"))
+		lines := strings.Split(strings.TrimSpace(trace.String()), "\n")
+		Expect(lines).To(HaveLen(1))
+		record := &traceRecord{}
+		err = json.Unmarshal([]byte(lines[0]), record)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(record.Method).To(Equal(http.MethodPost))
+		Expect(record.URL).To(HaveSuffix("/api/clusters_mgmt/v1/clusters/123/identity_providers"))
+		Expect(record.Status).To(Equal(http.StatusCreated))
+		Expect(record.RequestHeaders.Get("Authorization")).To(Equal("REDACTED"))
+		Expect(record.RequestBody).To(MatchJSON(`{
+		  "name": "my-idp",
+		  "ldap": {
+		    "bind_password": "REDACTED"
+		  }
+		}`))
+		Expect(record.ResponseBody).To(MatchJSON(`{
+		  "id": "456",
+		  "name": "my-idp"
+		}`))
+	})
+})
@@ -19,12 +19,14 @@ package provider
 import (
 	"context"
 	"fmt"
-	"time"
+	"reflect"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/openshift-online/ocm-sdk-go/logging"
 )
@@ -35,6 +37,7 @@ type MachinePoolResourceType struct {
 
 type MachinePoolResource struct {
 	logger     logging.Logger
+	connection *sdk.Connection
 	collection *cmv1.ClustersClient
 }
 
@@ -88,6 +91,113 @@ func (t *MachinePoolResourceType) GetSchema(ctx context.Context) (result tfsdk.S
 				Type:        types.Int64Type,
 				Optional:    true,
 			},
+			"labels": {
+				Description: "Labels that will be added to the nodes of the " +
+					"machine pool.",
+				Type: types.MapType{
+					ElemType: types.StringType,
+				},
+				Optional: true,
+			},
+			"taints": {
+				Description: "Taints that will be added to the nodes of the " +
+					"machine pool.",
+				Attributes: tfsdk.ListNestedAttributes(
+					map[string]tfsdk.Attribute{
+						"key": {
+							Description: "Key of the taint.",
+							Type:        types.StringType,
+							Required:    true,
+						},
+						"value": {
+							Description: "Value of the taint.",
+							Type:        types.StringType,
+							Required:    true,
+						},
+						"schedule_type": {
+							Description: "Effect of the taint, can be " +
+								"'NoSchedule', 'PreferNoSchedule' " +
+								"or 'NoExecute'.",
+							Type:     types.StringType,
+							Required: true,
+							Validators: []tfsdk.AttributeValidator{
+								EnumValidator(
+									"NoSchedule",
+									"PreferNoSchedule",
+									"NoExecute",
+								),
+							},
+						},
+					},
+					tfsdk.ListNestedAttributesOptions{},
+				),
+				Optional: true,
+			},
+			"use_spot_instances": {
+				Description: "Use Amazon EC2 spot instances for the nodes " +
+					"of the machine pool. Changing this forces the " +
+					"creation of a new machine pool.",
+				Type:     types.BoolType,
+				Optional: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"max_spot_price": {
+				Description: "Maximum hourly price for a spot instance. If " +
+					"not set the on-demand price is used as the maximum. " +
+					"Requires 'use_spot_instances'. Changing this forces " +
+					"the creation of a new machine pool.",
+				Type:     types.Float64Type,
+				Optional: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"availability_zone": {
+				Description: "Availability zone where all the nodes of the " +
+					"machine pool will be placed. By default the nodes " +
+					"are distributed across the availability zones of " +
+					"the cluster. Changing this forces the creation of a " +
+					"new machine pool.",
+				Type:     types.StringType,
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.UseStateForUnknown(),
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"subnet_id": {
+				Description: "Identifier of the subnet where all the nodes " +
+					"of the machine pool will be placed. Changing this " +
+					"forces the creation of a new machine pool.",
+				Type:     types.StringType,
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.UseStateForUnknown(),
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"aws_additional_security_group_ids": {
+				Description: "Identifiers of additional AWS security " +
+					"groups that will be attached to the nodes of the " +
+					"machine pool. Changing this forces the creation of " +
+					"a new machine pool.",
+				Type: types.ListType{
+					ElemType: types.StringType,
+				},
+				Optional: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"timeouts": {
+				Description: "Timeouts of the create, update and delete operations.",
+				Attributes:  timeoutsResource(),
+				Optional:    true,
+			},
 		},
 	}
 	return
@@ -104,6 +214,7 @@ func (t *MachinePoolResourceType) NewResource(ctx context.Context,
 	// Create the resource:
 	result = &MachinePoolResource{
 		logger:     parent.logger,
+		connection: parent.connection,
 		collection: collection,
 	}
 
@@ -120,16 +231,13 @@ func (r *MachinePoolResource) Create(ctx context.Context,
 		return
 	}
 
+	// Apply the create timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, state.Timeouts.CreateTimeout())
+	defer cancel()
+
 	// Wait till the cluster is ready:
 	resource := r.collection.Cluster(state.Cluster.Value)
-	pollCtx, cancel := context.WithTimeout(ctx, 1*time.Hour)
-	defer cancel()
-	_, err := resource.Poll().
-		Interval(30 * time.Second).
-		Predicate(func(get *cmv1.ClusterGetResponse) bool {
-			return get.Body().State() == cmv1.ClusterStateReady
-		}).
-		StartContext(pollCtx)
+	_, err := waitTillClusterReady(ctx, resource, state.Timeouts.Interval())
 	if err != nil {
 		response.Diagnostics.AddError(
 			"Can't poll cluster state",
@@ -164,6 +272,24 @@ func (r *MachinePoolResource) Create(ctx context.Context,
 		computeNodeEnabled = true
 		builder.Replicas(int(state.Replicas.Value))
 	}
+	if !state.Labels.Unknown && !state.Labels.Null {
+		builder.Labels(machinePoolLabels(state.Labels))
+	}
+
+	errMsg = getPlacement(state, builder)
+	if errMsg != "" {
+		response.Diagnostics.AddError(
+			"Can't build machine pool",
+			fmt.Sprintf(
+				"Can't build machine pool for cluster '%s', %s", state.Cluster.Value, errMsg,
+			),
+		)
+		return
+	}
+	if state.Taints != nil {
+		builder.Taints(machinePoolTaints(state.Taints)...)
+	}
+
 	if (!autoscalingEnabled && !computeNodeEnabled) || (autoscalingEnabled && computeNodeEnabled) {
 		response.Diagnostics.AddError(
 			"Can't build machine pool",
@@ -187,8 +313,20 @@ func (r *MachinePoolResource) Create(ctx context.Context,
 		return
 	}
 
-	collection := resource.MachinePools()
-	add, err := collection.Add().Body(object).SendContext(ctx)
+	// The additional security groups aren't supported by the SDK, so when they are requested
+	// the machine pool is sent as raw JSON. An empty list is the same as no list.
+	var securityGroupIDs []string
+	if len(state.SecurityGroupIDs) > 0 {
+		object, securityGroupIDs, err = createMachinePoolWithSecurityGroups(
+			ctx, r.connection, state.Cluster.Value, object, state.SecurityGroupIDs,
+		)
+	} else {
+		var add *cmv1.MachinePoolsAddResponse
+		add, err = resource.MachinePools().Add().Body(object).SendContext(ctx)
+		if err == nil {
+			object = add.Body()
+		}
+	}
 	if err != nil {
 		response.Diagnostics.AddError(
 			"Can't create machine pool",
@@ -199,14 +337,27 @@ func (r *MachinePoolResource) Create(ctx context.Context,
 		)
 		return
 	}
-	object = add.Body()
 
 	// Save the state:
 	r.populateState(object, state)
+	r.populateSecurityGroupIDs(securityGroupIDs, state)
 	diags = response.State.Set(ctx, state)
 	response.Diagnostics.Append(diags...)
 }
 
+// populateSecurityGroupIDs copies the additional security groups returned by the server to the
+// state. The server doesn't return empty lists, so when there are no security groups an empty
+// list in the state is preserved, otherwise it would be replaced by null and the machine pool
+// would be replaced in every plan.
+func (r *MachinePoolResource) populateSecurityGroupIDs(securityGroupIDs []string,
+	state *MachinePoolState) {
+	if len(securityGroupIDs) == 0 && state.SecurityGroupIDs != nil {
+		state.SecurityGroupIDs = []string{}
+		return
+	}
+	state.SecurityGroupIDs = securityGroupIDs
+}
+
 func (r *MachinePoolResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest,
 	response *tfsdk.ReadResourceResponse) {
 	// Get the current state:
@@ -217,11 +368,11 @@ func (r *MachinePoolResource) Read(ctx context.Context, request tfsdk.ReadResour
 		return
 	}
 
-	// Find the machine pool:
-	resource := r.collection.Cluster(state.Cluster.Value).
-		MachinePools().
-		MachinePool(state.ID.Value)
-	get, err := resource.Get().SendContext(ctx)
+	// Find the machine pool. This uses raw JSON because the SDK doesn't support the
+	// additional security groups yet.
+	object, securityGroupIDs, err := getMachinePool(
+		ctx, r.connection, state.Cluster.Value, state.ID.Value,
+	)
 	if err != nil {
 		response.Diagnostics.AddError(
 			"Can't find machine pool",
@@ -233,10 +384,10 @@ func (r *MachinePoolResource) Read(ctx context.Context, request tfsdk.ReadResour
 		)
 		return
 	}
-	object := get.Body()
 
 	// Save the state:
 	r.populateState(object, state)
+	r.populateSecurityGroupIDs(securityGroupIDs, state)
 	diags = response.State.Set(ctx, state)
 	response.Diagnostics.Append(diags...)
 }
@@ -261,6 +412,10 @@ func (r *MachinePoolResource) Update(ctx context.Context, request tfsdk.UpdateRe
 		return
 	}
 
+	// Apply the update timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, plan.Timeouts.UpdateTimeout())
+	defer cancel()
+
 	resource := r.collection.Cluster(state.Cluster.Value).
 		MachinePools().
 		MachinePool(state.ID.Value)
@@ -325,6 +480,15 @@ func (r *MachinePoolResource) Update(ctx context.Context, request tfsdk.UpdateRe
 		return
 	}
 
+	// Labels and taints are only sent when they change. When they are removed from the
+	// configuration we send empty values so that they are also removed from the nodes.
+	if !plan.Labels.Equal(state.Labels) {
+		mpBuilder.Labels(machinePoolLabels(plan.Labels))
+	}
+	if !reflect.DeepEqual(plan.Taints, state.Taints) {
+		mpBuilder.Taints(machinePoolTaints(plan.Taints)...)
+	}
+
 	machinePool, err := mpBuilder.Build()
 	if err != nil {
 		response.Diagnostics.AddError(
@@ -355,6 +519,9 @@ func (r *MachinePoolResource) Update(ctx context.Context, request tfsdk.UpdateRe
 	state.AutoScalingEnabled = plan.AutoScalingEnabled
 	// update the Replicas with the plan value (important for nil and zero value cases)
 	state.Replicas = plan.Replicas
+	state.Labels = plan.Labels
+	state.Taints = plan.Taints
+	state.Timeouts = plan.Timeouts
 
 	// Save the state:
 	r.populateState(object, state)
@@ -391,6 +558,32 @@ func getAutoscaling(state *MachinePoolState, mpBuilder *cmv1.MachinePoolBuilder)
 	return autoscalingEnabled, ""
 }
 
+// getPlacement adds to the builder the spot instance and placement options, which can only be
+// set when the machine pool is created.
+func getPlacement(state *MachinePoolState, mpBuilder *cmv1.MachinePoolBuilder) (errMsg string) {
+	useSpotInstances := !state.UseSpotInstances.Unknown && !state.UseSpotInstances.Null &&
+		state.UseSpotInstances.Value
+	if useSpotInstances {
+		spotMarketOptions := cmv1.NewAWSSpotMarketOptions()
+		if !state.MaxSpotPrice.Unknown && !state.MaxSpotPrice.Null {
+			if state.MaxSpotPrice.Value <= 0 {
+				return "max_spot_price should be greater than zero"
+			}
+			spotMarketOptions.MaxPrice(state.MaxSpotPrice.Value)
+		}
+		mpBuilder.AWS(cmv1.NewAWSMachinePool().SpotMarketOptions(spotMarketOptions))
+	} else if !state.MaxSpotPrice.Unknown && !state.MaxSpotPrice.Null {
+		return "max_spot_price can only be set when use_spot_instances is enabled"
+	}
+	if !state.AvailabilityZone.Unknown && !state.AvailabilityZone.Null {
+		mpBuilder.AvailabilityZones(state.AvailabilityZone.Value)
+	}
+	if !state.SubnetID.Unknown && !state.SubnetID.Null {
+		mpBuilder.Subnets(state.SubnetID.Value)
+	}
+	return ""
+}
+
 func (r *MachinePoolResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest,
 	response *tfsdk.DeleteResourceResponse) {
 	// Get the state:
@@ -401,6 +594,10 @@ func (r *MachinePoolResource) Delete(ctx context.Context, request tfsdk.DeleteRe
 		return
 	}
 
+	// Apply the delete timeout to the rest of the operation:
+	ctx, cancel := context.WithTimeout(ctx, state.Timeouts.DeleteTimeout())
+	defer cancel()
+
 	// Send the request to delete the machine pool:
 	resource := r.collection.Cluster(state.Cluster.Value).
 		MachinePools().
@@ -424,12 +621,25 @@ func (r *MachinePoolResource) Delete(ctx context.Context, request tfsdk.DeleteRe
 
 func (r *MachinePoolResource) ImportState(ctx context.Context, request tfsdk.ImportResourceStateRequest,
 	response *tfsdk.ImportResourceStateResponse) {
-	tfsdk.ResourceImportStatePassthroughID(
+	// The identifier of the machine pool is only unique within the cluster, so the import
+	// identifier must contain both:
+	parts, err := parseImportID(request.ID, "cluster_id", "machine_pool_id")
+	if err != nil {
+		response.Diagnostics.AddError("Invalid import identifier", err.Error())
+		return
+	}
+	diags := response.State.SetAttribute(
+		ctx,
+		tftypes.NewAttributePath().WithAttributeName("cluster"),
+		parts[0],
+	)
+	response.Diagnostics.Append(diags...)
+	diags = response.State.SetAttribute(
 		ctx,
 		tftypes.NewAttributePath().WithAttributeName("id"),
-		request,
-		response,
+		parts[1],
 	)
+	response.Diagnostics.Append(diags...)
 }
 
 // populateState copies the data from the API object to the Terraform state.
@@ -476,4 +686,113 @@ func (r *MachinePoolResource) populateState(object *cmv1.MachinePool, state *Mac
 		}
 	}
 
+	// Spot instances and placement can't be changed after creation, but they are copied
+	// anyhow so that changes made outside of Terraform force the replacement:
+	spotMarketOptions, ok := object.AWS().GetSpotMarketOptions()
+	if ok {
+		state.UseSpotInstances = types.Bool{
+			Value: true,
+		}
+		maxPrice, ok := spotMarketOptions.GetMaxPrice()
+		if ok {
+			state.MaxSpotPrice = types.Float64{
+				Value: maxPrice,
+			}
+		} else {
+			state.MaxSpotPrice = types.Float64{
+				Null: true,
+			}
+		}
+	} else {
+		if !state.UseSpotInstances.Unknown && !state.UseSpotInstances.Null {
+			state.UseSpotInstances = types.Bool{
+				Value: false,
+			}
+		}
+		state.MaxSpotPrice = types.Float64{
+			Null: true,
+		}
+	}
+	availabilityZones := object.AvailabilityZones()
+	if len(availabilityZones) == 1 {
+		state.AvailabilityZone = types.String{
+			Value: availabilityZones[0],
+		}
+	} else {
+		state.AvailabilityZone = types.String{
+			Null: true,
+		}
+	}
+	subnets := object.Subnets()
+	if len(subnets) == 1 {
+		state.SubnetID = types.String{
+			Value: subnets[0],
+		}
+	} else {
+		state.SubnetID = types.String{
+			Null: true,
+		}
+	}
+
+	// Labels and taints are left null when the server doesn't return them and they aren't in
+	// the state, otherwise they are always copied so that changes made outside of Terraform
+	// are detected:
+	labels := object.Labels()
+	if len(labels) > 0 || (!state.Labels.Unknown && !state.Labels.Null) {
+		state.Labels = types.Map{
+			ElemType: types.StringType,
+			Elems:    map[string]attr.Value{},
+		}
+		for k, v := range labels {
+			state.Labels.Elems[k] = types.String{
+				Value: v,
+			}
+		}
+	} else {
+		state.Labels = types.Map{
+			ElemType: types.StringType,
+			Null:     true,
+		}
+	}
+	taints := object.Taints()
+	if len(taints) > 0 || state.Taints != nil {
+		state.Taints = make([]Taint, len(taints))
+		for i, taint := range taints {
+			state.Taints[i] = Taint{
+				Key: types.String{
+					Value: taint.Key(),
+				},
+				Value: types.String{
+					Value: taint.Value(),
+				},
+				ScheduleType: types.String{
+					Value: taint.Effect(),
+				},
+			}
+		}
+	}
+}
+
+// machinePoolLabels converts the Terraform map of labels into a Go map.
+func machinePoolLabels(value types.Map) map[string]string {
+	result := map[string]string{}
+	if value.Unknown || value.Null {
+		return result
+	}
+	for k, v := range value.Elems {
+		result[k] = v.(types.String).Value
+	}
+	return result
+}
+
+// machinePoolTaints converts the Terraform list of taints into the corresponding builders.
+func machinePoolTaints(taints []Taint) []*cmv1.TaintBuilder {
+	result := make([]*cmv1.TaintBuilder, len(taints))
+	for i, taint := range taints {
+		result[i] = cmv1.NewTaint().
+			Key(taint.Key.Value).
+			Value(taint.Value.Value).
+			Effect(taint.ScheduleType.Value)
+	}
+	return result
 }
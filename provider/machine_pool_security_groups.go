@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// The version of the SDK used by the provider doesn't support the additional security groups of
+// machine pools yet, so the following functions send and receive machine pools as raw JSON.
+
+// machinePoolJSON is the part of the JSON representation of machine pools that the machine pool
+// type of the SDK doesn't support.
+type machinePoolJSON struct {
+	AWS *struct {
+		AdditionalSecurityGroupIDs []string `json:"additional_security_group_ids,omitempty"`
+	} `json:"aws,omitempty"`
+}
+
+// createMachinePoolWithSecurityGroups creates the given machine pool adding the given security
+// groups to the nodes. It returns the created machine pool and the security groups returned by
+// the server.
+func createMachinePoolWithSecurityGroups(ctx context.Context, connection *sdk.Connection,
+	clusterID string, pool *cmv1.MachinePool, securityGroupIDs []string) (result *cmv1.MachinePool,
+	resultIDs []string, err error) {
+	buffer := &bytes.Buffer{}
+	err = cmv1.MarshalMachinePool(pool, buffer)
+	if err != nil {
+		return
+	}
+	body := map[string]interface{}{}
+	err = json.Unmarshal(buffer.Bytes(), &body)
+	if err != nil {
+		return
+	}
+	aws, _ := body["aws"].(map[string]interface{})
+	if aws == nil {
+		aws = map[string]interface{}{}
+		body["aws"] = aws
+	}
+	aws["additional_security_group_ids"] = securityGroupIDs
+	data, err := json.Marshal(body)
+	if err != nil {
+		return
+	}
+	response, err := sendRawRequest(
+		ctx,
+		connection.Post().Path(machinePoolsPath(clusterID)).Bytes(data),
+	)
+	if err != nil {
+		return
+	}
+	return unmarshalMachinePool(response.Bytes())
+}
+
+// getMachinePool returns the machine pool with the given identifier and its additional security
+// groups.
+func getMachinePool(ctx context.Context, connection *sdk.Connection, clusterID,
+	id string) (result *cmv1.MachinePool, securityGroupIDs []string, err error) {
+	response, err := sendRawRequest(
+		ctx,
+		connection.Get().Path(machinePoolsPath(clusterID)+"/"+id),
+	)
+	if err != nil {
+		return
+	}
+	return unmarshalMachinePool(response.Bytes())
+}
+
+// unmarshalMachinePool converts the JSON representation of a machine pool into the machine pool
+// type of the SDK and the list of additional security groups.
+func unmarshalMachinePool(data []byte) (result *cmv1.MachinePool, securityGroupIDs []string,
+	err error) {
+	result, err = cmv1.UnmarshalMachinePool(data)
+	if err != nil {
+		return
+	}
+	extra := &machinePoolJSON{}
+	err = json.Unmarshal(data, extra)
+	if err != nil {
+		return
+	}
+	if extra.AWS != nil {
+		securityGroupIDs = extra.AWS.AdditionalSecurityGroupIDs
+	}
+	return
+}
+
+func machinePoolsPath(clusterID string) string {
+	return clustersPath + "/" + clusterID + "/machine_pools"
+}
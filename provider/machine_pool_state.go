@@ -21,12 +21,26 @@ import (
 )
 
 type MachinePoolState struct {
-	Cluster            types.String `tfsdk:"cluster"`
-	ID                 types.String `tfsdk:"id"`
-	MachineType        types.String `tfsdk:"machine_type"`
-	Name               types.String `tfsdk:"name"`
-	Replicas           types.Int64  `tfsdk:"replicas"`
-	AutoScalingEnabled types.Bool   `tfsdk:"autoscaling_enabled"`
-	MinReplicas        types.Int64  `tfsdk:"min_replicas"`
-	MaxReplicas        types.Int64  `tfsdk:"max_replicas"`
+	Cluster            types.String  `tfsdk:"cluster"`
+	ID                 types.String  `tfsdk:"id"`
+	MachineType        types.String  `tfsdk:"machine_type"`
+	Name               types.String  `tfsdk:"name"`
+	Replicas           types.Int64   `tfsdk:"replicas"`
+	AutoScalingEnabled types.Bool    `tfsdk:"autoscaling_enabled"`
+	MinReplicas        types.Int64   `tfsdk:"min_replicas"`
+	MaxReplicas        types.Int64   `tfsdk:"max_replicas"`
+	Labels             types.Map     `tfsdk:"labels"`
+	Taints             []Taint       `tfsdk:"taints"`
+	UseSpotInstances   types.Bool    `tfsdk:"use_spot_instances"`
+	MaxSpotPrice       types.Float64 `tfsdk:"max_spot_price"`
+	AvailabilityZone   types.String  `tfsdk:"availability_zone"`
+	SubnetID           types.String  `tfsdk:"subnet_id"`
+	SecurityGroupIDs   []string      `tfsdk:"aws_additional_security_group_ids"`
+	Timeouts           *Timeouts     `tfsdk:"timeouts"`
+}
+
+type Taint struct {
+	Key          types.String `tfsdk:"key"`
+	Value        types.String `tfsdk:"value"`
+	ScheduleType types.String `tfsdk:"schedule_type"`
 }
@@ -0,0 +1,92 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type notEmptyListValidator struct {
+}
+
+// NotEmptyListValidator returns an attribute validator that checks that a list attribute, when
+// it is set, contains at least one element.
+func NotEmptyListValidator() tfsdk.AttributeValidator {
+	return notEmptyListValidator{}
+}
+
+func (v notEmptyListValidator) Description(ctx context.Context) string {
+	return "The list must contain at least one element."
+}
+
+func (v notEmptyListValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v notEmptyListValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest,
+	resp *tfsdk.ValidateAttributeResponse) {
+	value, ok := req.AttributeConfig.(types.List)
+	if !ok || value.Unknown || value.Null {
+		return
+	}
+	if len(value.Elems) == 0 {
+		resp.Diagnostics.AddAttributeError(
+			req.AttributePath,
+			"Empty list",
+			"The list must contain at least one element",
+		)
+	}
+}
+
+type notEmptyObjectValidator struct {
+}
+
+// NotEmptyObjectValidator returns an attribute validator that checks that an object attribute,
+// when it is set, has at least one attribute that isn't null.
+func NotEmptyObjectValidator() tfsdk.AttributeValidator {
+	return notEmptyObjectValidator{}
+}
+
+func (v notEmptyObjectValidator) Description(ctx context.Context) string {
+	return "At least one of the attributes must be set."
+}
+
+func (v notEmptyObjectValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v notEmptyObjectValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest,
+	resp *tfsdk.ValidateAttributeResponse) {
+	value, ok := req.AttributeConfig.(types.Object)
+	if !ok || value.Unknown || value.Null {
+		return
+	}
+	for _, attr := range value.Attrs {
+		raw, err := attr.ToTerraformValue(ctx)
+		if err != nil || raw != nil {
+			return
+		}
+	}
+	resp.Diagnostics.AddAttributeError(
+		req.AttributePath,
+		"Missing attributes",
+		"At least one of the attributes must be set",
+	)
+}
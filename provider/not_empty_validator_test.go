@@ -0,0 +1,86 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	. "github.com/onsi/ginkgo/v2/dsl/core"  // nolint
+	. "github.com/onsi/ginkgo/v2/dsl/table" // nolint
+	. "github.com/onsi/gomega"              // nolint
+)
+
+var _ = Describe("Not empty validators", func() {
+	validate := func(validator tfsdk.AttributeValidator, value attr.Value) bool {
+		request := tfsdk.ValidateAttributeRequest{
+			AttributePath:   tftypes.NewAttributePath().WithAttributeName("claims"),
+			AttributeConfig: value,
+		}
+		response := &tfsdk.ValidateAttributeResponse{}
+		validator.Validate(context.Background(), request, response)
+		return !response.Diagnostics.HasError()
+	}
+	list := func(values ...string) types.List {
+		result := types.List{
+			ElemType: types.StringType,
+			Elems:    []attr.Value{},
+		}
+		for _, value := range values {
+			result.Elems = append(result.Elems, types.String{Value: value})
+		}
+		return result
+	}
+	null := types.List{
+		ElemType: types.StringType,
+		Null:     true,
+	}
+	object := func(email, name types.List) types.Object {
+		return types.Object{
+			AttrTypes: map[string]attr.Type{
+				"email": types.ListType{ElemType: types.StringType},
+				"name":  types.ListType{ElemType: types.StringType},
+			},
+			Attrs: map[string]attr.Value{
+				"email": email,
+				"name":  name,
+			},
+		}
+	}
+
+	DescribeTable("Validates lists",
+		func(value types.List, valid bool) {
+			Expect(validate(NotEmptyListValidator(), value)).To(Equal(valid))
+		},
+		Entry("One element", list("email"), true),
+		Entry("Two elements", list("name", "email"), true),
+		Entry("Null", null, true),
+		Entry("Empty", list(), false),
+	)
+
+	DescribeTable("Validates objects",
+		func(value types.Object, valid bool) {
+			Expect(validate(NotEmptyObjectValidator(), value)).To(Equal(valid))
+		},
+		Entry("One attribute", object(list("email"), null), true),
+		Entry("Two attributes", object(list("email"), list("name")), true),
+		Entry("No attributes", object(null, null), false),
+	)
+})
@@ -0,0 +1,138 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+)
+
+// OCMConfig is the subset of the configuration file of the `ocm` command line tool that the
+// provider uses as a fallback when the credentials aren't explicitly configured.
+type OCMConfig struct {
+	URL          string   `json:"url,omitempty"`
+	TokenURL     string   `json:"token_url,omitempty"`
+	ClientID     string   `json:"client_id,omitempty"`
+	ClientSecret string   `json:"client_secret,omitempty"`
+	User         string   `json:"user,omitempty"`
+	Password     string   `json:"password,omitempty"`
+	AccessToken  string   `json:"access_token,omitempty"`
+	RefreshToken string   `json:"refresh_token,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+	Insecure     bool     `json:"insecure,omitempty"`
+}
+
+// ocmConfigLocation returns the location of the configuration file of the `ocm` command line
+// tool. That is the value of the `OCM_CONFIG` environment variable if it is set, or else the
+// `ocm/ocm.json` file inside the user configuration directory, usually `~/.config`.
+func ocmConfigLocation() (result string, err error) {
+	result, ok := os.LookupEnv("OCM_CONFIG")
+	if ok && result != "" {
+		return
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return
+	}
+	result = filepath.Join(dir, "ocm", "ocm.json")
+	return
+}
+
+// loadOCMConfig loads the configuration file of the `ocm` command line tool. It returns nil if
+// the file doesn't exist.
+func loadOCMConfig() (config *OCMConfig, path string, err error) {
+	path, err = ocmConfigLocation()
+	if err != nil {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = nil
+		return
+	}
+	if err != nil {
+		return
+	}
+	config = &OCMConfig{}
+	err = json.Unmarshal(data, config)
+	if err != nil {
+		config = nil
+		err = fmt.Errorf("can't parse '%s': %v", path, err)
+		return
+	}
+	return
+}
+
+// HasCredentials checks if the configuration contains tokens or credentials that can be used
+// to request them. The file still exists after `ocm logout`, but without credentials.
+func (c *OCMConfig) HasCredentials() bool {
+	return c.AccessToken != "" || c.RefreshToken != "" ||
+		(c.ClientID != "" && c.ClientSecret != "") ||
+		(c.User != "" && c.Password != "")
+}
+
+// MatchesURL checks if the credentials of the configuration can be used with the given API URL,
+// which is the case when the URL is empty or when it is the same URL that the configuration
+// was created for. The `ocm` tool uses the production environment when the URL is empty.
+func (c *OCMConfig) MatchesURL(url string) bool {
+	if url == "" {
+		return true
+	}
+	configURL := c.URL
+	if configURL == "" {
+		configURL = sdk.DefaultURL
+	}
+	return strings.TrimRight(configURL, "/") == strings.TrimRight(url, "/")
+}
+
+// Apply copies the URLs and the credentials of the configuration to the given connection
+// builder. The URLs are only copied when the corresponding flags are true, so that explicitly
+// configured values aren't replaced.
+func (c *OCMConfig) Apply(builder *sdk.ConnectionBuilder, url, tokenURL bool) {
+	if url && c.URL != "" {
+		builder.URL(c.URL)
+	}
+	if tokenURL && c.TokenURL != "" {
+		builder.TokenURL(c.TokenURL)
+	}
+	if c.ClientID != "" || c.ClientSecret != "" {
+		builder.Client(c.ClientID, c.ClientSecret)
+	}
+	if c.User != "" && c.Password != "" {
+		builder.User(c.User, c.Password)
+	}
+	tokens := []string{}
+	if c.AccessToken != "" {
+		tokens = append(tokens, c.AccessToken)
+	}
+	if c.RefreshToken != "" {
+		tokens = append(tokens, c.RefreshToken)
+	}
+	if len(tokens) > 0 {
+		builder.Tokens(tokens...)
+	}
+	if len(c.Scopes) > 0 {
+		builder.Scopes(c.Scopes...)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core" // nolint
+	. "github.com/onsi/gomega"             // nolint
+)
+
+var _ = Describe("ocm configuration file", func() {
+	var (
+		dir      string
+		previous string
+		present  bool
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "ocm-config-*")
+		Expect(err).ToNot(HaveOccurred())
+		previous, present = os.LookupEnv("OCM_CONFIG")
+	})
+
+	AfterEach(func() {
+		if present {
+			os.Setenv("OCM_CONFIG", previous)
+		} else {
+			os.Unsetenv("OCM_CONFIG")
+		}
+		os.RemoveAll(dir)
+	})
+
+	It("Uses the location from the environment", func() {
+		path := filepath.Join(dir, "my.json")
+		os.Setenv("OCM_CONFIG", path)
+		location, err := ocmConfigLocation()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(location).To(Equal(path))
+	})
+
+	It("Loads the URL, tokens and flags", func() {
+		path := filepath.Join(dir, "ocm.json")
+		err := os.WriteFile(path, []byte(`{
+		  "access_token": "my-access",
+		  "client_id": "cloud-services",
+		  "insecure": true,
+		  "refresh_token": "my-refresh",
+		  "scopes": ["openid"],
+		  "token_url": "https://sso.example.com/token",
+		  "url": "https://api.example.com"
+		}`), 0600)
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv("OCM_CONFIG", path)
+		config, location, err := loadOCMConfig()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(location).To(Equal(path))
+		Expect(config).ToNot(BeNil())
+		Expect(config.URL).To(Equal("https://api.example.com"))
+		Expect(config.TokenURL).To(Equal("https://sso.example.com/token"))
+		Expect(config.ClientID).To(Equal("cloud-services"))
+		Expect(config.AccessToken).To(Equal("my-access"))
+		Expect(config.RefreshToken).To(Equal("my-refresh"))
+		Expect(config.Scopes).To(ConsistOf("openid"))
+		Expect(config.Insecure).To(BeTrue())
+		Expect(config.HasCredentials()).To(BeTrue())
+	})
+
+	It("Ignores a file without credentials", func() {
+		path := filepath.Join(dir, "ocm.json")
+		err := os.WriteFile(path, []byte(`{
+		  "client_id": "cloud-services",
+		  "url": "https://api.example.com"
+		}`), 0600)
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv("OCM_CONFIG", path)
+		config, _, err := loadOCMConfig()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.HasCredentials()).To(BeFalse())
+	})
+
+	It("Matches the URL of the configuration", func() {
+		config := &OCMConfig{
+			URL: "https://api.stage.openshift.com/",
+		}
+		Expect(config.MatchesURL("")).To(BeTrue())
+		Expect(config.MatchesURL("https://api.stage.openshift.com")).To(BeTrue())
+		Expect(config.MatchesURL("https://api.openshift.com")).To(BeFalse())
+	})
+
+	It("Uses the production URL when the configuration doesn't have one", func() {
+		config := &OCMConfig{}
+		Expect(config.MatchesURL("https://api.openshift.com")).To(BeTrue())
+		Expect(config.MatchesURL("https://api.stage.openshift.com")).To(BeFalse())
+	})
+
+	It("Returns nil if the file doesn't exist", func() {
+		os.Setenv("OCM_CONFIG", filepath.Join(dir, "missing.json"))
+		config, _, err := loadOCMConfig()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config).To(BeNil())
+	})
+
+	It("Fails if the file isn't valid JSON", func() {
+		path := filepath.Join(dir, "ocm.json")
+		err := os.WriteFile(path, []byte(`{`), 0600)
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv("OCM_CONFIG", path)
+		_, _, err = loadOCMConfig()
+		Expect(err).To(MatchError(ContainSubstring("can't parse")))
+	})
+})
@@ -0,0 +1,183 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/errors"
+)
+
+// The version of the SDK used by the provider doesn't support OIDC configurations yet, so the
+// following types and functions send and receive them as raw JSON.
+const (
+	clustersPath    = "/api/clusters_mgmt/v1/clusters"
+	oidcConfigsPath = "/api/clusters_mgmt/v1/oidc_configs"
+)
+
+// OIDCConfig is the representation of the OIDC configurations of the clusters management API.
+type OIDCConfig struct {
+	ID               string `json:"id,omitempty"`
+	IssuerURL        string `json:"issuer_url,omitempty"`
+	SecretARN        string `json:"secret_arn,omitempty"`
+	InstallerRoleARN string `json:"installer_role_arn,omitempty"`
+	Managed          bool   `json:"managed"`
+	Reusable         bool   `json:"reusable"`
+}
+
+// createOIDCConfig creates the given OIDC configuration and returns the result, including the
+// identifier and the issuer URL assigned by the server.
+func createOIDCConfig(ctx context.Context, connection *sdk.Connection,
+	config *OIDCConfig) (result *OIDCConfig, err error) {
+	body, err := json.Marshal(config)
+	if err != nil {
+		return
+	}
+	response, err := sendRawRequest(ctx, connection.Post().Path(oidcConfigsPath).Bytes(body))
+	if err != nil {
+		return
+	}
+	result = &OIDCConfig{}
+	err = json.Unmarshal(response.Bytes(), result)
+	return
+}
+
+// getOIDCConfig returns the OIDC configuration with the given identifier, or nil if it doesn't
+// exist.
+func getOIDCConfig(ctx context.Context, connection *sdk.Connection,
+	id string) (result *OIDCConfig, err error) {
+	response, err := sendRawRequest(ctx, connection.Get().Path(oidcConfigsPath+"/"+id))
+	if err != nil {
+		if isNotFound(err) {
+			err = nil
+		}
+		return
+	}
+	result = &OIDCConfig{}
+	err = json.Unmarshal(response.Bytes(), result)
+	return
+}
+
+// deleteOIDCConfig deletes the OIDC configuration with the given identifier. It doesn't fail if
+// the configuration doesn't exist.
+func deleteOIDCConfig(ctx context.Context, connection *sdk.Connection, id string) error {
+	_, err := sendRawRequest(ctx, connection.Delete().Path(oidcConfigsPath+"/"+id))
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// createClusterWithOIDCConfig creates the given cluster using the OIDC configuration with the
+// given identifier. The cluster type of the SDK doesn't have the corresponding field, so it is
+// added to the JSON representation before sending it.
+func createClusterWithOIDCConfig(ctx context.Context, connection *sdk.Connection,
+	cluster *cmv1.Cluster, oidcConfigID string) (result *cmv1.Cluster, err error) {
+	buffer := &bytes.Buffer{}
+	err = cmv1.MarshalCluster(cluster, buffer)
+	if err != nil {
+		return
+	}
+	body := map[string]interface{}{}
+	err = json.Unmarshal(buffer.Bytes(), &body)
+	if err != nil {
+		return
+	}
+	aws, _ := body["aws"].(map[string]interface{})
+	if aws == nil {
+		aws = map[string]interface{}{}
+		body["aws"] = aws
+	}
+	sts, _ := aws["sts"].(map[string]interface{})
+	if sts == nil {
+		sts = map[string]interface{}{}
+		aws["sts"] = sts
+	}
+	sts["oidc_config"] = map[string]interface{}{
+		"id": oidcConfigID,
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return
+	}
+	response, err := sendRawRequest(ctx, connection.Post().Path(clustersPath).Bytes(data))
+	if err != nil {
+		return
+	}
+	result, err = cmv1.UnmarshalCluster(response.Bytes())
+	return
+}
+
+// getClusterWithOIDCConfig returns the cluster with the given identifier and the identifier of
+// the OIDC configuration that it uses, which is empty if it doesn't use any. The cluster type of
+// the SDK doesn't have the corresponding field, so it is extracted from the JSON representation.
+func getClusterWithOIDCConfig(ctx context.Context, connection *sdk.Connection,
+	id string) (result *cmv1.Cluster, oidcConfigID string, err error) {
+	response, err := sendRawRequest(ctx, connection.Get().Path(clustersPath+"/"+id))
+	if err != nil {
+		return
+	}
+	result, err = cmv1.UnmarshalCluster(response.Bytes())
+	if err != nil {
+		return
+	}
+	var body struct {
+		AWS struct {
+			STS struct {
+				OIDCConfig struct {
+					ID string `json:"id"`
+				} `json:"oidc_config"`
+			} `json:"sts"`
+		} `json:"aws"`
+	}
+	err = json.Unmarshal(response.Bytes(), &body)
+	if err != nil {
+		return
+	}
+	oidcConfigID = body.AWS.STS.OIDCConfig.ID
+	return
+}
+
+// sendRawRequest sends the given request and converts error responses into errors of the same
+// type that the SDK returns.
+func sendRawRequest(ctx context.Context, request *sdk.Request) (result *sdk.Response, err error) {
+	response, err := request.Header("Content-Type", "application/json").SendContext(ctx)
+	if err != nil {
+		return
+	}
+	if response.Status() >= http.StatusBadRequest {
+		var apiErr *errors.Error
+		apiErr, err = errors.UnmarshalErrorStatus(response.Bytes(), response.Status())
+		if err != nil {
+			return
+		}
+		err = apiErr
+		return
+	}
+	result = response
+	return
+}
+
+func isNotFound(err error) bool {
+	apiErr, ok := err.(*errors.Error)
+	return ok && apiErr.Status() == http.StatusNotFound
+}
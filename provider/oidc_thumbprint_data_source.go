@@ -0,0 +1,158 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+type OIDCThumbprintDataSourceType struct {
+}
+
+type OIDCThumbprintDataSource struct {
+	logger     logging.Logger
+	trustedCAs string
+}
+
+func (t *OIDCThumbprintDataSourceType) GetSchema(ctx context.Context) (result tfsdk.Schema,
+	diags diag.Diagnostics) {
+	result = tfsdk.Schema{
+		Description: "Thumbprint of the certificate authority of an OIDC endpoint, " +
+			"as needed to create the AWS OIDC identity provider.",
+		Attributes: map[string]tfsdk.Attribute{
+			"oidc_endpoint_url": {
+				Description: "URL of the OIDC endpoint, for example " +
+					"'https://rh-oidc.s3.us-east-1.amazonaws.com/1234'. The " +
+					"'https://' prefix is optional.",
+				Type:     types.StringType,
+				Required: true,
+			},
+			"proxy": {
+				Description: "URL of the proxy used to connect to the OIDC " +
+					"endpoint. If this isn't explicitly specified then the " +
+					"proxy is taken from the 'HTTPS_PROXY' and 'NO_PROXY' " +
+					"environment variables.",
+				Type:     types.StringType,
+				Optional: true,
+				Validators: []tfsdk.AttributeValidator{
+					URLValidator("http", "https"),
+				},
+			},
+			"timeout": {
+				Description: "Maximum time to wait for each attempt to connect " +
+					"to the OIDC endpoint, for example '30s'. Default value " +
+					"is '10s'.",
+				Type:     types.StringType,
+				Optional: true,
+				Validators: []tfsdk.AttributeValidator{
+					DurationValidator(),
+				},
+			},
+			"thumbprint": {
+				Description: "SHA1 thumbprint of the certificate authority of " +
+					"the OIDC endpoint.",
+				Type:     types.StringType,
+				Computed: true,
+			},
+		},
+	}
+	return
+}
+
+func (t *OIDCThumbprintDataSourceType) NewDataSource(ctx context.Context,
+	p tfsdk.Provider) (result tfsdk.DataSource, diags diag.Diagnostics) {
+	// Cast the provider interface to the specific implementation:
+	parent := p.(*Provider)
+
+	// Create the data source:
+	result = &OIDCThumbprintDataSource{
+		logger:     parent.logger,
+		trustedCAs: parent.trustedCAs,
+	}
+	return
+}
+
+func (s *OIDCThumbprintDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest,
+	response *tfsdk.ReadDataSourceResponse) {
+	// Get the state:
+	state := &OIDCThumbprintState{}
+	diags := request.Config.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Prepare the HTTP client:
+	httpClient := DefaultHttpClient{
+		Timeout:    defaultThumbprintTimeout,
+		TrustedCAs: s.trustedCAs,
+	}
+	if !state.Timeout.Unknown && !state.Timeout.Null {
+		timeout, err := time.ParseDuration(state.Timeout.Value)
+		if err != nil {
+			response.Diagnostics.AddAttributeError(
+				tftypes.NewAttributePath().WithAttributeName("timeout"),
+				"Invalid timeout",
+				fmt.Sprintf(
+					"Can't parse timeout '%s': %v",
+					state.Timeout.Value, err,
+				),
+			)
+			return
+		}
+		httpClient.Timeout = timeout
+	}
+	if !state.Proxy.Unknown && !state.Proxy.Null {
+		httpClient.Proxy = state.Proxy.Value
+	}
+
+	// The state of the ROSA cluster contains the OIDC endpoint URL without the scheme, so we
+	// accept that as well:
+	oidcEndpointURL := state.OIDCEndpointURL.Value
+	if !strings.Contains(oidcEndpointURL, "://") {
+		oidcEndpointURL = "https://" + oidcEndpointURL
+	}
+
+	// Get the thumbprint:
+	thumbprint, err := getThumbprint(ctx, oidcEndpointURL, httpClient)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't get OIDC thumbprint",
+			fmt.Sprintf(
+				"Can't get the thumbprint of OIDC endpoint '%s': %v",
+				state.OIDCEndpointURL.Value, err,
+			),
+		)
+		return
+	}
+	state.Thumbprint = types.String{
+		Value: thumbprint,
+	}
+
+	// Save the state:
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"unicode"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// minPasswordLength is the minimum length of the passwords of the users of 'htpasswd' identity
+// providers, as required by the server.
+const minPasswordLength = 14
+
+type passwordValidator struct {
+}
+
+// PasswordValidator returns an attribute validator that checks that the value of a string
+// attribute satisfies the complexity rules that the server applies to the passwords of the users
+// of 'htpasswd' identity providers: at least 14 ASCII characters without spaces, including upper
+// case letters, lower case letters and digits or symbols.
+func PasswordValidator() tfsdk.AttributeValidator {
+	return passwordValidator{}
+}
+
+func (v passwordValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf(
+		"The value must contain at least %d ASCII characters without spaces, "+
+			"including upper case letters, lower case letters and digits or symbols.",
+		minPasswordLength,
+	)
+}
+
+func (v passwordValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v passwordValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest,
+	resp *tfsdk.ValidateAttributeResponse) {
+	value, ok := req.AttributeConfig.(types.String)
+	if !ok || value.Unknown || value.Null {
+		return
+	}
+	problem := checkPassword(value.Value)
+	if problem != "" {
+		// Note that the value of the password is intentionally not part of the message.
+		resp.Diagnostics.AddAttributeError(
+			req.AttributePath,
+			"Invalid password",
+			fmt.Sprintf("Password %s", problem),
+		)
+	}
+}
+
+// checkPassword checks the complexity of the given password. It returns an empty string if the
+// password is acceptable, or a description of the problem otherwise.
+func checkPassword(password string) string {
+	if len(password) < minPasswordLength {
+		return fmt.Sprintf("must be at least %d characters long", minPasswordLength)
+	}
+	var upper, lower, other bool
+	for _, char := range password {
+		switch {
+		case char > unicode.MaxASCII:
+			return "must contain only ASCII characters"
+		case unicode.IsSpace(char):
+			return "must not contain spaces"
+		case unicode.IsUpper(char):
+			upper = true
+		case unicode.IsLower(char):
+			lower = true
+		default:
+			other = true
+		}
+	}
+	switch {
+	case !upper:
+		return "must contain at least one upper case letter"
+	case !lower:
+		return "must contain at least one lower case letter"
+	case !other:
+		return "must contain at least one digit or symbol"
+	}
+	return ""
+}
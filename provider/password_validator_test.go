@@ -0,0 +1,40 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	. "github.com/onsi/ginkgo/v2/dsl/table" // nolint
+	. "github.com/onsi/gomega"              // nolint
+)
+
+var _ = DescribeTable("Checks password complexity",
+	func(password string, expected string) {
+		if expected == "" {
+			Expect(checkPassword(password)).To(BeEmpty())
+		} else {
+			Expect(checkPassword(password)).To(ContainSubstring(expected))
+		}
+	},
+	Entry("Valid with digits", "MyPassword1234", ""),
+	Entry("Valid with symbols", "MyPassword-Is-Long", ""),
+	Entry("Too short", "MyPass123", "at least 14"),
+	Entry("Spaces", "My Password 1234", "spaces"),
+	Entry("Not ASCII", "MyPässword1234", "ASCII"),
+	Entry("No upper case", "mypassword1234", "upper case"),
+	Entry("No lower case", "MYPASSWORD1234", "lower case"),
+	Entry("No digits or symbols", "MyPasswordIsLong", "digit or symbol"),
+)
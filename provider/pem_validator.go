@@ -0,0 +1,98 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type pemValidator struct {
+}
+
+// PEMValidator returns an attribute validator that checks that the value of a string attribute
+// contains one or more certificates in PEM format, and nothing else.
+func PEMValidator() tfsdk.AttributeValidator {
+	return pemValidator{}
+}
+
+func (v pemValidator) Description(ctx context.Context) string {
+	return "The value must contain one or more certificates in PEM format."
+}
+
+func (v pemValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v pemValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest,
+	resp *tfsdk.ValidateAttributeResponse) {
+	value, ok := req.AttributeConfig.(types.String)
+	if !ok || value.Unknown || value.Null {
+		return
+	}
+	count := 0
+	rest := []byte(value.Value)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		count++
+		if block.Type != "CERTIFICATE" {
+			resp.Diagnostics.AddAttributeError(
+				req.AttributePath,
+				"Invalid certificate",
+				fmt.Sprintf(
+					"PEM block %d has type '%s' but it should be 'CERTIFICATE'",
+					count, block.Type,
+				),
+			)
+			return
+		}
+		_, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				req.AttributePath,
+				"Invalid certificate",
+				fmt.Sprintf("Can't parse certificate %d: %v", count, err),
+			)
+			return
+		}
+	}
+	if count == 0 {
+		resp.Diagnostics.AddAttributeError(
+			req.AttributePath,
+			"Invalid certificate",
+			"Value doesn't contain any certificate in PEM format",
+		)
+		return
+	}
+	if strings.TrimSpace(string(rest)) != "" {
+		resp.Diagnostics.AddAttributeError(
+			req.AttributePath,
+			"Invalid certificate",
+			"Value contains text that isn't in PEM format after the last certificate",
+		)
+	}
+}
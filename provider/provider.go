@@ -21,7 +21,7 @@ import (
 	"crypto/x509"
 	"fmt"
 	"os"
-	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
@@ -35,19 +35,25 @@ import (
 type Provider struct {
 	logger     logging.Logger
 	connection *sdk.Connection
+	trustedCAs string
+	trace      *os.File
 }
 
 // Config contains the configuration of the provider.
 type Config struct {
-	URL          types.String `tfsdk:"url"`
-	TokenURL     types.String `tfsdk:"token_url"`
-	User         types.String `tfsdk:"user"`
-	Password     types.String `tfsdk:"password"`
-	Token        types.String `tfsdk:"token"`
-	ClientID     types.String `tfsdk:"client_id"`
-	ClientSecret types.String `tfsdk:"client_secret"`
-	TrustedCAs   types.String `tfsdk:"trusted_cas"`
-	Insecure     types.Bool   `tfsdk:"insecure"`
+	URL            types.String `tfsdk:"url"`
+	TokenURL       types.String `tfsdk:"token_url"`
+	User           types.String `tfsdk:"user"`
+	Password       types.String `tfsdk:"password"`
+	Token          types.String `tfsdk:"token"`
+	ClientID       types.String `tfsdk:"client_id"`
+	ClientSecret   types.String `tfsdk:"client_secret"`
+	TrustedCAs     types.String `tfsdk:"trusted_cas"`
+	Insecure       types.Bool   `tfsdk:"insecure"`
+	Environment    types.String `tfsdk:"environment"`
+	MaxRetries     types.Int64  `tfsdk:"max_retries"`
+	RetryInterval  types.String `tfsdk:"retry_interval"`
+	RequestTimeout types.String `tfsdk:"request_timeout"`
 }
 
 // New creates the provider.
@@ -105,6 +111,49 @@ func (p *Provider) GetSchema(ctx context.Context) (schema tfsdk.Schema, diags di
 				Type:     types.StringType,
 				Optional: true,
 			},
+			"environment": {
+				Description: "Name of a well known OCM environment: " +
+					"'production', 'staging' or 'integration'. It sets the " +
+					"URLs of the API server and of the single sign on " +
+					"service, unless the 'url' and 'token_url' attributes " +
+					"are explicitly set.",
+				Type:     types.StringType,
+				Optional: true,
+				Validators: []tfsdk.AttributeValidator{
+					EnumValidator(ocmEnvironmentNames()...),
+				},
+			},
+			"max_retries": {
+				Description: "Maximum number of times that a request to the API " +
+					"server is retried when it is throttled, when the server " +
+					"is unavailable or when the connection fails. Zero " +
+					"disables retries. Default value is '3', and the " +
+					"maximum is '10'.",
+				Type:     types.Int64Type,
+				Optional: true,
+			},
+			"retry_interval": {
+				Description: "Time to wait before the first retry, for example " +
+					"'2s'. It is doubled for each retry, unless the server " +
+					"indicates how much to wait with the 'Retry-After' " +
+					"header. The wait between two retries is never longer " +
+					"than one minute. Default value is '1s'.",
+				Type:     types.StringType,
+				Optional: true,
+				Validators: []tfsdk.AttributeValidator{
+					DurationValidator(),
+				},
+			},
+			"request_timeout": {
+				Description: "Maximum time to wait for each attempt of a request " +
+					"to the API server, for example '1m'. By default there " +
+					"is no limit.",
+				Type:     types.StringType,
+				Optional: true,
+				Validators: []tfsdk.AttributeValidator{
+					DurationValidator(),
+				},
+			},
 			"insecure": {
 				Description: "When set to 'true' enables insecure communication " +
 					"with the server. This disables verification of TLS " +
@@ -130,62 +179,107 @@ func (p *Provider) Configure(ctx context.Context, request tfsdk.ConfigureProvide
 		return
 	}
 
-	// Determine the log level used by the SDK from the environment variables used by Terraform:
-	level := os.Getenv("TF_LOG")
-
-	// The plugin infrastructure redirects the log package output so that it is sent to the main
-	// Terraform process, so if we want to have the logs of the SDK redirected we need to use
-	// the log package as well.
-	logger, err := logging.NewGoLoggerBuilder().
-		Error(true).
-		Warn(true).
-		Info(true).
-		Debug(strings.EqualFold(level, "DEBUG")).
-		Build()
-	if err != nil {
-		response.Diagnostics.AddError(err.Error(), "")
-		return
-	}
+	// Send the log messages of the SDK and of the provider to the logging infrastructure of
+	// Terraform, so that they are structured and tagged with the details of the RPC:
+	logger := &TFLogLogger{}
 
 	// Create the builder:
 	builder := sdk.NewConnectionBuilder()
 	builder.Logger(logger)
 	builder.Agent(fmt.Sprintf("OCM-TF/%s-%s", build.Version, build.Commit))
 
-	// Copy the settings:
-	if !config.URL.Null {
-		builder.URL(config.URL.Value)
-	} else {
-		url, ok := os.LookupEnv("OCM_URL")
+	// Copy the settings. Attributes explicitly set in the configuration of the provider,
+	// including the named environment, take precedence over environment variables, and finally
+	// the configuration file of the `ocm` command line tool, which is only used
+	// when no credentials have been given in any other way.
+	var credentials string
+	hasURL := false
+	hasTokenURL := false
+	url, tokenURL, err := ocmURLs(config.URL, config.TokenURL, config.Environment)
+	if err != nil {
+		response.Diagnostics.AddError("Unknown environment", err.Error())
+		return
+	}
+	if url != "" {
+		builder.URL(url)
+		hasURL = true
+	}
+	if tokenURL != "" {
+		builder.TokenURL(tokenURL)
+		hasTokenURL = true
+	}
+	user, source, ok := stringSetting(config.User, "OCM_USER")
+	if ok {
+		password, _, ok := stringSetting(config.Password, "OCM_PASSWORD")
 		if ok {
-			builder.URL(url)
+			builder.User(user, password)
+			credentials = source
 		}
 	}
-	if !config.TokenURL.Null {
-		builder.TokenURL(config.TokenURL.Value)
-	}
-	if !config.User.Null && !config.Password.Null {
-		builder.User(config.User.Value, config.Password.Value)
+	token, source, ok := stringSetting(config.Token, "OCM_TOKEN")
+	if ok {
+		builder.Tokens(token)
+		credentials = source
 	}
-	if !config.Token.Null {
-		builder.Tokens(config.Token.Value)
-	} else {
-		token, ok := os.LookupEnv("OCM_TOKEN")
+	clientID, source, ok := stringSetting(config.ClientID, "OCM_CLIENT_ID")
+	if ok {
+		clientSecret, _, ok := stringSetting(config.ClientSecret, "OCM_CLIENT_SECRET")
 		if ok {
-			builder.Tokens(token)
+			builder.Client(clientID, clientSecret)
+			credentials = source
 		}
 	}
-	if !config.ClientID.Null && !config.ClientSecret.Null {
-		builder.Client(config.ClientID.Value, config.ClientSecret.Value)
+	insecure, hasInsecure, err := boolSetting(config.Insecure, "OCM_INSECURE")
+	if err != nil {
+		response.Diagnostics.AddError(err.Error(), "")
+		return
+	}
+	if hasInsecure {
+		builder.Insecure(insecure)
+	}
+	if credentials == "" {
+		ocmConfig, ocmConfigPath, err := loadOCMConfig()
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't load configuration of the 'ocm' command line tool",
+				err.Error(),
+			)
+			return
+		}
+		switch {
+		case ocmConfig == nil || !ocmConfig.HasCredentials():
+			// Nothing to use.
+		case !ocmConfig.MatchesURL(url):
+			logger.Warn(
+				ctx,
+				"Ignoring the credentials of the configuration file '%s' of the "+
+					"'ocm' command line tool because they aren't for URL '%s'",
+				ocmConfigPath, url,
+			)
+		default:
+			ocmConfig.Apply(builder, !hasURL, !hasTokenURL)
+			if !hasInsecure {
+				builder.Insecure(ocmConfig.Insecure)
+			}
+			credentials = fmt.Sprintf(
+				"configuration file '%s' of the 'ocm' command line tool",
+				ocmConfigPath,
+			)
+		}
 	}
-	if !config.Insecure.Null {
-		builder.Insecure(config.Insecure.Value)
+	if credentials != "" {
+		logger.Info(ctx, "Using credentials from the %s", credentials)
 	}
-	if !config.TrustedCAs.Null {
+	trustedCAs, source, ok := stringSetting(config.TrustedCAs, "OCM_TRUSTED_CAS")
+	if ok {
 		pool := x509.NewCertPool()
-		if !pool.AppendCertsFromPEM([]byte(config.TrustedCAs.Value)) {
+		if !pool.AppendCertsFromPEM([]byte(trustedCAs)) {
 			response.Diagnostics.AddError(
-				"the value of 'trusted_cas' doesn't contain any certificate",
+				fmt.Sprintf(
+					"the value of the %s for 'trusted_cas' doesn't contain "+
+						"any certificate",
+					source,
+				),
 				"",
 			)
 			return
@@ -193,6 +287,41 @@ func (p *Provider) Configure(ctx context.Context, request tfsdk.ConfigureProvide
 		builder.TrustedCAs(pool)
 	}
 
+	// Replace the retry logic of the SDK with our own, as it doesn't honour the `Retry-After`
+	// header sent by the server when requests are throttled:
+	retries, err := retrySettings(config)
+	if err != nil {
+		response.Diagnostics.AddError(err.Error(), "")
+		return
+	}
+	retries.Logger = logger
+	builder.RetryLimit(0)
+	builder.TransportWrapper(retries.Wrap)
+
+	// Log each attempt of each request, and write the HTTP exchanges to the trace file if
+	// requested. Sensitive fields are always redacted.
+	tracing := &LoggingTransportWrapper{
+		Bodies: traceEnabled(),
+	}
+	traceFile, ok := os.LookupEnv("OCM_TRACE_FILE")
+	if ok && traceFile != "" {
+		if p.trace == nil {
+			p.trace, err = os.OpenFile(
+				traceFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600,
+			)
+			if err != nil {
+				response.Diagnostics.AddError(
+					"Can't open trace file",
+					fmt.Sprintf("Can't open trace file '%s': %v", traceFile, err),
+				)
+				return
+			}
+		}
+		tracing.Trace = p.trace
+		logger.Info(ctx, "Writing HTTP exchanges to trace file '%s'", traceFile)
+	}
+	builder.TransportWrapper(tracing.Wrap)
+
 	// Create the connection:
 	connection, err := builder.BuildContext(ctx)
 	if err != nil {
@@ -203,17 +332,26 @@ func (p *Provider) Configure(ctx context.Context, request tfsdk.ConfigureProvide
 	// Save the connection:
 	p.logger = logger
 	p.connection = connection
+	p.trustedCAs = trustedCAs
 }
 
 // GetResources returns the resources supported by the provider.
 func (p *Provider) GetResources(ctx context.Context) (result map[string]tfsdk.ResourceType,
 	diags diag.Diagnostics) {
 	result = map[string]tfsdk.ResourceType{
-		"ocm_cluster":              &ClusterResourceType{},
-		"ocm_cluster_rosa_classic": &ClusterRosaClassicResourceType{p.logger},
-		"ocm_group_membership":     &GroupMembershipResourceType{},
-		"ocm_identity_provider":    &IdentityProviderResourceType{},
-		"ocm_machine_pool":         &MachinePoolResourceType{p.logger},
+		"ocm_cluster":                &ClusterResourceType{},
+		"ocm_cluster_addon":          &ClusterAddonResourceType{},
+		"ocm_cluster_ingress":        &ClusterIngressResourceType{},
+		"ocm_cluster_rosa_classic":   &ClusterRosaClassicResourceType{p.logger},
+		"ocm_cluster_upgrade_policy": &ClusterUpgradePolicyResourceType{p.logger},
+		"ocm_group_members":          &GroupMembersResourceType{},
+		"ocm_group_membership":       &GroupMembershipResourceType{},
+		"ocm_htpasswd_user":          &HTPasswdUserResourceType{},
+		"ocm_identity_provider":      &IdentityProviderResourceType{},
+		"ocm_machine_pool":           &MachinePoolResourceType{p.logger},
+		"ocm_rosa_account_roles":     &RosaAccountRolesResourceType{},
+		"ocm_rosa_oidc_config":       &RosaOIDCConfigResourceType{},
+		"ocm_rosa_operator_roles":    &RosaOperatorRolesResourceType{},
 	}
 	return
 }
@@ -222,11 +360,47 @@ func (p *Provider) GetResources(ctx context.Context) (result map[string]tfsdk.Re
 func (p *Provider) GetDataSources(ctx context.Context) (result map[string]tfsdk.DataSourceType,
 	diags diag.Diagnostics) {
 	result = map[string]tfsdk.DataSourceType{
-		"ocm_cloud_providers":     &CloudProvidersDataSourceType{},
-		"ocm_rosa_operator_roles": &RosaOperatorRolesDataSourceType{},
-		"ocm_groups":              &GroupsDataSourceType{},
-		"ocm_machine_types":       &MachineTypesDataSourceType{},
-		"ocm_versions":            &VersionsDataSourceType{},
+		"ocm_addons":                     &AddonsDataSourceType{},
+		"ocm_cloud_providers":            &CloudProvidersDataSourceType{},
+		"ocm_rosa_account_role_policies": &RosaAccountRolePoliciesDataSourceType{},
+		"ocm_rosa_operator_roles":        &RosaOperatorRolesDataSourceType{},
+		"ocm_groups":                     &GroupsDataSourceType{},
+		"ocm_machine_types":              &MachineTypesDataSourceType{},
+		"ocm_oidc_thumbprint":            &OIDCThumbprintDataSourceType{},
+		"ocm_versions":                   &VersionsDataSourceType{},
+	}
+	return
+}
+
+// retrySettings creates the retry transport wrapper from the configuration of the provider.
+func retrySettings(config Config) (result *RetryTransportWrapper, err error) {
+	result = &RetryTransportWrapper{
+		Limit:    defaultMaxRetries,
+		Interval: defaultRetryInterval,
+	}
+	if !config.MaxRetries.Unknown && !config.MaxRetries.Null {
+		if config.MaxRetries.Value < 0 || config.MaxRetries.Value > maxRetryLimit {
+			err = fmt.Errorf(
+				"value of 'max_retries' should be between zero and %d, but it is %d",
+				maxRetryLimit, config.MaxRetries.Value,
+			)
+			return
+		}
+		result.Limit = int(config.MaxRetries.Value)
+	}
+	if !config.RetryInterval.Unknown && !config.RetryInterval.Null {
+		result.Interval, err = time.ParseDuration(config.RetryInterval.Value)
+		if err != nil {
+			err = fmt.Errorf("value of 'retry_interval' isn't valid: %v", err)
+			return
+		}
+	}
+	if !config.RequestTimeout.Unknown && !config.RequestTimeout.Null {
+		result.Timeout, err = time.ParseDuration(config.RequestTimeout.Value)
+		if err != nil {
+			err = fmt.Errorf("value of 'request_timeout' isn't valid: %v", err)
+			return
+		}
 	}
 	return
 }
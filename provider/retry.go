@@ -0,0 +1,272 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+// Default values used when the corresponding attributes of the provider aren't explicitly set:
+const (
+	defaultMaxRetries    = 3
+	defaultRetryInterval = 1 * time.Second
+	retryJitter          = 0.2
+)
+
+// Limits of the retries, regardless of the configuration of the provider and of what the server
+// asks for:
+const (
+	maxRetryLimit = 10
+	maxRetryWait  = 1 * time.Minute
+)
+
+// RetryTransportWrapper wraps the HTTP transport used by the connection to the OCM API so that
+// throttled and failed requests are retried with exponential backoff. Unlike the retry logic
+// of the SDK it honours the `Retry-After` header sent by the server, it stops waiting when the
+// context is cancelled, and it can limit the time of each individual attempt.
+type RetryTransportWrapper struct {
+	// Logger is used to report the retries.
+	Logger logging.Logger
+
+	// Limit is the maximum number of retries. Zero disables retries.
+	Limit int
+
+	// Interval is the time to wait before the first retry. It is doubled for each retry, unless
+	// the server explicitly says how much to wait.
+	Interval time.Duration
+
+	// Timeout is the maximum time for each attempt. Zero means no timeout.
+	Timeout time.Duration
+}
+
+type retryRoundTripper struct {
+	wrapper   *RetryTransportWrapper
+	transport http.RoundTripper
+}
+
+// Wrap creates a round tripper that wraps the given one and adds the retry logic.
+func (w *RetryTransportWrapper) Wrap(transport http.RoundTripper) http.RoundTripper {
+	return &retryRoundTripper{
+		wrapper:   w,
+		transport: transport,
+	}
+}
+
+func (t *retryRoundTripper) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	ctx := request.Context()
+
+	// The body needs to be copied in memory so that it can be sent again:
+	var body []byte
+	if request.Body != nil {
+		body, err = ioutil.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		response, err = t.send(ctx, request, body)
+		if attempt >= t.wrapper.Limit {
+			return
+		}
+		reason, wait := t.check(request, response, err)
+		if reason == "" {
+			return
+		}
+		if wait == 0 {
+			wait = t.backoff(attempt)
+		}
+		if response != nil {
+			io.Copy(ioutil.Discard, response.Body)
+			response.Body.Close()
+		}
+		if t.wrapper.Logger != nil {
+			t.wrapper.Logger.Warn(
+				ctx,
+				"Request for method %s and URL '%s' failed with %s, will try "+
+					"again in %s",
+				request.Method, request.URL, reason, wait,
+			)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			response = nil
+			err = ctx.Err()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// send does one attempt, applying the per attempt timeout if needed. The timeout covers reading
+// the response body as well, so it is only released when the body is closed.
+func (t *retryRoundTripper) send(ctx context.Context, request *http.Request,
+	body []byte) (response *http.Response, err error) {
+	cancel := func() {}
+	if t.wrapper.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.wrapper.Timeout)
+	}
+	attempt := request.Clone(ctx)
+	if body != nil {
+		attempt.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	response, err = t.transport.RoundTrip(attempt)
+	if err != nil {
+		cancel()
+		return
+	}
+	response.Body = &cancelOnClose{
+		ReadCloser: response.Body,
+		cancel:     cancel,
+	}
+	return
+}
+
+// check decides if the result of an attempt should be retried. It returns a description of the
+// failure, or an empty string if the request shouldn't be retried, and the time that the server
+// asked to wait, if any.
+func (t *retryRoundTripper) check(request *http.Request, response *http.Response,
+	err error) (reason string, wait time.Duration) {
+	if err != nil {
+		// Errors caused by the context of the caller aren't retried, but timeouts of
+		// individual attempts are:
+		if request.Context().Err() != nil {
+			return
+		}
+		// A refused connection means that the request never reached the server, so it is
+		// safe to retry regardless of the method. For the rest of the errors the server may
+		// have already processed the request, so only requests without side effects are
+		// retried:
+		message := err.Error()
+		switch {
+		case strings.Contains(message, "connection refused"):
+			reason = "connection refused"
+		case !isIdempotent(request):
+			return
+		case errors.Is(err, context.DeadlineExceeded):
+			reason = "timeout"
+		case strings.Contains(message, "EOF"):
+			reason = "EOF"
+		case strings.Contains(message, "connection reset by peer"):
+			reason = "connection reset by peer"
+		}
+		return
+	}
+	code := response.StatusCode
+	switch {
+	case code == http.StatusTooManyRequests || code == http.StatusServiceUnavailable:
+		// The server didn't process the request, so it is safe to retry regardless of the
+		// method:
+		reason = fmt.Sprintf("code %d", code)
+		wait = retryAfter(response)
+	case code >= http.StatusInternalServerError && isIdempotent(request):
+		// For other server errors we don't know if the request was processed, so only
+		// requests without side effects are retried:
+		reason = fmt.Sprintf("code %d", code)
+	}
+	return
+}
+
+// isIdempotent checks if the request has no side effects, so that it can be safely sent again
+// when we don't know if the server processed it.
+func isIdempotent(request *http.Request) bool {
+	switch request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff calculates the time to wait before the given retry, doubling the interval for each
+// attempt and adding some jitter so that clients don't retry at the same time. The result is
+// never longer than the maximum wait.
+func (t *retryRoundTripper) backoff(attempt int) time.Duration {
+	// The interval is doubled step by step instead of shifting, as that could overflow:
+	interval := t.wrapper.Interval
+	for i := 0; i < attempt && interval < maxRetryWait; i++ {
+		interval *= 2
+	}
+	factor := retryJitter * (1 - 2*rand.Float64()) // nolint:gosec
+	interval += time.Duration(float64(interval) * factor)
+	if interval > maxRetryWait {
+		interval = maxRetryWait
+	}
+	return interval
+}
+
+// retryAfter returns the time to wait indicated by the `Retry-After` header of the response,
+// or zero if there is no such header or it isn't valid. The header can contain a number of
+// seconds or a date. The result is never longer than the maximum wait.
+func retryAfter(response *http.Response) time.Duration {
+	value := response.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	var wait time.Duration
+	seconds, err := strconv.Atoi(value)
+	if err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		// Checked before converting to a duration, as that could overflow:
+		if seconds > int(maxRetryWait/time.Second) {
+			return maxRetryWait
+		}
+		wait = time.Duration(seconds) * time.Second
+	} else {
+		date, err := http.ParseTime(value)
+		if err != nil {
+			return 0
+		}
+		wait = time.Until(date)
+		if wait <= 0 {
+			return 0
+		}
+	}
+	if wait > maxRetryWait {
+		wait = maxRetryWait
+	}
+	return wait
+}
+
+// cancelOnClose releases the context of an attempt when the response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
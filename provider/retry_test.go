@@ -0,0 +1,247 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/onsi/gomega/ghttp"                   // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+var _ = Describe("Retries", func() {
+	const path = "/api/clusters_mgmt/v1/clusters"
+
+	var (
+		ctx     context.Context
+		server  *Server
+		wrapper *RetryTransportWrapper
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		server = NewServer()
+		logger, err := logging.NewGoLoggerBuilder().Build()
+		Expect(err).ToNot(HaveOccurred())
+		wrapper = &RetryTransportWrapper{
+			Logger:   logger,
+			Limit:    3,
+			Interval: time.Millisecond,
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	// connect creates a connection to the test server that uses the retry wrapper, the same
+	// way that the provider does.
+	connect := func() *sdk.Connection {
+		logger, err := logging.NewGoLoggerBuilder().Build()
+		Expect(err).ToNot(HaveOccurred())
+		connection, err := sdk.NewConnectionBuilder().
+			Logger(logger).
+			URL(server.URL()).
+			Tokens(MakeTokenString("Bearer", 10*time.Minute)).
+			RetryLimit(0).
+			TransportWrapper(wrapper.Wrap).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(connection.Close)
+		return connection
+	}
+
+	It("Honours the Retry-After header of throttled requests", func() {
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, path),
+				RespondWith(
+					http.StatusTooManyRequests,
+					`{}`,
+					http.Header{"Retry-After": []string{"1"}},
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, path),
+				RespondWithJSON(http.StatusOK, `{}`),
+			),
+		)
+		start := time.Now()
+		response, err := connect().Get().Path(path).SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Status()).To(Equal(http.StatusOK))
+		Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+		Expect(server.ReceivedRequests()).To(HaveLen(2))
+	})
+
+	It("Doubles the interval between retries", func() {
+		wrapper.Interval = 100 * time.Millisecond
+		server.AppendHandlers(
+			RespondWithJSON(http.StatusServiceUnavailable, `{}`),
+			RespondWithJSON(http.StatusServiceUnavailable, `{}`),
+			RespondWithJSON(http.StatusOK, `{}`),
+		)
+		start := time.Now()
+		response, err := connect().Get().Path(path).SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Status()).To(Equal(http.StatusOK))
+
+		// With a 20% jitter the waits are at least 80ms and 160ms:
+		Expect(time.Since(start)).To(BeNumerically(">=", 240*time.Millisecond))
+		Expect(server.ReceivedRequests()).To(HaveLen(3))
+	})
+
+	It("Retries posts when throttled", func() {
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, path),
+				VerifyJSON(`{"name": "my-cluster"}`),
+				RespondWithJSON(http.StatusTooManyRequests, `{}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, path),
+				VerifyJSON(`{"name": "my-cluster"}`),
+				RespondWithJSON(http.StatusCreated, `{}`),
+			),
+		)
+		response, err := connect().Post().Path(path).
+			String(`{"name": "my-cluster"}`).
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Status()).To(Equal(http.StatusCreated))
+	})
+
+	It("Doesn't retry posts that failed with a server error", func() {
+		server.AppendHandlers(
+			RespondWithJSON(http.StatusInternalServerError, `{}`),
+		)
+		response, err := connect().Post().Path(path).String(`{}`).SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Status()).To(Equal(http.StatusInternalServerError))
+		Expect(server.ReceivedRequests()).To(HaveLen(1))
+	})
+
+	It("Gives up after the maximum number of retries", func() {
+		wrapper.Limit = 1
+		server.AppendHandlers(
+			RespondWithJSON(http.StatusServiceUnavailable, `{}`),
+			RespondWithJSON(http.StatusServiceUnavailable, `{}`),
+		)
+		response, err := connect().Get().Path(path).SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Status()).To(Equal(http.StatusServiceUnavailable))
+		Expect(server.ReceivedRequests()).To(HaveLen(2))
+	})
+
+	It("Retries attempts that exceed the request timeout", func() {
+		wrapper.Timeout = 100 * time.Millisecond
+		server.AppendHandlers(
+			CombineHandlers(
+				func(w http.ResponseWriter, r *http.Request) {
+					time.Sleep(500 * time.Millisecond)
+				},
+				RespondWithJSON(http.StatusOK, `{}`),
+			),
+			RespondWithJSON(http.StatusOK, `{}`),
+		)
+		response, err := connect().Get().Path(path).SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Status()).To(Equal(http.StatusOK))
+		Eventually(server.ReceivedRequests).Should(HaveLen(2))
+	})
+
+	It("Doesn't retry posts that exceed the request timeout", func() {
+		wrapper.Timeout = 100 * time.Millisecond
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, path),
+				func(w http.ResponseWriter, r *http.Request) {
+					time.Sleep(500 * time.Millisecond)
+				},
+				RespondWithJSON(http.StatusCreated, `{}`),
+			),
+		)
+		_, err := connect().Post().Path(path).String(`{}`).SendContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Consistently(server.ReceivedRequests, 500*time.Millisecond).Should(HaveLen(1))
+	})
+
+	It("Stops waiting when the context is cancelled", func() {
+		server.AppendHandlers(
+			RespondWith(
+				http.StatusTooManyRequests,
+				`{}`,
+				http.Header{"Retry-After": []string{"60"}},
+			),
+		)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err := connect().Get().Path(path).SendContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", 10*time.Second))
+	})
+
+	It("Limits the backoff of late retries", func() {
+		wrapper.Interval = time.Second
+		tripper := &retryRoundTripper{
+			wrapper: wrapper,
+		}
+		for _, attempt := range []int{10, 62, 63, 64, 1000} {
+			wait := tripper.backoff(attempt)
+			Expect(wait).To(BeNumerically(">", 0))
+			Expect(wait).To(BeNumerically("<=", maxRetryWait))
+		}
+	})
+
+	It("Limits the wait requested by the Retry-After header", func() {
+		wait := retryAfter(&http.Response{
+			Header: http.Header{"Retry-After": []string{"7200"}},
+		})
+		Expect(wait).To(Equal(maxRetryWait))
+		date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+		wait = retryAfter(&http.Response{
+			Header: http.Header{"Retry-After": []string{date}},
+		})
+		Expect(wait).To(Equal(maxRetryWait))
+	})
+
+	It("Rejects too many retries", func() {
+		_, err := retrySettings(Config{
+			MaxRetries: types.Int64{Value: 1000},
+		})
+		Expect(err).To(MatchError(ContainSubstring("between zero and 10")))
+	})
+
+	It("Parses Retry-After dates", func() {
+		date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+		wait := retryAfter(&http.Response{
+			Header: http.Header{"Retry-After": []string{date}},
+		})
+		Expect(wait).To(BeNumerically(">", 50*time.Second))
+		Expect(wait).To(BeNumerically("<=", time.Minute))
+	})
+})
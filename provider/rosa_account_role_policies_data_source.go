@@ -0,0 +1,190 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+type RosaAccountRolePoliciesDataSourceType struct {
+}
+
+type RosaAccountRolePoliciesDataSource struct {
+	logger       logging.Logger
+	awsInquiries *cmv1.AWSInquiriesClient
+	jumpAccount  string
+}
+
+func (t *RosaAccountRolePoliciesDataSourceType) GetSchema(ctx context.Context) (result tfsdk.Schema,
+	diags diag.Diagnostics) {
+	result = tfsdk.Schema{
+		Description: "Trust and permission policies of the account roles needed to " +
+			"create ROSA clusters with STS.",
+		Attributes: map[string]tfsdk.Attribute{
+			"openshift_version": {
+				Description: "OpenShift version, for example '4.11.2'. It is " +
+					"only used to check that the version supports STS, the " +
+					"data source fails if it doesn't. The returned policies " +
+					"are the same for all the versions that support STS.",
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"partition": {
+				Description: "AWS partition where the roles will be created, " +
+					"for example 'aws-us-gov'. The default is 'aws'.",
+				Type:     types.StringType,
+				Optional: true,
+				Computed: true,
+			},
+			"permission_policies": {
+				Description: "Permission policies of the roles, in JSON format, " +
+					"indexed by role type: 'installer', 'support', " +
+					"'instance_controlplane' and 'instance_worker'.",
+				Type: types.MapType{
+					ElemType: types.StringType,
+				},
+				Computed: true,
+			},
+			"trust_policies": {
+				Description: "Trust policies of the roles, in JSON format, " +
+					"indexed by role type: 'installer', 'support', " +
+					"'instance_controlplane' and 'instance_worker'.",
+				Type: types.MapType{
+					ElemType: types.StringType,
+				},
+				Computed: true,
+			},
+		},
+	}
+	return
+}
+
+func (t *RosaAccountRolePoliciesDataSourceType) NewDataSource(ctx context.Context,
+	p tfsdk.Provider) (result tfsdk.DataSource, diags diag.Diagnostics) {
+	// Cast the provider interface to the specific implementation:
+	parent := p.(*Provider)
+
+	// Get the AWS inquiries client:
+	awsInquiries := parent.connection.ClustersMgmt().V1().AWSInquiries()
+
+	// Create the data source:
+	result = &RosaAccountRolePoliciesDataSource{
+		logger:       parent.logger,
+		awsInquiries: awsInquiries,
+		jumpAccount:  jumpAccountID(parent.connection.URL()),
+	}
+	return
+}
+
+func (s *RosaAccountRolePoliciesDataSource) Read(ctx context.Context,
+	request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	// Get the state:
+	state := &RosaAccountRolePoliciesState{}
+	diags := request.Config.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// OCM returns the same account role policies for all the versions that support STS, so
+	// we only need to check that the requested version is one of them:
+	if !state.OpenShiftVersion.Unknown && !state.OpenShiftVersion.Null {
+		supported, err := checkSupportedVersion(state.OpenShiftVersion.Value)
+		if err != nil {
+			response.Diagnostics.AddAttributeError(
+				tftypes.NewAttributePath().WithAttributeName("openshift_version"),
+				"Invalid OpenShift version",
+				fmt.Sprintf(
+					"Can't check OpenShift version '%s': %v",
+					state.OpenShiftVersion.Value, err,
+				),
+			)
+			return
+		}
+		if !supported {
+			response.Diagnostics.AddAttributeError(
+				tftypes.NewAttributePath().WithAttributeName("openshift_version"),
+				"Unsupported OpenShift version",
+				fmt.Sprintf(
+					"OpenShift version '%s' doesn't support STS, the minimum "+
+						"version is '%s'",
+					state.OpenShiftVersion.Value, MinVersion,
+				),
+			)
+			return
+		}
+	}
+
+	// Get the policies:
+	policies, err := listSTSPolicies(ctx, s.awsInquiries.STSPolicies())
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't get STS policies",
+			fmt.Sprintf("Can't get STS policies: %v", err),
+		)
+		return
+	}
+	if state.Partition.Unknown || state.Partition.Null || state.Partition.Value == "" {
+		state.Partition = types.String{
+			Value: defaultAWSPartition,
+		}
+	}
+	values := map[string]string{
+		"partition":      state.Partition.Value,
+		"aws_account_id": s.jumpAccount,
+	}
+	state.TrustPolicies = types.Map{
+		ElemType: types.StringType,
+		Elems:    map[string]attr.Value{},
+	}
+	state.PermissionPolicies = types.Map{
+		ElemType: types.StringType,
+		Elems:    map[string]attr.Value{},
+	}
+	for _, accountRole := range accountRoles {
+		trust, permission, err := accountRolePolicies(policies, accountRole, values)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't get account role policies",
+				fmt.Sprintf(
+					"Can't get policies of the %s role: %v",
+					accountRole.Type, err,
+				),
+			)
+			return
+		}
+		state.TrustPolicies.Elems[accountRole.Type] = types.String{
+			Value: trust,
+		}
+		state.PermissionPolicies.Elems[accountRole.Type] = types.String{
+			Value: permission,
+		}
+	}
+
+	// Save the state:
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
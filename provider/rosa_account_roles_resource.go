@@ -0,0 +1,525 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+type RosaAccountRolesResourceType struct {
+}
+
+type RosaAccountRolesResource struct {
+	logger       logging.Logger
+	awsInquiries *cmv1.AWSInquiriesClient
+	iamClient    IAMClient
+	jumpAccount  string
+}
+
+func (t *RosaAccountRolesResourceType) GetSchema(ctx context.Context) (result tfsdk.Schema,
+	diags diag.Diagnostics) {
+	result = tfsdk.Schema{
+		Description: "Account roles needed to create ROSA clusters with STS. The trust and " +
+			"permission policies are the ones required by OCM, and the roles are created " +
+			"with the AWS credentials of the environment.",
+		Attributes: map[string]tfsdk.Attribute{
+			"id": {
+				Description: "Unique identifier of the account roles, the same " +
+					"as the account role prefix.",
+				Type:     types.StringType,
+				Computed: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"account_role_prefix": {
+				Description: "Prefix of the names of the roles. Default value is " +
+					"'" + DefaultAccountRolePrefix + "'.",
+				Type:     types.StringType,
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"path": {
+				Description: "Path of the roles. Default value is '/'.",
+				Type:        types.StringType,
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"permissions_boundary": {
+				Description: "ARN of the policy used as permissions boundary " +
+					"of the roles.",
+				Type:     types.StringType,
+				Optional: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"tags": {
+				Description: "Additional tags added to the roles.",
+				Type: types.MapType{
+					ElemType: types.StringType,
+				},
+				Optional: true,
+			},
+			"installer_role_arn": {
+				Description: "ARN of the installer role.",
+				Type:        types.StringType,
+				Computed:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"support_role_arn": {
+				Description: "ARN of the support role.",
+				Type:        types.StringType,
+				Computed:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"controlplane_role_arn": {
+				Description: "ARN of the control plane instance role.",
+				Type:        types.StringType,
+				Computed:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"worker_role_arn": {
+				Description: "ARN of the worker instance role.",
+				Type:        types.StringType,
+				Computed:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+	return
+}
+
+func (t *RosaAccountRolesResourceType) NewResource(ctx context.Context,
+	p tfsdk.Provider) (result tfsdk.Resource, diags diag.Diagnostics) {
+	// Cast the provider interface to the specific implementation:
+	parent := p.(*Provider)
+
+	// Get the AWS inquiries client:
+	awsInquiries := parent.connection.ClustersMgmt().V1().AWSInquiries()
+
+	// Create the resource:
+	result = &RosaAccountRolesResource{
+		logger:       parent.logger,
+		awsInquiries: awsInquiries,
+		iamClient:    NewDefaultIAMClient(),
+		jumpAccount:  jumpAccountID(parent.connection.URL()),
+	}
+	return
+}
+
+func (r *RosaAccountRolesResource) Create(ctx context.Context,
+	request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	// Get the plan:
+	state := &RosaAccountRolesState{}
+	diags := request.Plan.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Set the default values:
+	if state.AccountRolePrefix.Unknown || state.AccountRolePrefix.Null {
+		state.AccountRolePrefix = types.String{
+			Value: DefaultAccountRolePrefix,
+		}
+	}
+	if state.Path.Unknown || state.Path.Null {
+		state.Path = types.String{
+			Value: "/",
+		}
+	}
+
+	// Create the roles:
+	r.apply(ctx, state, true, &response.Diagnostics)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Save the state:
+	state.ID = state.AccountRolePrefix
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *RosaAccountRolesResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest,
+	response *tfsdk.ReadResourceResponse) {
+	// Get the current state:
+	state := &RosaAccountRolesState{}
+	diags := request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Get the roles. If any of them has been deleted then we remove the resource from the
+	// state, so that all of them are created again.
+	found, err := readAccountRoles(ctx, r.iamClient, state)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't get account roles",
+			fmt.Sprintf(
+				"Can't get account roles with prefix '%s': %v",
+				state.AccountRolePrefix.Value, err,
+			),
+		)
+		return
+	}
+	if !found {
+		r.logger.Warn(
+			ctx,
+			"Account roles with prefix '%s' don't exist, removing from state",
+			state.AccountRolePrefix.Value,
+		)
+		response.State.RemoveResource(ctx)
+		return
+	}
+
+	// Save the state:
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+// ModifyPlan compares the existing roles with the ones that would be applied, so that changes
+// made outside of Terraform, or new policies required by OCM, result in an update.
+func (r *RosaAccountRolesResource) ModifyPlan(ctx context.Context,
+	request tfsdk.ModifyResourcePlanRequest, response *tfsdk.ModifyResourcePlanResponse) {
+	// Nothing to compare when the roles are being created or deleted:
+	if request.State.Raw.IsNull() || request.Plan.Raw.IsNull() {
+		return
+	}
+
+	// Get the plan:
+	plan := &RosaAccountRolesState{}
+	diags := request.Plan.Get(ctx, plan)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() || plan.Tags.Unknown {
+		return
+	}
+
+	// Compare the roles:
+	policies, values := r.policies(ctx, &response.Diagnostics)
+	if response.Diagnostics.HasError() {
+		return
+	}
+	changed, err := accountRolesChanged(ctx, r.iamClient, policies, values, plan)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't get account roles",
+			fmt.Sprintf(
+				"Can't get account roles with prefix '%s': %v",
+				plan.AccountRolePrefix.Value, err,
+			),
+		)
+		return
+	}
+	if !changed {
+		return
+	}
+
+	// The roles will be updated, and the ARNs are only known after that:
+	for _, name := range []string{
+		"installer_role_arn",
+		"support_role_arn",
+		"controlplane_role_arn",
+		"worker_role_arn",
+	} {
+		diags = response.Plan.SetAttribute(
+			ctx,
+			tftypes.NewAttributePath().WithAttributeName(name),
+			types.String{Unknown: true},
+		)
+		response.Diagnostics.Append(diags...)
+	}
+}
+
+func (r *RosaAccountRolesResource) Update(ctx context.Context,
+	request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	var diags diag.Diagnostics
+
+	// Get the state:
+	state := &RosaAccountRolesState{}
+	diags = request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Get the plan:
+	plan := &RosaAccountRolesState{}
+	diags = request.Plan.Get(ctx, plan)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// The prefix, the path and the permissions boundary can't be changed, changing them forces
+	// the replacement of the resource, so only the policies and the tags need to be updated:
+	plan.ID = state.ID
+	plan.AccountRolePrefix = state.AccountRolePrefix
+	plan.Path = state.Path
+	r.apply(ctx, plan, false, &response.Diagnostics)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Save the state:
+	diags = response.State.Set(ctx, plan)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *RosaAccountRolesResource) Delete(ctx context.Context,
+	request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	// Get the state:
+	state := &RosaAccountRolesState{}
+	diags := request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Delete the roles:
+	err := deleteAccountRoles(ctx, r.iamClient, state.AccountRolePrefix.Value)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't delete account roles",
+			fmt.Sprintf(
+				"Can't delete account roles with prefix '%s': %v",
+				state.AccountRolePrefix.Value, err,
+			),
+		)
+		return
+	}
+
+	// Remove the state:
+	response.State.RemoveResource(ctx)
+}
+
+func (r *RosaAccountRolesResource) ImportState(ctx context.Context,
+	request tfsdk.ImportResourceStateRequest, response *tfsdk.ImportResourceStateResponse) {
+	// The identifier of the resource is the account role prefix:
+	state := &RosaAccountRolesState{
+		ID: types.String{
+			Value: request.ID,
+		},
+		AccountRolePrefix: types.String{
+			Value: request.ID,
+		},
+		Path: types.String{
+			Null: true,
+		},
+		PermissionsBoundary: types.String{
+			Null: true,
+		},
+		Tags: types.Map{
+			ElemType: types.StringType,
+			Null:     true,
+		},
+	}
+	diags := response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+// apply gets the policies from OCM and then creates or updates the account roles.
+func (r *RosaAccountRolesResource) apply(ctx context.Context, state *RosaAccountRolesState,
+	create bool, diags *diag.Diagnostics) {
+	policies, values := r.policies(ctx, diags)
+	if diags.HasError() {
+		return
+	}
+	err := applyAccountRoles(ctx, r.iamClient, policies, values, state, create)
+	if err != nil {
+		diags.AddError(
+			"Can't create account roles",
+			fmt.Sprintf(
+				"Can't create account roles with prefix '%s': %v",
+				state.AccountRolePrefix.Value, err,
+			),
+		)
+	}
+}
+
+// policies gets the policies from OCM and the values used to replace their placeholders.
+func (r *RosaAccountRolesResource) policies(ctx context.Context,
+	diags *diag.Diagnostics) (policies map[string]string, values map[string]string) {
+	policies, err := listSTSPolicies(ctx, r.awsInquiries.STSPolicies())
+	if err != nil {
+		diags.AddError(
+			"Can't get STS policies",
+			fmt.Sprintf("Can't get STS policies: %v", err),
+		)
+		return
+	}
+	identity, err := r.iamClient.GetCallerIdentity(ctx)
+	if err != nil {
+		diags.AddError(
+			"Can't get AWS account",
+			fmt.Sprintf("Can't get identity of the AWS account: %v", err),
+		)
+		return
+	}
+	values = map[string]string{
+		"partition":      identity.Partition,
+		"aws_account_id": r.jumpAccount,
+	}
+	return
+}
+
+// applyAccountRoles creates or updates the account roles using the given policies, and saves
+// their ARNs to the state. The create flag indicates if the roles are being created, see the
+// ensureIAMRole function for details.
+func applyAccountRoles(ctx context.Context, client IAMClient, policies map[string]string,
+	values map[string]string, state *RosaAccountRolesState, create bool) error {
+	for _, accountRole := range accountRoles {
+		spec, err := accountRoleSpec(policies, values, state, accountRole)
+		if err != nil {
+			return err
+		}
+		arn, err := ensureIAMRole(ctx, client, spec, create)
+		if err != nil {
+			return fmt.Errorf("can't create role '%s': %v", spec.Role.Name, err)
+		}
+		*accountRoleARN(state, accountRole) = types.String{
+			Value: arn,
+		}
+	}
+	return nil
+}
+
+// accountRolesChanged checks if any of the account roles has a trust policy, permission policy
+// or tags different to the ones that would be applied using the given policies and state.
+func accountRolesChanged(ctx context.Context, client IAMClient, policies map[string]string,
+	values map[string]string, state *RosaAccountRolesState) (changed bool, err error) {
+	for _, accountRole := range accountRoles {
+		var spec *iamRoleSpec
+		spec, err = accountRoleSpec(policies, values, state, accountRole)
+		if err != nil {
+			return
+		}
+		changed, err = iamRoleChanged(ctx, client, spec)
+		if err != nil || changed {
+			return
+		}
+	}
+	return
+}
+
+// accountRoleSpec calculates the desired role and permission policy for the given account role.
+func accountRoleSpec(policies map[string]string, values map[string]string,
+	state *RosaAccountRolesState, accountRole accountRole) (spec *iamRoleSpec, err error) {
+	prefix := state.AccountRolePrefix.Value
+	trust, permission, err := accountRolePolicies(policies, accountRole, values)
+	if err != nil {
+		return
+	}
+	role := &IAMRole{
+		Name:             accountRoleName(prefix, accountRole),
+		Path:             state.Path.Value,
+		AssumeRolePolicy: trust,
+		Tags: roleTags(stringMap(state.Tags), map[string]string{
+			"rosa_role_prefix": prefix,
+			"rosa_role_type":   accountRole.Type,
+		}),
+	}
+	if !state.PermissionsBoundary.Unknown && !state.PermissionsBoundary.Null {
+		role.PermissionsBoundary = state.PermissionsBoundary.Value
+	}
+	spec = &iamRoleSpec{
+		Role:           role,
+		PolicyName:     accountRolePolicyName(prefix, accountRole),
+		PolicyDocument: permission,
+	}
+	return
+}
+
+// readAccountRoles saves to the state the ARNs of the account roles. It returns false if any of
+// the roles doesn't exist.
+func readAccountRoles(ctx context.Context, client IAMClient,
+	state *RosaAccountRolesState) (found bool, err error) {
+	prefix := state.AccountRolePrefix.Value
+	for _, accountRole := range accountRoles {
+		var role *IAMRole
+		role, err = client.GetRole(ctx, accountRoleName(prefix, accountRole))
+		if err != nil || role == nil {
+			return
+		}
+		*accountRoleARN(state, accountRole) = types.String{
+			Value: role.ARN,
+		}
+		state.Path = types.String{
+			Value: role.Path,
+		}
+		if role.PermissionsBoundary != "" {
+			state.PermissionsBoundary = types.String{
+				Value: role.PermissionsBoundary,
+			}
+		}
+	}
+	found = true
+	return
+}
+
+// deleteAccountRoles deletes the account roles with the given prefix and their policies.
+func deleteAccountRoles(ctx context.Context, client IAMClient, prefix string) error {
+	for _, accountRole := range accountRoles {
+		name := accountRoleName(prefix, accountRole)
+		err := deleteIAMRole(ctx, client, name, accountRolePolicyName(prefix, accountRole))
+		if err != nil {
+			return fmt.Errorf("can't delete role '%s': %v", name, err)
+		}
+	}
+	return nil
+}
+
+// accountRoleARN returns a pointer to the field of the state that contains the ARN of the given
+// account role.
+func accountRoleARN(state *RosaAccountRolesState, role accountRole) *types.String {
+	switch role.Type {
+	case "installer":
+		return &state.InstallerRoleARN
+	case "support":
+		return &state.SupportRoleARN
+	case "instance_controlplane":
+		return &state.ControlPlaneRoleARN
+	default:
+		return &state.WorkerRoleARN
+	}
+}
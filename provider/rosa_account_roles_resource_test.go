@@ -0,0 +1,202 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	. "github.com/onsi/ginkgo/v2/dsl/core" // nolint
+	. "github.com/onsi/gomega"             // nolint
+)
+
+var _ = Describe("Account roles", func() {
+	var ctx context.Context
+	var client *FakeIAMClient
+	var policies map[string]string
+	var values map[string]string
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		client = NewFakeIAMClient()
+		policies = map[string]string{}
+		for _, accountRole := range accountRoles {
+			policies[accountRole.PolicyID+"_trust_policy"] = `{"Account": "%{aws_account_id}"}`
+			policies[accountRole.PolicyID+"_permission_policy"] = `{"Partition": "%{partition}"}`
+		}
+		values = map[string]string{
+			"partition":      "aws",
+			"aws_account_id": "710019948333",
+		}
+	})
+
+	newState := func() *RosaAccountRolesState {
+		return &RosaAccountRolesState{
+			AccountRolePrefix: types.String{
+				Value: "my-prefix",
+			},
+			Path: types.String{
+				Value: "/",
+			},
+			PermissionsBoundary: types.String{
+				Null: true,
+			},
+			Tags: types.Map{
+				ElemType: types.StringType,
+				Elems: map[string]attr.Value{
+					"owner": types.String{
+						Value: "me",
+					},
+				},
+			},
+		}
+	}
+
+	It("Creates the roles", func() {
+		state := newState()
+		err := applyAccountRoles(ctx, client, policies, values, state, true)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(client.Roles).To(HaveLen(4))
+		installer := client.Roles["my-prefix-Installer-Role"]
+		Expect(installer).ToNot(BeNil())
+		Expect(installer.AssumeRolePolicy).To(Equal(`{"Account": "710019948333"}`))
+		Expect(installer.Tags).To(Equal(map[string]string{
+			"owner":            "me",
+			"red-hat-managed":  "true",
+			"rosa_role_prefix": "my-prefix",
+			"rosa_role_type":   "installer",
+		}))
+		Expect(client.Policies["my-prefix-Installer-Role"]).To(Equal(map[string]string{
+			"my-prefix-Installer-Role-Policy": `{"Partition": "aws"}`,
+		}))
+		Expect(client.Roles).To(HaveKey("my-prefix-Support-Role"))
+		Expect(client.Roles).To(HaveKey("my-prefix-ControlPlane-Role"))
+		Expect(client.Roles).To(HaveKey("my-prefix-Worker-Role"))
+
+		Expect(state.InstallerRoleARN.Value).To(Equal(
+			"arn:aws:iam::123456789012:role/my-prefix-Installer-Role",
+		))
+		Expect(state.SupportRoleARN.Value).To(Equal(
+			"arn:aws:iam::123456789012:role/my-prefix-Support-Role",
+		))
+		Expect(state.ControlPlaneRoleARN.Value).To(Equal(
+			"arn:aws:iam::123456789012:role/my-prefix-ControlPlane-Role",
+		))
+		Expect(state.WorkerRoleARN.Value).To(Equal(
+			"arn:aws:iam::123456789012:role/my-prefix-Worker-Role",
+		))
+	})
+
+	It("Updates existing roles", func() {
+		err := applyAccountRoles(ctx, client, policies, values, newState(), true)
+		Expect(err).ToNot(HaveOccurred())
+
+		// Change the policies returned by OCM and apply again:
+		policies["sts_installer_permission_policy"] = `{"Changed": true}`
+		state := newState()
+		state.Tags.Null = true
+		state.Tags.Elems = nil
+		err = applyAccountRoles(ctx, client, policies, values, state, false)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(client.Roles).To(HaveLen(4))
+		Expect(client.Roles["my-prefix-Installer-Role"].Tags).ToNot(HaveKey("owner"))
+		Expect(client.Policies["my-prefix-Installer-Role"]).To(Equal(map[string]string{
+			"my-prefix-Installer-Role-Policy": `{"Changed": true}`,
+		}))
+	})
+
+	It("Doesn't take over existing roles with a different prefix", func() {
+		client.Roles["my-prefix-Installer-Role"] = &IAMRole{
+			Name: "my-prefix-Installer-Role",
+			Tags: map[string]string{
+				"rosa_role_prefix": "your-prefix",
+			},
+		}
+		err := applyAccountRoles(ctx, client, policies, values, newState(), true)
+		Expect(err).To(MatchError(ContainSubstring("my-prefix-Installer-Role")))
+		Expect(client.Policies).ToNot(HaveKey("my-prefix-Installer-Role"))
+	})
+
+	It("Detects changes made outside of Terraform", func() {
+		err := applyAccountRoles(ctx, client, policies, values, newState(), true)
+		Expect(err).ToNot(HaveOccurred())
+		changed, err := accountRolesChanged(ctx, client, policies, values, newState())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeFalse())
+
+		// Change the permission policy:
+		client.Policies["my-prefix-Worker-Role"]["my-prefix-Worker-Role-Policy"] = `{}`
+		changed, err = accountRolesChanged(ctx, client, policies, values, newState())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+
+		// Restore the permission policy and change the tags:
+		err = applyAccountRoles(ctx, client, policies, values, newState(), false)
+		Expect(err).ToNot(HaveOccurred())
+		delete(client.Roles["my-prefix-Support-Role"].Tags, "owner")
+		changed, err = accountRolesChanged(ctx, client, policies, values, newState())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+	})
+
+	It("Fails if a policy is missing", func() {
+		delete(policies, "sts_support_trust_policy")
+		err := applyAccountRoles(ctx, client, policies, values, newState(), true)
+		Expect(err).To(MatchError(ContainSubstring("sts_support_trust_policy")))
+	})
+
+	It("Reads the roles", func() {
+		err := applyAccountRoles(ctx, client, policies, values, newState(), true)
+		Expect(err).ToNot(HaveOccurred())
+
+		state := &RosaAccountRolesState{
+			AccountRolePrefix: types.String{
+				Value: "my-prefix",
+			},
+		}
+		found, err := readAccountRoles(ctx, client, state)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(state.Path.Value).To(Equal("/"))
+		Expect(state.WorkerRoleARN.Value).To(Equal(
+			"arn:aws:iam::123456789012:role/my-prefix-Worker-Role",
+		))
+	})
+
+	It("Reports missing roles", func() {
+		err := applyAccountRoles(ctx, client, policies, values, newState(), true)
+		Expect(err).ToNot(HaveOccurred())
+		delete(client.Policies, "my-prefix-Support-Role")
+		delete(client.Roles, "my-prefix-Support-Role")
+
+		found, err := readAccountRoles(ctx, client, newState())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeFalse())
+	})
+
+	It("Deletes the roles and their policies", func() {
+		err := applyAccountRoles(ctx, client, policies, values, newState(), true)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = deleteAccountRoles(ctx, client, "my-prefix")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.Roles).To(BeEmpty())
+	})
+})
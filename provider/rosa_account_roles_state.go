@@ -0,0 +1,33 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type RosaAccountRolesState struct {
+	ID                  types.String `tfsdk:"id"`
+	AccountRolePrefix   types.String `tfsdk:"account_role_prefix"`
+	Path                types.String `tfsdk:"path"`
+	PermissionsBoundary types.String `tfsdk:"permissions_boundary"`
+	Tags                types.Map    `tfsdk:"tags"`
+	InstallerRoleARN    types.String `tfsdk:"installer_role_arn"`
+	SupportRoleARN      types.String `tfsdk:"support_role_arn"`
+	ControlPlaneRoleARN types.String `tfsdk:"controlplane_role_arn"`
+	WorkerRoleARN       types.String `tfsdk:"worker_role_arn"`
+}
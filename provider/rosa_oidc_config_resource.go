@@ -0,0 +1,350 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+type RosaOIDCConfigResourceType struct {
+}
+
+type RosaOIDCConfigResource struct {
+	logger     logging.Logger
+	connection *sdk.Connection
+}
+
+func (t *RosaOIDCConfigResourceType) GetSchema(ctx context.Context) (result tfsdk.Schema,
+	diags diag.Diagnostics) {
+	result = tfsdk.Schema{
+		Description: "OIDC configuration that can be shared by multiple ROSA clusters " +
+			"with STS, so that the AWS OIDC identity provider can be created before " +
+			"the clusters.",
+		Attributes: map[string]tfsdk.Attribute{
+			"id": {
+				Description: "Unique identifier of the OIDC configuration.",
+				Type:        types.StringType,
+				Computed:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"managed": {
+				Description: "Indicates if the OIDC configuration is managed by " +
+					"Red Hat. Unmanaged configurations are hosted in the AWS " +
+					"account of the user and require the 'secret_arn', " +
+					"'issuer_url' and 'installer_role_arn' attributes. Default " +
+					"value is 'true'.",
+				Type:     types.BoolType,
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"secret_arn": {
+				Description: "ARN of the AWS secret that contains the private " +
+					"key of the unmanaged OIDC configuration.",
+				Type:     types.StringType,
+				Optional: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"issuer_url": {
+				Description: "URL of the issuer. It is required for unmanaged " +
+					"OIDC configurations and assigned by the server for " +
+					"managed ones.",
+				Type:     types.StringType,
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"installer_role_arn": {
+				Description: "ARN of the installer role used to access the " +
+					"secret of the unmanaged OIDC configuration.",
+				Type:     types.StringType,
+				Optional: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"oidc_endpoint_url": {
+				Description: "URL of the issuer without the 'https://' prefix, " +
+					"in the same format used by the 'sts.oidc_endpoint_url' " +
+					"attribute of the clusters.",
+				Type:     types.StringType,
+				Computed: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+	return
+}
+
+func (t *RosaOIDCConfigResourceType) NewResource(ctx context.Context,
+	p tfsdk.Provider) (result tfsdk.Resource, diags diag.Diagnostics) {
+	// Cast the provider interface to the specific implementation:
+	parent := p.(*Provider)
+
+	// Create the resource:
+	result = &RosaOIDCConfigResource{
+		logger:     parent.logger,
+		connection: parent.connection,
+	}
+	return
+}
+
+func (r *RosaOIDCConfigResource) ValidateConfig(ctx context.Context,
+	request tfsdk.ValidateResourceConfigRequest, response *tfsdk.ValidateResourceConfigResponse) {
+	// Get the configuration:
+	config := &RosaOIDCConfigState{}
+	diags := request.Config.Get(ctx, config)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Check that the attributes required by unmanaged configurations are present only when
+	// needed. If the flag isn't known yet there is nothing to check till the apply phase,
+	// where the server will do it. Attributes that aren't known yet are considered present.
+	if config.Managed.Unknown {
+		return
+	}
+	managed := config.Managed.Null || config.Managed.Value
+	unmanagedAttributes := map[string]types.String{
+		"secret_arn":         config.SecretARN,
+		"issuer_url":         config.IssuerURL,
+		"installer_role_arn": config.InstallerRoleARN,
+	}
+	for name, value := range unmanagedAttributes {
+		present := !value.Null
+		if managed && present {
+			response.Diagnostics.AddAttributeError(
+				tftypes.NewAttributePath().WithAttributeName(name),
+				"Invalid OIDC configuration",
+				fmt.Sprintf(
+					"Attribute '%s' can only be used with unmanaged OIDC "+
+						"configurations",
+					name,
+				),
+			)
+		}
+		if !managed && !present {
+			response.Diagnostics.AddAttributeError(
+				tftypes.NewAttributePath().WithAttributeName(name),
+				"Invalid OIDC configuration",
+				fmt.Sprintf(
+					"Attribute '%s' is required for unmanaged OIDC "+
+						"configurations",
+					name,
+				),
+			)
+		}
+	}
+}
+
+func (r *RosaOIDCConfigResource) Create(ctx context.Context,
+	request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	// Get the plan:
+	state := &RosaOIDCConfigState{}
+	diags := request.Plan.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// The attributes have already been checked by the ValidateConfig method:
+	managed := state.Managed.Unknown || state.Managed.Null || state.Managed.Value
+
+	// Create the configuration:
+	config := &OIDCConfig{
+		Managed:  managed,
+		Reusable: true,
+	}
+	if !managed {
+		config.SecretARN = state.SecretARN.Value
+		config.IssuerURL = state.IssuerURL.Value
+		config.InstallerRoleARN = state.InstallerRoleARN.Value
+	}
+	config, err := createOIDCConfig(ctx, r.connection, config)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't create OIDC configuration",
+			fmt.Sprintf("Can't create OIDC configuration: %v", err),
+		)
+		return
+	}
+
+	// Save the state:
+	populateRosaOIDCConfigState(config, state)
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *RosaOIDCConfigResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest,
+	response *tfsdk.ReadResourceResponse) {
+	// Get the current state:
+	state := &RosaOIDCConfigState{}
+	diags := request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Find the configuration:
+	config, err := getOIDCConfig(ctx, r.connection, state.ID.Value)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't find OIDC configuration",
+			fmt.Sprintf(
+				"Can't find OIDC configuration with identifier '%s': %v",
+				state.ID.Value, err,
+			),
+		)
+		return
+	}
+	if config == nil {
+		r.logger.Warn(
+			ctx,
+			"OIDC configuration with identifier '%s' doesn't exist, removing from state",
+			state.ID.Value,
+		)
+		response.State.RemoveResource(ctx)
+		return
+	}
+
+	// Save the state:
+	populateRosaOIDCConfigState(config, state)
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *RosaOIDCConfigResource) Update(ctx context.Context,
+	request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	// All the attributes force the replacement of the resource, so there is nothing to update
+	// in the server:
+	plan := &RosaOIDCConfigState{}
+	diags := request.Plan.Get(ctx, plan)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+	diags = response.State.Set(ctx, plan)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *RosaOIDCConfigResource) Delete(ctx context.Context,
+	request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	// Get the state:
+	state := &RosaOIDCConfigState{}
+	diags := request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Delete the configuration:
+	err := deleteOIDCConfig(ctx, r.connection, state.ID.Value)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't delete OIDC configuration",
+			fmt.Sprintf(
+				"Can't delete OIDC configuration with identifier '%s': %v",
+				state.ID.Value, err,
+			),
+		)
+		return
+	}
+
+	// Remove the state:
+	response.State.RemoveResource(ctx)
+}
+
+func (r *RosaOIDCConfigResource) ImportState(ctx context.Context,
+	request tfsdk.ImportResourceStateRequest, response *tfsdk.ImportResourceStateResponse) {
+	// Find the configuration:
+	config, err := getOIDCConfig(ctx, r.connection, request.ID)
+	if err == nil && config == nil {
+		err = fmt.Errorf("it doesn't exist")
+	}
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't find OIDC configuration",
+			fmt.Sprintf(
+				"Can't find OIDC configuration with identifier '%s': %v",
+				request.ID, err,
+			),
+		)
+		return
+	}
+
+	// Save the state:
+	state := &RosaOIDCConfigState{}
+	populateRosaOIDCConfigState(config, state)
+	diags := response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+// populateRosaOIDCConfigState copies the data from the API object to the Terraform state.
+func populateRosaOIDCConfigState(config *OIDCConfig, state *RosaOIDCConfigState) {
+	state.ID = types.String{
+		Value: config.ID,
+	}
+	state.Managed = types.Bool{
+		Value: config.Managed,
+	}
+	state.IssuerURL = types.String{
+		Value: config.IssuerURL,
+	}
+	state.OIDCEndpointURL = types.String{
+		Value: strings.TrimPrefix(config.IssuerURL, "https://"),
+	}
+	if config.SecretARN != "" {
+		state.SecretARN = types.String{
+			Value: config.SecretARN,
+		}
+	} else {
+		state.SecretARN = types.String{
+			Null: true,
+		}
+	}
+	if config.InstallerRoleARN != "" {
+		state.InstallerRoleARN = types.String{
+			Value: config.InstallerRoleARN,
+		}
+	} else {
+		state.InstallerRoleARN = types.String{
+			Null: true,
+		}
+	}
+}
@@ -18,12 +18,15 @@ package provider
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/openshift-online/ocm-sdk-go/logging"
 )
@@ -34,11 +37,18 @@ type RosaOperatorRolesDataSourceType struct {
 type RosaOperatorRolesDataSource struct {
 	logger       logging.Logger
 	awsInquiries *cmv1.AWSInquiriesClient
+	collection   *cmv1.ClustersClient
 }
 
 const (
 	DefaultAccountRolePrefix = "ManagedOpenShift"
 	serviceAccountFmt        = "system:serviceaccount:%s:%s"
+
+	// maxIAMNameLength is the maximum length of the names of IAM roles and policies, and
+	// iamNameHashLength is the length of the hash added to the names that would collide
+	// after being truncated.
+	maxIAMNameLength  = 64
+	iamNameHashLength = 8
 )
 
 func (t *RosaOperatorRolesDataSourceType) GetSchema(ctx context.Context) (result tfsdk.Schema,
@@ -47,15 +57,44 @@ func (t *RosaOperatorRolesDataSourceType) GetSchema(ctx context.Context) (result
 		Description: "List of rosa operator role for a specific cluster.",
 		Attributes: map[string]tfsdk.Attribute{
 			"operator_role_prefix": {
-				Description: "Operator role prefix.",
-				Type:        types.StringType,
-				Required:    true,
+				Description: "Operator role prefix. Required unless the 'cluster' " +
+					"attribute is given, in which case the default is the operator " +
+					"role prefix of the cluster.",
+				Type:     types.StringType,
+				Optional: true,
+				Computed: true,
 			},
 			"account_role_prefix": {
 				Description: "Account role prefix.",
 				Type:        types.StringType,
 				Optional:    true,
 			},
+			"openshift_version": {
+				Description: "OpenShift version, for example '4.11.2'. Only the " +
+					"operators that are required by this version are returned. " +
+					"If the 'cluster' attribute is given the default is the " +
+					"version of the cluster, otherwise all the operators are " +
+					"returned.",
+				Type:     types.StringType,
+				Optional: true,
+				Computed: true,
+			},
+			"partition": {
+				Description: "AWS partition where the roles will be created, " +
+					"for example 'aws-us-gov'. If the 'cluster' attribute is " +
+					"given the default is the partition of the installer role " +
+					"of the cluster, otherwise it is 'aws'.",
+				Type:     types.StringType,
+				Optional: true,
+				Computed: true,
+			},
+			"cluster": {
+				Description: "Identifier of the cluster. When given, the OIDC " +
+					"endpoint and the AWS account of the cluster are used to " +
+					"build the trust policy of each role.",
+				Type:     types.StringType,
+				Optional: true,
+			},
 			"operator_iam_roles": {
 				Description: "Operator IAM Roles.",
 				Attributes: tfsdk.ListNestedAttributes(
@@ -98,6 +137,17 @@ func (t *RosaOperatorRolesDataSourceType) itemAttributes() map[string]tfsdk.Attr
 			},
 			Computed: true,
 		},
+		"policy_document": {
+			Description: "Permission policy of the role, in JSON format.",
+			Type:        types.StringType,
+			Computed:    true,
+		},
+		"assume_role_policy": {
+			Description: "Trust policy of the role, in JSON format. It is only " +
+				"calculated when the 'cluster' attribute is given.",
+			Type:     types.StringType,
+			Computed: true,
+		},
 	}
 }
 
@@ -106,13 +156,15 @@ func (t *RosaOperatorRolesDataSourceType) NewDataSource(ctx context.Context,
 	// Cast the provider interface to the specific implementation:
 	parent := p.(*Provider)
 
-	// Get the collection of clusters:
+	// Get the AWS inquiries and the collection of clusters:
 	awsInquiries := parent.connection.ClustersMgmt().V1().AWSInquiries()
+	collection := parent.connection.ClustersMgmt().V1().Clusters()
 
 	// Create the resource:
 	result = &RosaOperatorRolesDataSource{
 		logger:       parent.logger,
 		awsInquiries: awsInquiries,
+		collection:   collection,
 	}
 	return
 }
@@ -127,35 +179,136 @@ func (t *RosaOperatorRolesDataSource) Read(ctx context.Context, request tfsdk.Re
 		return
 	}
 
-	stsOperatorRolesList, err := t.awsInquiries.STSCredentialRequests().List().Send()
+	// Get the OIDC endpoint and the AWS account of the cluster, needed to build the trust
+	// policies. The operator role prefix and the version of the cluster are used when they
+	// aren't explicitly given.
+	var identity *IAMCallerIdentity
+	var oidcEndpointURL string
+	if !state.Cluster.Unknown && !state.Cluster.Null {
+		get, err := t.collection.Cluster(state.Cluster.Value).Get().SendContext(ctx)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't find cluster",
+				fmt.Sprintf(
+					"Can't find cluster with identifier '%s': %v",
+					state.Cluster.Value, err,
+				),
+			)
+			return
+		}
+		cluster := get.Body()
+		oidcEndpointURL = cluster.AWS().STS().OIDCEndpointURL()
+		if oidcEndpointURL == "" {
+			response.Diagnostics.AddError(
+				"Can't find OIDC endpoint",
+				fmt.Sprintf(
+					"Cluster with identifier '%s' doesn't have an OIDC endpoint, "+
+						"check that it is a ROSA cluster with STS",
+					state.Cluster.Value,
+				),
+			)
+			return
+		}
+		if state.Partition.Unknown || state.Partition.Null || state.Partition.Value == "" {
+			state.Partition = types.String{
+				Value: arnPartition(cluster.AWS().STS().RoleARN()),
+			}
+		}
+		identity = &IAMCallerIdentity{
+			AccountID: cluster.AWS().AccountID(),
+			Partition: state.Partition.Value,
+		}
+		prefix := cluster.AWS().STS().OperatorRolePrefix()
+		if (state.OperatorRolePrefix.Unknown || state.OperatorRolePrefix.Null) && prefix != "" {
+			state.OperatorRolePrefix = types.String{
+				Value: prefix,
+			}
+		}
+		version := cluster.Version().RawID()
+		if (state.OpenShiftVersion.Unknown || state.OpenShiftVersion.Null) && version != "" {
+			state.OpenShiftVersion = types.String{
+				Value: version,
+			}
+		}
+	}
+	if state.OperatorRolePrefix.Unknown || state.OperatorRolePrefix.Null ||
+		state.OperatorRolePrefix.Value == "" {
+		response.Diagnostics.AddError(
+			"Missing operator role prefix",
+			"The 'operator_role_prefix' attribute is required unless the 'cluster' "+
+				"attribute is given and the cluster has an operator role prefix",
+		)
+		return
+	}
+	if state.OpenShiftVersion.Unknown {
+		state.OpenShiftVersion = types.String{
+			Null: true,
+		}
+	}
+	if state.Partition.Unknown || state.Partition.Null || state.Partition.Value == "" {
+		state.Partition = types.String{
+			Value: defaultAWSPartition,
+		}
+	}
+
+	// Get the credential requests of the operators and keep only the ones that are
+	// compatible with the version:
+	requests, err := listSTSCredentialRequests(ctx, t.awsInquiries.STSCredentialRequests())
 	if err != nil {
-		t.logger.Error(ctx, "Failed to get operator list")
+		response.Diagnostics.AddError(
+			"Can't get STS credential requests",
+			fmt.Sprintf("Can't get STS credential requests: %v", err),
+		)
 		return
 	}
+	if !state.OpenShiftVersion.Null {
+		requests, err = filterSTSCredentialRequests(requests, state.OpenShiftVersion.Value)
+		if err != nil {
+			response.Diagnostics.AddAttributeError(
+				tftypes.NewAttributePath().WithAttributeName("openshift_version"),
+				"Invalid OpenShift version",
+				fmt.Sprintf(
+					"Can't check OpenShift version '%s': %v",
+					state.OpenShiftVersion.Value, err,
+				),
+			)
+			return
+		}
+	}
 
-	stsOperatorMap := make(map[string]*cmv1.STSOperator)
-	stsOperatorRolesList.Items().Each(func(stsCredentialRequest *cmv1.STSCredentialRequest) bool {
-		// TODO: check the MinVersion of the operator role
-		t.logger.Debug(ctx, "Operator name: %s, namespace %s, service account %s",
-			stsCredentialRequest.Operator().Name(),
-			stsCredentialRequest.Operator().Namespace(),
-			stsCredentialRequest.Operator().ServiceAccounts(),
+	// Get the permission policies of the operators:
+	policies, err := listSTSPolicies(ctx, t.awsInquiries.STSPolicies())
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't get STS policies",
+			fmt.Sprintf("Can't get STS policies: %v", err),
 		)
-		// The key can't be stsCredentialRequest.Operator().Name() because of constants between
-		// the name of `ingress_operator_cloud_credentials` and `cloud_network_config_controller_cloud_credentials`
-		// both of them includes the same Name `cloud-credentials` and it cannot be fixed
-		stsOperatorMap[stsCredentialRequest.Operator().Namespace()] = stsCredentialRequest.Operator()
-		return true
-	})
+		return
+	}
+	values := map[string]string{
+		"partition": state.Partition.Value,
+	}
 
 	accountRolePrefix := DefaultAccountRolePrefix
 	if !state.AccountRolePrefix.Unknown && !state.AccountRolePrefix.Null && state.AccountRolePrefix.Value != "" {
 		accountRolePrefix = state.AccountRolePrefix.Value
 	}
 
-	// TODO: use the sts.OperatorRolePrefix() if not empty
-	// There is a bug in the return value of sts.OperatorRolePrefix() - it's always empty string
-	for _, operatorRole := range stsOperatorMap {
+	// The names of the roles and the policies are shortened when they are too long, so we
+	// need to check that two operators don't end up with the same names:
+	roleNames, policyNames := operatorIAMNames(
+		state.OperatorRolePrefix.Value, accountRolePrefix, requests,
+	)
+	usedRoleNames := map[string]string{}
+	usedPolicyNames := map[string]string{}
+	state.OperatorIAMRoles = []*OperatorIAMRole{}
+	for i, stsCredentialRequest := range requests {
+		operatorRole := stsCredentialRequest.Operator()
+		t.logger.Debug(ctx, "Operator name: %s, namespace %s, service account %s",
+			operatorRole.Name(),
+			operatorRole.Namespace(),
+			operatorRole.ServiceAccounts(),
+		)
 		r := OperatorIAMRole{
 			Name: types.String{
 				Value: operatorRole.Name(),
@@ -164,12 +317,59 @@ func (t *RosaOperatorRolesDataSource) Read(ctx context.Context, request tfsdk.Re
 				Value: operatorRole.Namespace(),
 			},
 			RoleName: types.String{
-				Value: getRoleName(state.OperatorRolePrefix.Value, operatorRole),
+				Value: roleNames[i],
 			},
 			PolicyName: types.String{
-				Value: getPolicyName(accountRolePrefix, operatorRole.Namespace(), operatorRole.Name()),
+				Value: policyNames[i],
 			},
-			ServiceAccounts: buildServiceAccountsArray(stsOperatorMap[operatorRole.Namespace()].ServiceAccounts(), operatorRole.Namespace()),
+			ServiceAccounts: buildServiceAccountsArray(operatorRole.ServiceAccounts(), operatorRole.Namespace()),
+			AssumeRolePolicy: types.String{
+				Null: true,
+			},
+		}
+		err = checkNameCollision(usedRoleNames, r.RoleName.Value, stsCredentialRequest)
+		if err == nil {
+			err = checkNameCollision(usedPolicyNames, r.PolicyName.Value, stsCredentialRequest)
+		}
+		if err != nil {
+			response.Diagnostics.AddError("Name collision", err.Error())
+			return
+		}
+		policyID := operatorPolicyID(stsCredentialRequest)
+		policy, ok := policies[policyID]
+		if !ok {
+			response.Diagnostics.AddError(
+				"Can't get operator role policy",
+				fmt.Sprintf(
+					"Can't get policy of operator '%s' in namespace '%s': OCM "+
+						"didn't return the '%s' policy",
+					operatorRole.Name(), operatorRole.Namespace(), policyID,
+				),
+			)
+			return
+		}
+		r.PolicyDocument = types.String{
+			Value: interpolatePolicyDocument(policy, values),
+		}
+		if identity != nil {
+			var serviceAccounts []string
+			for _, serviceAccount := range r.ServiceAccounts.Elems {
+				serviceAccounts = append(serviceAccounts, serviceAccount.(types.String).Value)
+			}
+			trust, err := buildOperatorTrustPolicy(identity, oidcEndpointURL, serviceAccounts)
+			if err != nil {
+				response.Diagnostics.AddError(
+					"Can't build trust policy",
+					fmt.Sprintf(
+						"Can't build trust policy for operator role '%s': %v",
+						r.RoleName.Value, err,
+					),
+				)
+				return
+			}
+			r.AssumeRolePolicy = types.String{
+				Value: trust,
+			}
 		}
 		state.OperatorIAMRoles = append(state.OperatorIAMRoles, &r)
 	}
@@ -179,22 +379,86 @@ func (t *RosaOperatorRolesDataSource) Read(ctx context.Context, request tfsdk.Re
 	response.Diagnostics.Append(diags...)
 }
 
-// TODO: should be in a separate repo
-func getRoleName(rolePrefix string, operatorRole *cmv1.STSOperator) string {
-	role := fmt.Sprintf("%s-%s-%s", rolePrefix, operatorRole.Namespace(), operatorRole.Name())
-	if len(role) > 64 {
-		role = role[0:64]
+// operatorIAMNames calculates the names of the roles and of the permission policies of the
+// operators of the given credential requests, in the same order as the requests.
+func operatorIAMNames(rolePrefix, policyPrefix string,
+	requests []*cmv1.STSCredentialRequest) (roleNames, policyNames []string) {
+	roleNames = make([]string, len(requests))
+	policyNames = make([]string, len(requests))
+	for i, request := range requests {
+		operator := request.Operator()
+		roleNames[i] = fmt.Sprintf(
+			"%s-%s-%s", rolePrefix, operator.Namespace(), operator.Name(),
+		)
+		policyNames[i] = fmt.Sprintf(
+			"%s-%s-%s", policyPrefix, operator.Namespace(), operator.Name(),
+		)
 	}
-	return role
+	roleNames = shortenIAMNames(roleNames)
+	policyNames = shortenIAMNames(policyNames)
+	return
 }
 
-// TODO: should be in a separate repo
-func getPolicyName(prefix string, namespace string, name string) string {
-	policy := fmt.Sprintf("%s-%s-%s", prefix, namespace, name)
-	if len(policy) > 64 {
-		policy = policy[0:64]
+// shortenIAMNames shortens the names that are longer than the 64 characters that IAM allows.
+// Names are truncated the same way that the `rosa` command line tool does, so that roles and
+// policies created by either tool get the same names. But when different names would end up
+// with the same truncated name the end of each of them is replaced with a hash of the complete
+// name instead, so that the result is still deterministic but different for each name.
+func shortenIAMNames(names []string) []string {
+	// Find the different names that end up with each truncated name:
+	groups := map[string]map[string]bool{}
+	for _, name := range names {
+		truncated := name
+		if len(truncated) > maxIAMNameLength {
+			truncated = truncated[0:maxIAMNameLength]
+		}
+		group := groups[truncated]
+		if group == nil {
+			group = map[string]bool{}
+			groups[truncated] = group
+		}
+		group[name] = true
+	}
+
+	// Shorten the names:
+	result := make([]string, len(names))
+	for i, name := range names {
+		switch {
+		case len(name) <= maxIAMNameLength:
+			result[i] = name
+		case len(groups[name[0:maxIAMNameLength]]) == 1:
+			result[i] = name[0:maxIAMNameLength]
+		default:
+			result[i] = hashIAMName(name)
+		}
+	}
+	return result
+}
+
+// hashIAMName shortens the given name replacing the end with a hash of the complete name.
+func hashIAMName(name string) string {
+	hash := sha256.Sum256([]byte(name))
+	suffix := hex.EncodeToString(hash[:])[0:iamNameHashLength]
+	return fmt.Sprintf("%s-%s", name[0:maxIAMNameLength-iamNameHashLength-1], suffix)
+}
+
+// checkNameCollision checks that the given name hasn't already been used for a different
+// operator, and then saves it in the names map.
+func checkNameCollision(names map[string]string, name string,
+	request *cmv1.STSCredentialRequest) error {
+	operator := fmt.Sprintf(
+		"%s/%s",
+		request.Operator().Namespace(), request.Operator().Name(),
+	)
+	previous, ok := names[name]
+	if ok && previous != operator {
+		return fmt.Errorf(
+			"operators '%s' and '%s' would use the same name '%s', use a shorter prefix",
+			previous, operator, name,
+		)
 	}
-	return policy
+	names[name] = operator
+	return nil
 }
 
 func buildServiceAccountsArray(serviceAccountArr []string, operatorNamespace string) types.List {
@@ -0,0 +1,141 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"  // nolint
+	. "github.com/onsi/ginkgo/v2/dsl/table" // nolint
+	. "github.com/onsi/gomega"              // nolint
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var _ = Describe("Operator role names", func() {
+	newRequest := func(namespace, name, minVersion string) *cmv1.STSCredentialRequest {
+		request, err := cmv1.NewSTSCredentialRequest().
+			Name(strings.ReplaceAll(namespace+"_"+name, "-", "_")).
+			Operator(
+				cmv1.NewSTSOperator().
+					Namespace(namespace).
+					Name(name).
+					MinVersion(minVersion),
+			).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		return request
+	}
+
+	It("Doesn't change short names", func() {
+		Expect(shortenIAMNames([]string{
+			"my-prefix-openshift-image-registry-installer-cloud",
+		})).To(Equal([]string{
+			"my-prefix-openshift-image-registry-installer-cloud",
+		}))
+	})
+
+	It("Truncates long names", func() {
+		Expect(shortenIAMNames([]string{
+			"ManagedOpenShift-openshift-cluster-csi-drivers-ebs-cloud-credentials",
+		})).To(Equal([]string{
+			"ManagedOpenShift-openshift-cluster-csi-drivers-ebs-cloud-credent",
+		}))
+	})
+
+	It("Adds a hash to long names that would collide after truncation", func() {
+		first := "ManagedOpenShift-openshift-cluster-csi-drivers-ebs-cloud-credentials-first"
+		second := "ManagedOpenShift-openshift-cluster-csi-drivers-ebs-cloud-credentials-second"
+		names := shortenIAMNames([]string{first, second})
+		Expect(names).To(HaveLen(2))
+		Expect(names[0]).To(HaveLen(maxIAMNameLength))
+		Expect(names[1]).To(HaveLen(maxIAMNameLength))
+		Expect(names[0]).To(HavePrefix("ManagedOpenShift-openshift-cluster-csi-drivers-ebs-clou-"))
+		Expect(names[1]).To(HavePrefix("ManagedOpenShift-openshift-cluster-csi-drivers-ebs-clou-"))
+		Expect(names[0]).ToNot(Equal(names[1]))
+		Expect(shortenIAMNames([]string{second, first})).To(Equal([]string{
+			names[1], names[0],
+		}))
+	})
+
+	It("Doesn't add a hash to repeated names", func() {
+		name := "ManagedOpenShift-openshift-cluster-csi-drivers-ebs-cloud-credentials"
+		Expect(shortenIAMNames([]string{name, name})).To(Equal([]string{
+			"ManagedOpenShift-openshift-cluster-csi-drivers-ebs-cloud-credent",
+			"ManagedOpenShift-openshift-cluster-csi-drivers-ebs-cloud-credent",
+		}))
+	})
+
+	It("Detects collisions", func() {
+		names := map[string]string{}
+		first := newRequest("my-namespace", "my-operator", "")
+		second := newRequest("your-namespace", "your-operator", "")
+		Expect(checkNameCollision(names, "my-name", first)).To(Succeed())
+		Expect(checkNameCollision(names, "my-name", first)).To(Succeed())
+		err := checkNameCollision(names, "my-name", second)
+		Expect(err).To(MatchError(ContainSubstring(
+			"operators 'my-namespace/my-operator' and " +
+				"'your-namespace/your-operator' would use the same name 'my-name'",
+		)))
+	})
+
+	DescribeTable("Filters credential requests by version",
+		func(version string, expected ...string) {
+			requests := []*cmv1.STSCredentialRequest{
+				newRequest("openshift-ingress-operator", "cloud-credentials", ""),
+				newRequest("openshift-cloud-network-config-controller", "cloud-credentials", "4.10"),
+				newRequest("openshift-cloud-credential-operator", "cloud-credential-operator-iam-ro-creds", "4.11.0"),
+			}
+			result, err := filterSTSCredentialRequests(requests, version)
+			Expect(err).ToNot(HaveOccurred())
+			var namespaces []string
+			for _, request := range result {
+				namespaces = append(namespaces, request.Operator().Namespace())
+			}
+			Expect(namespaces).To(Equal(expected))
+		},
+		Entry(
+			"Old version",
+			"4.9.12",
+			"openshift-ingress-operator",
+		),
+		Entry(
+			"Same as minimum",
+			"4.10.0",
+			"openshift-ingress-operator",
+			"openshift-cloud-network-config-controller",
+		),
+		Entry(
+			"Version identifier",
+			"openshift-v4.11.2",
+			"openshift-ingress-operator",
+			"openshift-cloud-network-config-controller",
+			"openshift-cloud-credential-operator",
+		),
+		Entry(
+			"Release candidate",
+			"4.11.0-rc.1",
+			"openshift-ingress-operator",
+			"openshift-cloud-network-config-controller",
+			"openshift-cloud-credential-operator",
+		),
+	)
+
+	It("Rejects invalid versions", func() {
+		_, err := filterSTSCredentialRequests(nil, "junk")
+		Expect(err).To(HaveOccurred())
+	})
+})
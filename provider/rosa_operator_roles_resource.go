@@ -0,0 +1,625 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+type RosaOperatorRolesResourceType struct {
+}
+
+type RosaOperatorRolesResource struct {
+	logger       logging.Logger
+	awsInquiries *cmv1.AWSInquiriesClient
+	iamClient    IAMClient
+}
+
+func (t *RosaOperatorRolesResourceType) GetSchema(ctx context.Context) (result tfsdk.Schema,
+	diags diag.Diagnostics) {
+	result = tfsdk.Schema{
+		Description: "Operator roles of a ROSA cluster with STS. The trust and permission " +
+			"policies are the ones required by OCM, and the roles are created with the " +
+			"AWS credentials of the environment.",
+		Attributes: map[string]tfsdk.Attribute{
+			"id": {
+				Description: "Unique identifier of the operator roles, the same " +
+					"as the operator role prefix.",
+				Type:     types.StringType,
+				Computed: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"cluster": {
+				Description: "Identifier of the cluster, added as the " +
+					"'rosa_cluster_id' tag of the roles.",
+				Type:     types.StringType,
+				Optional: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"operator_role_prefix": {
+				Description: "Prefix of the names of the roles.",
+				Type:        types.StringType,
+				Required:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"account_role_prefix": {
+				Description: "Prefix of the names of the policies. Default value " +
+					"is '" + DefaultAccountRolePrefix + "'.",
+				Type:     types.StringType,
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"oidc_endpoint_url": {
+				Description: "URL of the OIDC endpoint of the cluster, for example " +
+					"the 'sts.oidc_endpoint_url' attribute of the " +
+					"'ocm_cluster_rosa_classic' resource.",
+				Type:     types.StringType,
+				Required: true,
+			},
+			"path": {
+				Description: "Path of the roles. Default value is '/'.",
+				Type:        types.StringType,
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"permissions_boundary": {
+				Description: "ARN of the policy used as permissions boundary " +
+					"of the roles.",
+				Type:     types.StringType,
+				Optional: true,
+				PlanModifiers: []tfsdk.AttributePlanModifier{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"tags": {
+				Description: "Additional tags added to the roles.",
+				Type: types.MapType{
+					ElemType: types.StringType,
+				},
+				Optional: true,
+			},
+			"operator_iam_roles": {
+				Description: "Operator IAM roles.",
+				Attributes: tfsdk.ListNestedAttributes(
+					t.itemAttributes(),
+					tfsdk.ListNestedAttributesOptions{},
+				),
+				Computed: true,
+			},
+		},
+	}
+	return
+}
+
+func (t *RosaOperatorRolesResourceType) itemAttributes() map[string]tfsdk.Attribute {
+	return map[string]tfsdk.Attribute{
+		"operator_name": {
+			Description: "Name of the operator.",
+			Type:        types.StringType,
+			Computed:    true,
+		},
+		"operator_namespace": {
+			Description: "Kubernetes namespace of the operator.",
+			Type:        types.StringType,
+			Computed:    true,
+		},
+		"role_name": {
+			Description: "Name of the role.",
+			Type:        types.StringType,
+			Computed:    true,
+		},
+		"role_arn": {
+			Description: "ARN of the role.",
+			Type:        types.StringType,
+			Computed:    true,
+		},
+		"policy_name": {
+			Description: "Name of the inline permission policy of the role.",
+			Type:        types.StringType,
+			Computed:    true,
+		},
+		"service_accounts": {
+			Description: "Service accounts that can assume the role.",
+			Type: types.ListType{
+				ElemType: types.StringType,
+			},
+			Computed: true,
+		},
+	}
+}
+
+func (t *RosaOperatorRolesResourceType) NewResource(ctx context.Context,
+	p tfsdk.Provider) (result tfsdk.Resource, diags diag.Diagnostics) {
+	// Cast the provider interface to the specific implementation:
+	parent := p.(*Provider)
+
+	// Get the AWS inquiries client:
+	awsInquiries := parent.connection.ClustersMgmt().V1().AWSInquiries()
+
+	// Create the resource:
+	result = &RosaOperatorRolesResource{
+		logger:       parent.logger,
+		awsInquiries: awsInquiries,
+		iamClient:    NewDefaultIAMClient(),
+	}
+	return
+}
+
+func (r *RosaOperatorRolesResource) Create(ctx context.Context,
+	request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	// Get the plan:
+	state := &RosaOperatorRolesResourceState{}
+	diags := request.Plan.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Set the default values:
+	if state.AccountRolePrefix.Unknown || state.AccountRolePrefix.Null {
+		state.AccountRolePrefix = types.String{
+			Value: DefaultAccountRolePrefix,
+		}
+	}
+	if state.Path.Unknown || state.Path.Null {
+		state.Path = types.String{
+			Value: "/",
+		}
+	}
+
+	// Create the roles:
+	r.apply(ctx, state, true, &response.Diagnostics)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Save the state:
+	state.ID = state.OperatorRolePrefix
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *RosaOperatorRolesResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest,
+	response *tfsdk.ReadResourceResponse) {
+	// Get the current state:
+	state := &RosaOperatorRolesResourceState{}
+	diags := request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Get the roles. If any of them has been deleted then we remove the resource from the
+	// state, so that all of them are created again.
+	for _, item := range state.OperatorIAMRoles {
+		role, err := r.iamClient.GetRole(ctx, item.RoleName.Value)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't get operator role",
+				fmt.Sprintf(
+					"Can't get operator role '%s': %v",
+					item.RoleName.Value, err,
+				),
+			)
+			return
+		}
+		if role == nil {
+			r.logger.Warn(
+				ctx,
+				"Operator role '%s' doesn't exist, removing from state",
+				item.RoleName.Value,
+			)
+			response.State.RemoveResource(ctx)
+			return
+		}
+		item.RoleARN = types.String{
+			Value: role.ARN,
+		}
+	}
+
+	// Save the state:
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+// ModifyPlan compares the existing roles with the ones that would be applied, so that changes
+// made outside of Terraform, or new policies required by OCM, result in an update.
+func (r *RosaOperatorRolesResource) ModifyPlan(ctx context.Context,
+	request tfsdk.ModifyResourcePlanRequest, response *tfsdk.ModifyResourcePlanResponse) {
+	// Nothing to compare when the roles are being created or deleted:
+	if request.State.Raw.IsNull() || request.Plan.Raw.IsNull() {
+		return
+	}
+
+	// The list of roles of the plan may be unknown, so start with the state and take from the
+	// plan the attributes that can be updated:
+	state := &RosaOperatorRolesResourceState{}
+	diags := request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+	diags = request.Plan.GetAttribute(
+		ctx,
+		tftypes.NewAttributePath().WithAttributeName("oidc_endpoint_url"),
+		&state.OIDCEndpointURL,
+	)
+	response.Diagnostics.Append(diags...)
+	diags = request.Plan.GetAttribute(
+		ctx,
+		tftypes.NewAttributePath().WithAttributeName("tags"),
+		&state.Tags,
+	)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() || state.OIDCEndpointURL.Unknown || state.Tags.Unknown {
+		return
+	}
+
+	// Compare the roles:
+	requests, policies := r.policies(ctx, &response.Diagnostics)
+	if response.Diagnostics.HasError() {
+		return
+	}
+	changed, err := operatorRolesChanged(ctx, r.iamClient, requests, policies, state)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can't get operator roles",
+			fmt.Sprintf(
+				"Can't get operator roles with prefix '%s': %v",
+				state.OperatorRolePrefix.Value, err,
+			),
+		)
+		return
+	}
+	if !changed {
+		return
+	}
+
+	// The roles will be updated, and their details are only known after that:
+	path := tftypes.NewAttributePath().WithAttributeName("operator_iam_roles")
+	attrType, err := response.Plan.Schema.AttributeTypeAtPath(path)
+	if err != nil {
+		response.Diagnostics.AddError("Can't get type of operator roles", err.Error())
+		return
+	}
+	unknown, err := attrType.ValueFromTerraform(
+		ctx,
+		tftypes.NewValue(attrType.TerraformType(ctx), tftypes.UnknownValue),
+	)
+	if err != nil {
+		response.Diagnostics.AddError("Can't create unknown operator roles", err.Error())
+		return
+	}
+	diags = response.Plan.SetAttribute(ctx, path, unknown)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *RosaOperatorRolesResource) Update(ctx context.Context,
+	request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	var diags diag.Diagnostics
+
+	// Get the state:
+	state := &RosaOperatorRolesResourceState{}
+	diags = request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Get the plan:
+	plan := &RosaOperatorRolesResourceState{}
+	diags = request.Plan.Get(ctx, plan)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// The prefixes, the path and the permissions boundary can't be changed, changing them
+	// forces the replacement of the resource. The list of roles may change if OCM now requires
+	// different operators, so we start from the roles in the state and remove the ones that
+	// are no longer needed.
+	plan.ID = state.ID
+	plan.AccountRolePrefix = state.AccountRolePrefix
+	plan.Path = state.Path
+	plan.OperatorIAMRoles = state.OperatorIAMRoles
+	r.apply(ctx, plan, false, &response.Diagnostics)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Save the state:
+	diags = response.State.Set(ctx, plan)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *RosaOperatorRolesResource) Delete(ctx context.Context,
+	request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	// Get the state:
+	state := &RosaOperatorRolesResourceState{}
+	diags := request.State.Get(ctx, state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// Delete the roles:
+	for _, item := range state.OperatorIAMRoles {
+		err := deleteIAMRole(ctx, r.iamClient, item.RoleName.Value, item.PolicyName.Value)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can't delete operator role",
+				fmt.Sprintf(
+					"Can't delete operator role '%s': %v",
+					item.RoleName.Value, err,
+				),
+			)
+			return
+		}
+	}
+
+	// Remove the state:
+	response.State.RemoveResource(ctx)
+}
+
+func (r *RosaOperatorRolesResource) ImportState(ctx context.Context,
+	request tfsdk.ImportResourceStateRequest, response *tfsdk.ImportResourceStateResponse) {
+	// The state contains the list of roles and the OIDC endpoint, and they can't be calculated
+	// from the operator role prefix alone:
+	tfsdk.ResourceImportStateNotImplemented(
+		ctx,
+		"Operator roles can't be imported, create the resource with the same "+
+			"operator role prefix instead, the existing roles that have the "+
+			"'rosa_role_prefix' tag with that prefix will be updated.",
+		response,
+	)
+}
+
+// apply gets the credential requests and the policies from OCM and then creates or updates the
+// operator roles.
+func (r *RosaOperatorRolesResource) apply(ctx context.Context,
+	state *RosaOperatorRolesResourceState, create bool, diags *diag.Diagnostics) {
+	requests, policies := r.policies(ctx, diags)
+	if diags.HasError() {
+		return
+	}
+	err := applyOperatorRoles(ctx, r.iamClient, requests, policies, state, create)
+	if err != nil {
+		diags.AddError(
+			"Can't create operator roles",
+			fmt.Sprintf(
+				"Can't create operator roles with prefix '%s': %v",
+				state.OperatorRolePrefix.Value, err,
+			),
+		)
+	}
+}
+
+// policies gets the credential requests and the policies from OCM.
+func (r *RosaOperatorRolesResource) policies(ctx context.Context,
+	diags *diag.Diagnostics) (requests []*cmv1.STSCredentialRequest,
+	policies map[string]string) {
+	requests, err := listSTSCredentialRequests(ctx, r.awsInquiries.STSCredentialRequests())
+	if err != nil {
+		diags.AddError(
+			"Can't get STS credential requests",
+			fmt.Sprintf("Can't get STS credential requests: %v", err),
+		)
+		return
+	}
+	policies, err = listSTSPolicies(ctx, r.awsInquiries.STSPolicies())
+	if err != nil {
+		diags.AddError(
+			"Can't get STS policies",
+			fmt.Sprintf("Can't get STS policies: %v", err),
+		)
+		return
+	}
+	return
+}
+
+// applyOperatorRoles creates or updates the operator roles needed by the given credential
+// requests, deletes the roles in the state that are no longer needed, and saves the resulting
+// roles to the state. The create flag indicates if the roles are being created, see the
+// ensureIAMRole function for details.
+func applyOperatorRoles(ctx context.Context, client IAMClient,
+	requests []*cmv1.STSCredentialRequest, policies map[string]string,
+	state *RosaOperatorRolesResourceState, create bool) error {
+	identity, err := client.GetCallerIdentity(ctx)
+	if err != nil {
+		return fmt.Errorf("can't get identity of the AWS account: %v", err)
+	}
+	specs, items, err := operatorRoleSpecs(identity, requests, policies, state)
+	if err != nil {
+		return err
+	}
+	names := map[string]bool{}
+	for i, spec := range specs {
+		arn, err := ensureIAMRole(ctx, client, spec, create)
+		if err != nil {
+			return fmt.Errorf("can't create role '%s': %v", spec.Role.Name, err)
+		}
+		items[i].RoleARN = types.String{
+			Value: arn,
+		}
+		names[spec.Role.Name] = true
+	}
+
+	// Delete the roles that were created before but that are no longer needed:
+	for _, item := range state.OperatorIAMRoles {
+		if names[item.RoleName.Value] {
+			continue
+		}
+		err = deleteIAMRole(ctx, client, item.RoleName.Value, item.PolicyName.Value)
+		if err != nil {
+			return fmt.Errorf("can't delete role '%s': %v", item.RoleName.Value, err)
+		}
+	}
+
+	state.OperatorIAMRoles = items
+	return nil
+}
+
+// operatorRolesChanged checks if the roles in the state aren't the ones needed by the given
+// credential requests, or if any of them has a trust policy, permission policy or tags different
+// to the ones that would be applied.
+func operatorRolesChanged(ctx context.Context, client IAMClient,
+	requests []*cmv1.STSCredentialRequest, policies map[string]string,
+	state *RosaOperatorRolesResourceState) (changed bool, err error) {
+	identity, err := client.GetCallerIdentity(ctx)
+	if err != nil {
+		return
+	}
+	specs, _, err := operatorRoleSpecs(identity, requests, policies, state)
+	if err != nil {
+		return
+	}
+	if len(specs) != len(state.OperatorIAMRoles) {
+		changed = true
+		return
+	}
+	for i, spec := range specs {
+		if spec.Role.Name != state.OperatorIAMRoles[i].RoleName.Value {
+			changed = true
+			return
+		}
+		changed, err = iamRoleChanged(ctx, client, spec)
+		if err != nil || changed {
+			return
+		}
+	}
+	return
+}
+
+// operatorRoleSpecs calculates the desired roles and permission policies for the operators of
+// the given credential requests. It also returns the corresponding items of the state, without
+// the ARNs of the roles, as they are only known once the roles have been created.
+func operatorRoleSpecs(identity *IAMCallerIdentity, requests []*cmv1.STSCredentialRequest,
+	policies map[string]string, state *RosaOperatorRolesResourceState) (specs []*iamRoleSpec,
+	items []*RosaOperatorRole, err error) {
+	values := map[string]string{
+		"partition": identity.Partition,
+	}
+	roleNames, policyNames := operatorIAMNames(
+		state.OperatorRolePrefix.Value, state.AccountRolePrefix.Value, requests,
+	)
+	usedRoleNames := map[string]string{}
+	usedPolicyNames := map[string]string{}
+	for i, request := range requests {
+		operator := request.Operator()
+		policyID := operatorPolicyID(request)
+		policy, ok := policies[policyID]
+		if !ok {
+			err = fmt.Errorf("OCM didn't return the '%s' policy", policyID)
+			return
+		}
+		serviceAccounts := make([]string, len(operator.ServiceAccounts()))
+		for i, serviceAccount := range operator.ServiceAccounts() {
+			serviceAccounts[i] = fmt.Sprintf(
+				serviceAccountFmt, operator.Namespace(), serviceAccount,
+			)
+		}
+		var trust string
+		trust, err = buildOperatorTrustPolicy(
+			identity, state.OIDCEndpointURL.Value, serviceAccounts,
+		)
+		if err != nil {
+			return
+		}
+		tags := map[string]string{
+			"rosa_role_prefix":   state.OperatorRolePrefix.Value,
+			"operator_namespace": operator.Namespace(),
+			"operator_name":      operator.Name(),
+		}
+		if !state.Cluster.Unknown && !state.Cluster.Null {
+			tags["rosa_cluster_id"] = state.Cluster.Value
+		}
+		role := &IAMRole{
+			Name:             roleNames[i],
+			Path:             state.Path.Value,
+			AssumeRolePolicy: trust,
+			Tags:             roleTags(stringMap(state.Tags), tags),
+		}
+		if !state.PermissionsBoundary.Unknown && !state.PermissionsBoundary.Null {
+			role.PermissionsBoundary = state.PermissionsBoundary.Value
+		}
+		policyName := policyNames[i]
+		err = checkNameCollision(usedRoleNames, role.Name, request)
+		if err != nil {
+			return
+		}
+		err = checkNameCollision(usedPolicyNames, policyName, request)
+		if err != nil {
+			return
+		}
+		specs = append(specs, &iamRoleSpec{
+			Role:           role,
+			PolicyName:     policyName,
+			PolicyDocument: interpolatePolicyDocument(policy, values),
+		})
+		item := &RosaOperatorRole{
+			Name: types.String{
+				Value: operator.Name(),
+			},
+			Namespace: types.String{
+				Value: operator.Namespace(),
+			},
+			RoleName: types.String{
+				Value: role.Name,
+			},
+			PolicyName: types.String{
+				Value: policyName,
+			},
+			ServiceAccounts: types.List{
+				ElemType: types.StringType,
+				Elems:    []attr.Value{},
+			},
+		}
+		for _, serviceAccount := range serviceAccounts {
+			item.ServiceAccounts.Elems = append(
+				item.ServiceAccounts.Elems,
+				types.String{
+					Value: serviceAccount,
+				},
+			)
+		}
+		items = append(items, item)
+	}
+	return
+}
@@ -0,0 +1,42 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type RosaOperatorRolesResourceState struct {
+	ID                  types.String        `tfsdk:"id"`
+	Cluster             types.String        `tfsdk:"cluster"`
+	OperatorRolePrefix  types.String        `tfsdk:"operator_role_prefix"`
+	AccountRolePrefix   types.String        `tfsdk:"account_role_prefix"`
+	OIDCEndpointURL     types.String        `tfsdk:"oidc_endpoint_url"`
+	Path                types.String        `tfsdk:"path"`
+	PermissionsBoundary types.String        `tfsdk:"permissions_boundary"`
+	Tags                types.Map           `tfsdk:"tags"`
+	OperatorIAMRoles    []*RosaOperatorRole `tfsdk:"operator_iam_roles"`
+}
+
+type RosaOperatorRole struct {
+	Name            types.String `tfsdk:"operator_name"`
+	Namespace       types.String `tfsdk:"operator_namespace"`
+	RoleName        types.String `tfsdk:"role_name"`
+	RoleARN         types.String `tfsdk:"role_arn"`
+	PolicyName      types.String `tfsdk:"policy_name"`
+	ServiceAccounts types.List   `tfsdk:"service_accounts"`
+}
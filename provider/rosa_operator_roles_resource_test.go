@@ -0,0 +1,190 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	. "github.com/onsi/ginkgo/v2/dsl/core" // nolint
+	. "github.com/onsi/gomega"             // nolint
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var _ = Describe("Operator roles", func() {
+	var ctx context.Context
+	var client *FakeIAMClient
+	var requests []*cmv1.STSCredentialRequest
+	var policies map[string]string
+
+	newRequest := func(name, namespace, operator string,
+		serviceAccounts ...string) *cmv1.STSCredentialRequest {
+		request, err := cmv1.NewSTSCredentialRequest().
+			Name(name).
+			Operator(
+				cmv1.NewSTSOperator().
+					Name(operator).
+					Namespace(namespace).
+					ServiceAccounts(serviceAccounts...),
+			).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		return request
+	}
+
+	newState := func() *RosaOperatorRolesResourceState {
+		return &RosaOperatorRolesResourceState{
+			Cluster: types.String{
+				Value: "1a2b3c",
+			},
+			OperatorRolePrefix: types.String{
+				Value: "my-cluster",
+			},
+			AccountRolePrefix: types.String{
+				Value: "my-prefix",
+			},
+			OIDCEndpointURL: types.String{
+				Value: "rh-oidc.s3.us-east-1.amazonaws.com/1a2b3c",
+			},
+			Path: types.String{
+				Value: "/",
+			},
+			PermissionsBoundary: types.String{
+				Null: true,
+			},
+			Tags: types.Map{
+				ElemType: types.StringType,
+				Null:     true,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		client = NewFakeIAMClient()
+		requests = []*cmv1.STSCredentialRequest{
+			newRequest(
+				"cluster_csi_drivers_ebs_cloud_credentials",
+				"openshift-cluster-csi-drivers", "ebs-cloud-credentials",
+				"aws-ebs-csi-driver-operator", "aws-ebs-csi-driver-controller-sa",
+			),
+			newRequest(
+				"ingress_operator_cloud_credentials",
+				"openshift-ingress-operator", "cloud-credentials",
+				"ingress-operator",
+			),
+		}
+		policies = map[string]string{
+			"openshift_ingress_operator_cloud_credentials_policy":        `{"Ingress": "%{partition}"}`,
+			"openshift_cluster_csi_drivers_ebs_cloud_credentials_policy": `{"EBS": "%{partition}"}`,
+		}
+	})
+
+	It("Creates the roles", func() {
+		state := newState()
+		err := applyOperatorRoles(ctx, client, requests, policies, state, true)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(client.Roles).To(HaveLen(2))
+		role := client.Roles["my-cluster-openshift-ingress-operator-cloud-credentials"]
+		Expect(role).ToNot(BeNil())
+		Expect(role.Tags).To(Equal(map[string]string{
+			"red-hat-managed":    "true",
+			"rosa_role_prefix":   "my-cluster",
+			"rosa_cluster_id":    "1a2b3c",
+			"operator_namespace": "openshift-ingress-operator",
+			"operator_name":      "cloud-credentials",
+		}))
+		Expect(role.AssumeRolePolicy).To(ContainSubstring(
+			"arn:aws:iam::123456789012:oidc-provider/rh-oidc.s3.us-east-1.amazonaws.com/1a2b3c",
+		))
+		Expect(role.AssumeRolePolicy).To(ContainSubstring(
+			"system:serviceaccount:openshift-ingress-operator:ingress-operator",
+		))
+		Expect(client.Policies[role.Name]).To(Equal(map[string]string{
+			"my-prefix-openshift-ingress-operator-cloud-credentials": `{"Ingress": "aws"}`,
+		}))
+
+		Expect(state.OperatorIAMRoles).To(HaveLen(2))
+		item := state.OperatorIAMRoles[0]
+		Expect(item.Namespace.Value).To(Equal("openshift-cluster-csi-drivers"))
+		Expect(item.RoleName.Value).To(Equal(
+			"my-cluster-openshift-cluster-csi-drivers-ebs-cloud-credentials",
+		))
+		Expect(item.RoleARN.Value).To(Equal(
+			"arn:aws:iam::123456789012:role/" +
+				"my-cluster-openshift-cluster-csi-drivers-ebs-cloud-credentials",
+		))
+		Expect(item.ServiceAccounts.Elems).To(Equal([]attr.Value{
+			types.String{
+				Value: "system:serviceaccount:openshift-cluster-csi-drivers:" +
+					"aws-ebs-csi-driver-operator",
+			},
+			types.String{
+				Value: "system:serviceaccount:openshift-cluster-csi-drivers:" +
+					"aws-ebs-csi-driver-controller-sa",
+			},
+		}))
+	})
+
+	It("Deletes the roles that are no longer needed", func() {
+		state := newState()
+		err := applyOperatorRoles(ctx, client, requests, policies, state, true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.Roles).To(HaveLen(2))
+
+		err = applyOperatorRoles(ctx, client, requests[1:], policies, state, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.Roles).To(HaveLen(1))
+		Expect(client.Roles).ToNot(HaveKey(
+			"my-cluster-openshift-cluster-csi-drivers-ebs-cloud-credentials",
+		))
+		Expect(state.OperatorIAMRoles).To(HaveLen(1))
+	})
+
+	It("Detects changes made outside of Terraform", func() {
+		state := newState()
+		err := applyOperatorRoles(ctx, client, requests, policies, state, true)
+		Expect(err).ToNot(HaveOccurred())
+		changed, err := operatorRolesChanged(ctx, client, requests, policies, state)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeFalse())
+
+		// Change the trust policy:
+		name := "my-cluster-openshift-ingress-operator-cloud-credentials"
+		client.Roles[name].AssumeRolePolicy = `{}`
+		changed, err = operatorRolesChanged(ctx, client, requests, policies, state)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+
+		// Restore it and remove one of the operators:
+		err = applyOperatorRoles(ctx, client, requests, policies, state, false)
+		Expect(err).ToNot(HaveOccurred())
+		changed, err = operatorRolesChanged(ctx, client, requests[1:], policies, state)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+	})
+
+	It("Fails if a policy is missing", func() {
+		delete(policies, "openshift_ingress_operator_cloud_credentials_policy")
+		err := applyOperatorRoles(ctx, client, requests, policies, newState(), true)
+		Expect(err).To(MatchError(ContainSubstring(
+			"openshift_ingress_operator_cloud_credentials_policy",
+		)))
+	})
+})
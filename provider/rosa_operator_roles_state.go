@@ -21,13 +21,18 @@ import "github.com/hashicorp/terraform-plugin-framework/types"
 type RosaOperatorRolesState struct {
 	OperatorRolePrefix types.String       `tfsdk:"operator_role_prefix"`
 	AccountRolePrefix  types.String       `tfsdk:"account_role_prefix"`
+	OpenShiftVersion   types.String       `tfsdk:"openshift_version"`
+	Partition          types.String       `tfsdk:"partition"`
+	Cluster            types.String       `tfsdk:"cluster"`
 	OperatorIAMRoles   []*OperatorIAMRole `tfsdk:"operator_iam_roles"`
 }
 
 type OperatorIAMRole struct {
-	Name            types.String `tfsdk:"operator_name"`
-	Namespace       types.String `tfsdk:"operator_namespace"`
-	RoleName        types.String `tfsdk:"role_name"`
-	PolicyName      types.String `tfsdk:"policy_name"`
-	ServiceAccounts types.List   `tfsdk:"service_accounts"`
+	Name             types.String `tfsdk:"operator_name"`
+	Namespace        types.String `tfsdk:"operator_namespace"`
+	RoleName         types.String `tfsdk:"role_name"`
+	PolicyName       types.String `tfsdk:"policy_name"`
+	ServiceAccounts  types.List   `tfsdk:"service_accounts"`
+	PolicyDocument   types.String `tfsdk:"policy_document"`
+	AssumeRolePolicy types.String `tfsdk:"assume_role_policy"`
 }
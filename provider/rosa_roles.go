@@ -0,0 +1,348 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	semver "github.com/hashicorp/go-version"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// defaultAWSPartition is the AWS partition used when it can't be obtained from the AWS
+// credentials or from an ARN.
+const defaultAWSPartition = "aws"
+
+// arnPartition returns the partition of the given ARN, or the default partition if the ARN
+// doesn't have one.
+func arnPartition(arn string) string {
+	// The ARN has the format 'arn:partition:service:region:account:resource':
+	parts := strings.Split(arn, ":")
+	if len(parts) > 1 && parts[1] != "" {
+		return parts[1]
+	}
+	return defaultAWSPartition
+}
+
+// accountRole describes one of the account roles that are needed to create ROSA clusters with
+// STS.
+type accountRole struct {
+	// Type is the value of the 'rosa_role_type' tag added to the role.
+	Type string
+
+	// Name is the suffix added to the account role prefix to calculate the name of the role.
+	Name string
+
+	// PolicyID is the prefix of the identifiers of the trust and permission policies returned
+	// by the STS policies inquiry.
+	PolicyID string
+}
+
+// accountRoles are the account roles that are needed to create ROSA clusters with STS.
+var accountRoles = []accountRole{
+	{
+		Type:     "installer",
+		Name:     "Installer-Role",
+		PolicyID: "sts_installer",
+	},
+	{
+		Type:     "support",
+		Name:     "Support-Role",
+		PolicyID: "sts_support",
+	},
+	{
+		Type:     "instance_controlplane",
+		Name:     "ControlPlane-Role",
+		PolicyID: "sts_instance_controlplane",
+	},
+	{
+		Type:     "instance_worker",
+		Name:     "Worker-Role",
+		PolicyID: "sts_instance_worker",
+	},
+}
+
+// jumpAccounts contains the identifiers of the AWS accounts that Red Hat uses to assume the
+// installer and support roles, indexed by the URL of the corresponding OCM environment.
+var jumpAccounts = map[string]string{
+	"https://api.openshift.com":             "710019948333",
+	"https://api.stage.openshift.com":       "644306948063",
+	"https://api.integration.openshift.com": "896164604406",
+}
+
+// jumpAccountID returns the identifier of the AWS account that Red Hat uses to assume the
+// installer and support roles for the OCM environment with the given URL. Unknown environments
+// are assumed to be production.
+func jumpAccountID(url string) string {
+	id, ok := jumpAccounts[strings.TrimSuffix(url, "/")]
+	if !ok {
+		id = jumpAccounts["https://api.openshift.com"]
+	}
+	return id
+}
+
+// accountRoleName returns the name of an account role.
+func accountRoleName(prefix string, role accountRole) string {
+	return fmt.Sprintf("%s-%s", prefix, role.Name)
+}
+
+// accountRolePolicyName returns the name of the inline permission policy of an account role.
+func accountRolePolicyName(prefix string, role accountRole) string {
+	return fmt.Sprintf("%s-%s-Policy", prefix, role.Name)
+}
+
+// accountRolePolicies returns the trust and permission policies of an account role, taken from
+// the policies returned by the STS policies inquiry and with the placeholders replaced.
+func accountRolePolicies(policies map[string]string, role accountRole,
+	values map[string]string) (trust, permission string, err error) {
+	trustID := role.PolicyID + "_trust_policy"
+	trust, ok := policies[trustID]
+	if !ok {
+		err = fmt.Errorf("OCM didn't return the '%s' policy", trustID)
+		return
+	}
+	permissionID := role.PolicyID + "_permission_policy"
+	permission, ok = policies[permissionID]
+	if !ok {
+		err = fmt.Errorf("OCM didn't return the '%s' policy", permissionID)
+		return
+	}
+	trust = interpolatePolicyDocument(trust, values)
+	permission = interpolatePolicyDocument(permission, values)
+	return
+}
+
+// operatorPolicyID returns the identifier of the permission policy of an operator role, as
+// returned by the STS policies inquiry.
+func operatorPolicyID(request *cmv1.STSCredentialRequest) string {
+	return fmt.Sprintf("openshift_%s_policy", request.Name())
+}
+
+// listSTSPolicies returns the policy documents returned by the STS policies inquiry, indexed by
+// policy identifier.
+func listSTSPolicies(ctx context.Context,
+	client *cmv1.AWSSTSPoliciesInquiryClient) (result map[string]string, err error) {
+	response, err := client.List().SendContext(ctx)
+	if err != nil {
+		return
+	}
+	result = map[string]string{}
+	response.Items().Each(func(policy *cmv1.AWSSTSPolicy) bool {
+		result[policy.ID()] = policy.Details()
+		return true
+	})
+	return
+}
+
+// listSTSCredentialRequests returns the credential requests of the operators that need IAM
+// roles, sorted by namespace and name.
+func listSTSCredentialRequests(ctx context.Context,
+	client *cmv1.STSCredentialRequestsInquiryClient) (result []*cmv1.STSCredentialRequest,
+	err error) {
+	response, err := client.List().SendContext(ctx)
+	if err != nil {
+		return
+	}
+	result = response.Items().Slice()
+	sort.Slice(result, func(i, j int) bool {
+		a := result[i].Operator()
+		b := result[j].Operator()
+		if a.Namespace() != b.Namespace() {
+			return a.Namespace() < b.Namespace()
+		}
+		return a.Name() < b.Name()
+	})
+	return
+}
+
+// filterSTSCredentialRequests returns the credential requests of the operators that are required
+// by the given OpenShift version, according to their minimum version.
+func filterSTSCredentialRequests(requests []*cmv1.STSCredentialRequest,
+	version string) (result []*cmv1.STSCredentialRequest, err error) {
+	current, err := semver.NewVersion(strings.Replace(version, "openshift-v", "", 1))
+	if err != nil {
+		return
+	}
+
+	// Pre-release versions like '4.11.0-rc.1' are compared as if they were the final version:
+	current = current.Core()
+	for _, request := range requests {
+		minVersion := request.Operator().MinVersion()
+		if minVersion != "" {
+			var min *semver.Version
+			min, err = semver.NewVersion(minVersion)
+			if err != nil {
+				result = nil
+				return
+			}
+			if current.LessThan(min) {
+				continue
+			}
+		}
+		result = append(result, request)
+	}
+	return
+}
+
+// interpolatePolicyDocument replaces the '%{name}' placeholders that appear in the policy
+// documents returned by the STS policies inquiry.
+func interpolatePolicyDocument(document string, values map[string]string) string {
+	for name, value := range values {
+		document = strings.ReplaceAll(document, fmt.Sprintf("%%{%s}", name), value)
+	}
+	return document
+}
+
+// buildOperatorTrustPolicy builds the trust policy that allows the given service accounts to
+// assume an operator role using the OIDC provider of the cluster.
+func buildOperatorTrustPolicy(identity *IAMCallerIdentity, oidcEndpointURL string,
+	serviceAccounts []string) (result string, err error) {
+	issuer := strings.TrimPrefix(oidcEndpointURL, "https://")
+	document := map[string]interface{}{
+		"Version": "2012-10-17",
+		"Statement": []interface{}{
+			map[string]interface{}{
+				"Effect": "Allow",
+				"Action": "sts:AssumeRoleWithWebIdentity",
+				"Principal": map[string]interface{}{
+					"Federated": fmt.Sprintf(
+						"arn:%s:iam::%s:oidc-provider/%s",
+						identity.Partition, identity.AccountID, issuer,
+					),
+				},
+				"Condition": map[string]interface{}{
+					"StringEquals": map[string]interface{}{
+						fmt.Sprintf("%s:sub", issuer): serviceAccounts,
+					},
+				},
+			},
+		},
+	}
+	data, err := json.Marshal(document)
+	if err != nil {
+		return
+	}
+	result = string(data)
+	return
+}
+
+// iamRoleSpec describes the desired state of a role and of its inline permission policy.
+type iamRoleSpec struct {
+	Role           *IAMRole
+	PolicyName     string
+	PolicyDocument string
+}
+
+// ensureIAMRole creates the role if it doesn't exist, or updates its trust policy and tags if it
+// does, and then puts the inline permission policy. It returns the ARN of the role. When the
+// create flag is true existing roles are only updated if they have the 'rosa_role_prefix' tag
+// of the new role, so that roles that belong to something else aren't taken over.
+func ensureIAMRole(ctx context.Context, client IAMClient, spec *iamRoleSpec,
+	create bool) (arn string, err error) {
+	role := spec.Role
+	current, err := client.GetRole(ctx, role.Name)
+	if err != nil {
+		return
+	}
+	if current == nil {
+		current, err = client.CreateRole(ctx, role)
+		if err != nil {
+			return
+		}
+	} else {
+		prefix := role.Tags["rosa_role_prefix"]
+		if create && current.Tags["rosa_role_prefix"] != prefix {
+			err = fmt.Errorf(
+				"role already exists and doesn't have the 'rosa_role_prefix' tag "+
+					"with value '%s', delete it or import it",
+				prefix,
+			)
+			return
+		}
+		err = client.UpdateRole(ctx, role)
+		if err != nil {
+			return
+		}
+	}
+	err = client.PutRolePolicy(ctx, role.Name, spec.PolicyName, spec.PolicyDocument)
+	if err != nil {
+		return
+	}
+	arn = current.ARN
+	return
+}
+
+// iamRoleChanged checks if the role doesn't exist, or if its trust policy, tags or inline
+// permission policy are different to the desired ones.
+func iamRoleChanged(ctx context.Context, client IAMClient, spec *iamRoleSpec) (changed bool,
+	err error) {
+	current, err := client.GetRole(ctx, spec.Role.Name)
+	if err != nil {
+		return
+	}
+	if current == nil ||
+		!jsonEqual(current.AssumeRolePolicy, spec.Role.AssumeRolePolicy) ||
+		!reflect.DeepEqual(current.Tags, spec.Role.Tags) {
+		changed = true
+		return
+	}
+	document, err := client.GetRolePolicy(ctx, spec.Role.Name, spec.PolicyName)
+	if err != nil {
+		return
+	}
+	changed = !jsonEqual(document, spec.PolicyDocument)
+	return
+}
+
+// jsonEqual checks if two JSON documents are equivalent, ignoring white space and the order of
+// the fields. Documents that aren't valid JSON are compared as strings.
+func jsonEqual(a, b string) bool {
+	var x, y interface{}
+	if json.Unmarshal([]byte(a), &x) != nil || json.Unmarshal([]byte(b), &y) != nil {
+		return a == b
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// deleteIAMRole deletes the inline permission policy of the role and then the role itself. It
+// doesn't fail if they don't exist.
+func deleteIAMRole(ctx context.Context, client IAMClient, roleName, policyName string) error {
+	err := client.DeleteRolePolicy(ctx, roleName, policyName)
+	if err != nil {
+		return err
+	}
+	return client.DeleteRole(ctx, roleName)
+}
+
+// roleTags merges the tags given by the user with the tags that identify a ROSA role. The tags
+// that identify the role take precedence, as OCM uses them to find the roles.
+func roleTags(user map[string]string, tags map[string]string) map[string]string {
+	result := map[string]string{}
+	for key, value := range user {
+		result[key] = value
+	}
+	result["red-hat-managed"] = "true"
+	for key, value := range tags {
+		result[key] = value
+	}
+	return result
+}
@@ -0,0 +1,195 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core" // nolint
+	. "github.com/onsi/gomega"             // nolint
+)
+
+// FakeIAMClient is an implementation of the IAMClient interface that keeps the roles and the
+// policies in memory.
+type FakeIAMClient struct {
+	Identity *IAMCallerIdentity
+	Roles    map[string]*IAMRole
+	Policies map[string]map[string]string
+}
+
+func NewFakeIAMClient() *FakeIAMClient {
+	return &FakeIAMClient{
+		Identity: &IAMCallerIdentity{
+			AccountID: "123456789012",
+			Partition: "aws",
+		},
+		Roles:    map[string]*IAMRole{},
+		Policies: map[string]map[string]string{},
+	}
+}
+
+func (c *FakeIAMClient) GetCallerIdentity(ctx context.Context) (*IAMCallerIdentity, error) {
+	return c.Identity, nil
+}
+
+func (c *FakeIAMClient) GetRole(ctx context.Context, name string) (*IAMRole, error) {
+	return c.Roles[name], nil
+}
+
+func (c *FakeIAMClient) CreateRole(ctx context.Context, role *IAMRole) (*IAMRole, error) {
+	if _, ok := c.Roles[role.Name]; ok {
+		return nil, fmt.Errorf("role '%s' already exists", role.Name)
+	}
+	created := *role
+	created.ARN = fmt.Sprintf(
+		"arn:%s:iam::%s:role%s%s",
+		c.Identity.Partition, c.Identity.AccountID, role.Path, role.Name,
+	)
+	c.Roles[role.Name] = &created
+	return &created, nil
+}
+
+func (c *FakeIAMClient) UpdateRole(ctx context.Context, role *IAMRole) error {
+	current, ok := c.Roles[role.Name]
+	if !ok {
+		return fmt.Errorf("role '%s' doesn't exist", role.Name)
+	}
+	current.AssumeRolePolicy = role.AssumeRolePolicy
+	current.Tags = role.Tags
+	return nil
+}
+
+func (c *FakeIAMClient) DeleteRole(ctx context.Context, name string) error {
+	if len(c.Policies[name]) > 0 {
+		return fmt.Errorf("role '%s' still has policies", name)
+	}
+	delete(c.Roles, name)
+	return nil
+}
+
+func (c *FakeIAMClient) GetRolePolicy(ctx context.Context, role, name string) (string, error) {
+	return c.Policies[role][name], nil
+}
+
+func (c *FakeIAMClient) PutRolePolicy(ctx context.Context, role, name, document string) error {
+	if _, ok := c.Roles[role]; !ok {
+		return fmt.Errorf("role '%s' doesn't exist", role)
+	}
+	if c.Policies[role] == nil {
+		c.Policies[role] = map[string]string{}
+	}
+	c.Policies[role][name] = document
+	return nil
+}
+
+func (c *FakeIAMClient) DeleteRolePolicy(ctx context.Context, role, name string) error {
+	delete(c.Policies[role], name)
+	return nil
+}
+
+var _ = Describe("ROSA roles", func() {
+	It("Replaces the placeholders of policy documents", func() {
+		document := interpolatePolicyDocument(
+			`{"Principal": {"AWS": "arn:%{partition}:iam::%{aws_account_id}:root"}}`,
+			map[string]string{
+				"partition":      "aws",
+				"aws_account_id": "710019948333",
+			},
+		)
+		Expect(document).To(Equal(
+			`{"Principal": {"AWS": "arn:aws:iam::710019948333:root"}}`,
+		))
+	})
+
+	It("Returns the policies of an account role", func() {
+		policies := map[string]string{
+			"sts_support_trust_policy":      `{"Account": "%{aws_account_id}"}`,
+			"sts_support_permission_policy": `{"Resource": "arn:%{partition}:s3:::*"}`,
+		}
+		values := map[string]string{
+			"partition":      "aws",
+			"aws_account_id": "710019948333",
+		}
+		trust, permission, err := accountRolePolicies(policies, accountRoles[1], values)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(trust).To(Equal(`{"Account": "710019948333"}`))
+		Expect(permission).To(Equal(`{"Resource": "arn:aws:s3:::*"}`))
+
+		_, _, err = accountRolePolicies(policies, accountRoles[0], values)
+		Expect(err).To(MatchError(ContainSubstring("sts_installer_trust_policy")))
+	})
+
+	It("Selects the jump account from the URL", func() {
+		Expect(jumpAccountID("https://api.openshift.com")).To(Equal("710019948333"))
+		Expect(jumpAccountID("https://api.stage.openshift.com/")).To(Equal("644306948063"))
+		Expect(jumpAccountID("http://localhost:8000")).To(Equal("710019948333"))
+	})
+
+	It("Gets the partition from the ARN", func() {
+		Expect(arnPartition("arn:aws:iam::123:role/my-role")).To(Equal("aws"))
+		Expect(arnPartition("arn:aws-us-gov:iam::123:role/my-role")).To(Equal("aws-us-gov"))
+		Expect(arnPartition("")).To(Equal("aws"))
+	})
+
+	It("Builds the trust policy of operator roles", func() {
+		identity := &IAMCallerIdentity{
+			AccountID: "123456789012",
+			Partition: "aws",
+		}
+		document, err := buildOperatorTrustPolicy(
+			identity,
+			"https://rh-oidc.s3.us-east-1.amazonaws.com/1a2b3c",
+			[]string{"system:serviceaccount:my-namespace:my-account"},
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(document).To(MatchJSON(`{
+			"Version": "2012-10-17",
+			"Statement": [{
+				"Effect": "Allow",
+				"Action": "sts:AssumeRoleWithWebIdentity",
+				"Principal": {
+					"Federated": "arn:aws:iam::123456789012:oidc-provider/rh-oidc.s3.us-east-1.amazonaws.com/1a2b3c"
+				},
+				"Condition": {
+					"StringEquals": {
+						"rh-oidc.s3.us-east-1.amazonaws.com/1a2b3c:sub": [
+							"system:serviceaccount:my-namespace:my-account"
+						]
+					}
+				}
+			}]
+		}`))
+	})
+
+	It("Merges the tags of roles", func() {
+		tags := roleTags(
+			map[string]string{
+				"owner":           "me",
+				"red-hat-managed": "false",
+			},
+			map[string]string{
+				"rosa_role_type": "installer",
+			},
+		)
+		Expect(tags).To(Equal(map[string]string{
+			"owner":           "me",
+			"red-hat-managed": "true",
+			"rosa_role_type":  "installer",
+		}))
+	})
+})
@@ -12,10 +12,26 @@ func stsResource() tfsdk.NestedAttributes {
 			Type:        types.StringType,
 			Computed:    true,
 		},
+		"oidc_config_id": {
+			Description: "Identifier of the OIDC configuration used by the " +
+				"cluster, as created by the 'ocm_rosa_oidc_config' resource. " +
+				"If it isn't set the cluster gets its own OIDC endpoint. " +
+				"Changing this forces the creation of a new cluster.",
+			Type:     types.StringType,
+			Optional: true,
+			PlanModifiers: []tfsdk.AttributePlanModifier{
+				tfsdk.RequiresReplace(),
+			},
+		},
 		"thumbprint": {
-			Description: "SHA1-hash value of the root CA of the issuer URL",
-			Type:        types.StringType,
-			Computed:    true,
+			Description: "SHA1-hash value of the root CA of the issuer URL. " +
+				"It is calculated when the OIDC endpoint changes, using the " +
+				"proxy given in the 'HTTPS_PROXY' and 'NO_PROXY' environment " +
+				"variables and a timeout of ten seconds. Use the " +
+				"'ocm_oidc_thumbprint' data source to configure the proxy " +
+				"and the timeout explicitly.",
+			Type:     types.StringType,
+			Computed: true,
 		},
 		"role_arn": {
 			Description: "Installer Role",
@@ -0,0 +1,219 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// Default values used to get the thumbprint of OIDC endpoints when they aren't explicitly
+// configured:
+const (
+	defaultThumbprintTimeout = 10 * time.Second
+	thumbprintAttempts       = 3
+	thumbprintRetryInterval  = 2 * time.Second
+)
+
+// HttpClient is the subset of the HTTP client used to get the certificates of the OIDC
+// endpoints.
+type HttpClient interface {
+	Get(ctx context.Context, url string) (resp *http.Response, err error)
+}
+
+// DefaultHttpClient is the HTTP client used to get the certificates of the OIDC endpoints. It
+// honours the proxy environment variables unless a proxy is explicitly given, and it trusts
+// the certificate authorities of the system in addition to the given ones.
+type DefaultHttpClient struct {
+	// Timeout is the maximum time to wait for the connection and the response. Zero means no
+	// timeout.
+	Timeout time.Duration
+
+	// Proxy is the URL of the proxy server. When empty the proxy is taken from the
+	// HTTPS_PROXY and NO_PROXY environment variables.
+	Proxy string
+
+	// TrustedCAs contains PEM encoded certificates of additional certificate authorities.
+	TrustedCAs string
+}
+
+func (c DefaultHttpClient) Get(ctx context.Context, url string) (resp *http.Response,
+	err error) {
+	client, err := c.client()
+	if err != nil {
+		return
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return
+	}
+	resp, err = client.Do(request)
+	return
+}
+
+func (c DefaultHttpClient) client() (result *http.Client, err error) {
+	proxy := http.ProxyFromEnvironment
+	if c.Proxy != "" {
+		var proxyURL *url.URL
+		proxyURL, err = url.Parse(c.Proxy)
+		if err != nil {
+			err = fmt.Errorf("invalid proxy URL '%s': %v", c.Proxy, err)
+			return
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+	var roots *x509.CertPool
+	if c.TrustedCAs != "" {
+		roots, err = x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM([]byte(c.TrustedCAs)) {
+			err = errors.New("trusted certificate authorities don't contain any certificate")
+			return
+		}
+	}
+	dialer := &net.Dialer{
+		Timeout: c.Timeout,
+	}
+	result = &http.Client{
+		Timeout: c.Timeout,
+		Transport: &http.Transport{
+			Proxy:               proxy,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: c.Timeout,
+			TLSClientConfig: &tls.Config{
+				MinVersion: tls.VersionTLS12,
+				RootCAs:    roots,
+			},
+		},
+	}
+	return
+}
+
+// getThumbprint returns the SHA1 thumbprint of the certificate authority of the given OIDC
+// endpoint, as needed by the AWS OIDC identity providers. Failures to connect to the endpoint
+// are retried a few times before giving up.
+func getThumbprint(ctx context.Context, oidcEndpointURL string,
+	httpClient HttpClient) (thumbprint string, err error) {
+	return getThumbprintWithRetries(
+		ctx, oidcEndpointURL, httpClient, thumbprintAttempts, thumbprintRetryInterval,
+	)
+}
+
+func getThumbprintWithRetries(ctx context.Context, oidcEndpointURL string, httpClient HttpClient,
+	attempts int, interval time.Duration) (thumbprint string, err error) {
+	connect, err := url.ParseRequestURI(oidcEndpointURL)
+	if err != nil {
+		return
+	}
+	address := fmt.Sprintf("https://%s:443", connect.Hostname())
+	for attempt := 1; ; attempt++ {
+		var chain []*x509.Certificate
+		chain, err = getCertificateChain(ctx, address, httpClient)
+		if err == nil {
+			return thumbprintOf(chain)
+		}
+		if attempt >= attempts || !isTransientError(err) {
+			err = fmt.Errorf(
+				"can't get certificates of '%s' after %d attempts: %v",
+				address, attempt, err,
+			)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case <-time.After(time.Duration(attempt) * interval):
+		}
+	}
+}
+
+// isTransientError checks if the given error may go away when the request is retried. Only
+// timeouts, refused or reset connections and connections closed unexpectedly are retried, other
+// errors like host names that don't exist or certificates that can't be verified aren't
+// expected to go away in a few seconds.
+func isTransientError(err error) bool {
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET):
+		return true
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	default:
+		return false
+	}
+}
+
+func getCertificateChain(ctx context.Context, address string,
+	httpClient HttpClient) (result []*x509.Certificate, err error) {
+	response, err := httpClient.Get(ctx, address)
+	if err != nil {
+		return
+	}
+	if response.Body != nil {
+		defer response.Body.Close()
+	}
+	if response.TLS == nil || len(response.TLS.PeerCertificates) == 0 {
+		err = fmt.Errorf("server didn't present any TLS certificate")
+		return
+	}
+	result = response.TLS.PeerCertificates
+	return
+}
+
+func thumbprintOf(chain []*x509.Certificate) (string, error) {
+	// Grab the CA in the chain
+	for _, cert := range chain {
+		if cert.IsCA {
+			if bytes.Equal(cert.RawIssuer, cert.RawSubject) {
+				return sha1Hash(cert.Raw)
+			}
+		}
+	}
+
+	// Fall back to using the last certficiate in the chain
+	cert := chain[len(chain)-1]
+	return sha1Hash(cert.Raw)
+}
+
+// sha1Hash computes the SHA1 of the byte array and returns the hex encoding as a string.
+func sha1Hash(data []byte) (string, error) {
+	// nolint:gosec
+	hasher := sha1.New()
+	_, err := hasher.Write(data)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't calculate hash:\n %v", err)
+	}
+	hashed := hasher.Sum(nil)
+	return hex.EncodeToString(hashed), nil
+}
@@ -0,0 +1,202 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core" // nolint
+	. "github.com/onsi/gomega"             // nolint
+)
+
+// FlakyHttpClient is an implementation of the HttpClient interface that fails a number of times
+// before returning the given response.
+type FlakyHttpClient struct {
+	failures int
+	err      error
+	calls    []string
+	response *http.Response
+}
+
+func (c *FlakyHttpClient) Get(ctx context.Context, url string) (resp *http.Response,
+	err error) {
+	c.calls = append(c.calls, url)
+	if len(c.calls) <= c.failures {
+		err = c.err
+		if err == nil {
+			err = &net.OpError{
+				Op:  "dial",
+				Net: "tcp",
+				Err: &os.SyscallError{
+					Syscall: "connect",
+					Err:     syscall.ECONNREFUSED,
+				},
+			}
+		}
+		return
+	}
+	resp = c.response
+	return
+}
+
+var _ = Describe("OIDC thumbprint", func() {
+	ca := &x509.Certificate{
+		Raw:        []byte("ca"),
+		RawIssuer:  []byte("issuer"),
+		RawSubject: []byte("issuer"),
+		IsCA:       true,
+	}
+	leaf := &x509.Certificate{
+		Raw: []byte("leaf"),
+	}
+
+	It("Prefers the self signed certificate authority of the chain", func() {
+		client := &FlakyHttpClient{
+			response: &http.Response{
+				TLS: &tls.ConnectionState{
+					PeerCertificates: []*x509.Certificate{leaf, ca},
+				},
+			},
+		}
+		thumbprint, err := getThumbprint(context.Background(), "https://example.com/1234", client)
+		Expect(err).ToNot(HaveOccurred())
+		expected, err := sha1Hash([]byte("ca"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(thumbprint).To(Equal(expected))
+		Expect(client.calls).To(ConsistOf("https://example.com:443"))
+	})
+
+	It("Retries until the endpoint responds", func() {
+		client := &FlakyHttpClient{
+			failures: 2,
+			response: &http.Response{
+				TLS: &tls.ConnectionState{
+					PeerCertificates: []*x509.Certificate{leaf},
+				},
+			},
+		}
+		thumbprint, err := getThumbprintWithRetries(
+			context.Background(), "https://example.com", client, 3, time.Millisecond,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		expected, err := sha1Hash([]byte("leaf"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(thumbprint).To(Equal(expected))
+		Expect(client.calls).To(HaveLen(3))
+	})
+
+	It("Gives up after the last attempt", func() {
+		client := &FlakyHttpClient{
+			failures: 3,
+		}
+		_, err := getThumbprintWithRetries(
+			context.Background(), "https://example.com", client, 3, time.Millisecond,
+		)
+		Expect(err).To(MatchError(ContainSubstring("after 3 attempts")))
+		Expect(client.calls).To(HaveLen(3))
+	})
+
+	It("Doesn't retry host names that don't exist", func() {
+		client := &FlakyHttpClient{
+			failures: 3,
+			err: &net.DNSError{
+				Err:        "no such host",
+				Name:       "example.com",
+				IsNotFound: true,
+			},
+		}
+		_, err := getThumbprintWithRetries(
+			context.Background(), "https://example.com", client, 3, time.Millisecond,
+		)
+		Expect(err).To(MatchError(ContainSubstring("no such host")))
+		Expect(client.calls).To(HaveLen(1))
+	})
+
+	It("Doesn't retry certificates that can't be verified", func() {
+		client := &FlakyHttpClient{
+			failures: 3,
+			err: &url.Error{
+				Op:  "Get",
+				URL: "https://example.com:443",
+				Err: x509.UnknownAuthorityError{},
+			},
+		}
+		_, err := getThumbprintWithRetries(
+			context.Background(), "https://example.com", client, 3, time.Second,
+		)
+		Expect(err).To(MatchError(ContainSubstring("after 1 attempts")))
+		Expect(client.calls).To(HaveLen(1))
+	})
+
+	It("Retries timeouts", func() {
+		client := &FlakyHttpClient{
+			failures: 1,
+			err:      context.DeadlineExceeded,
+			response: &http.Response{
+				TLS: &tls.ConnectionState{
+					PeerCertificates: []*x509.Certificate{leaf},
+				},
+			},
+		}
+		_, err := getThumbprintWithRetries(
+			context.Background(), "https://example.com", client, 3, time.Millisecond,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.calls).To(HaveLen(2))
+	})
+
+	It("Fails if the server doesn't present certificates", func() {
+		client := &FlakyHttpClient{
+			response: &http.Response{},
+		}
+		_, err := getThumbprintWithRetries(
+			context.Background(), "https://example.com", client, 1, time.Millisecond,
+		)
+		Expect(err).To(MatchError(ContainSubstring("didn't present any TLS certificate")))
+	})
+
+	It("Doesn't retry invalid URLs", func() {
+		client := &FlakyHttpClient{}
+		_, err := getThumbprint(context.Background(), "invalid$url", client)
+		Expect(err).To(HaveOccurred())
+		Expect(client.calls).To(BeEmpty())
+	})
+
+	It("Rejects trusted certificate authorities without certificates", func() {
+		client := DefaultHttpClient{
+			TrustedCAs: "junk",
+		}
+		_, err := client.Get(context.Background(), "https://example.com")
+		Expect(err).To(MatchError(ContainSubstring("don't contain any certificate")))
+	})
+
+	It("Rejects invalid proxy URLs", func() {
+		client := DefaultHttpClient{
+			Proxy: "http://[::1",
+		}
+		_, err := client.Get(context.Background(), "https://example.com")
+		Expect(err).To(MatchError(ContainSubstring("invalid proxy URL")))
+	})
+})
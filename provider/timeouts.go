@@ -0,0 +1,164 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Default values used when the corresponding attribute of the `timeouts` attribute isn't
+// explicitly set:
+const (
+	defaultCreateTimeout = 1 * time.Hour
+	defaultUpdateTimeout = 1 * time.Hour
+	defaultDeleteTimeout = 10 * time.Minute
+	defaultPollInterval  = 30 * time.Second
+)
+
+func timeoutsResource() tfsdk.NestedAttributes {
+	return tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
+		"create": {
+			Description: "Maximum time to wait for the create operation to " +
+				"complete, for example '90m'. Default value is '1h'.",
+			Type:     types.StringType,
+			Optional: true,
+			Validators: []tfsdk.AttributeValidator{
+				DurationValidator(),
+			},
+		},
+		"update": {
+			Description: "Maximum time to wait for the update operation to " +
+				"complete, for example '90m'. Default value is '1h'.",
+			Type:     types.StringType,
+			Optional: true,
+			Validators: []tfsdk.AttributeValidator{
+				DurationValidator(),
+			},
+		},
+		"delete": {
+			Description: "Maximum time to wait for the delete operation to " +
+				"complete, for example '30m'. Default value is '10m'.",
+			Type:     types.StringType,
+			Optional: true,
+			Validators: []tfsdk.AttributeValidator{
+				DurationValidator(),
+			},
+		},
+		"poll_interval": {
+			Description: "Time between two consecutive checks of the state of " +
+				"the cluster while waiting, for example '10s'. Default value " +
+				"is '30s'.",
+			Type:     types.StringType,
+			Optional: true,
+			Validators: []tfsdk.AttributeValidator{
+				DurationValidator(),
+			},
+		},
+	})
+}
+
+// CreateTimeout returns the maximum duration of the create operation.
+func (t *Timeouts) CreateTimeout() time.Duration {
+	if t == nil {
+		return defaultCreateTimeout
+	}
+	return durationOrDefault(t.Create, defaultCreateTimeout)
+}
+
+// UpdateTimeout returns the maximum duration of the update operation.
+func (t *Timeouts) UpdateTimeout() time.Duration {
+	if t == nil {
+		return defaultUpdateTimeout
+	}
+	return durationOrDefault(t.Update, defaultUpdateTimeout)
+}
+
+// DeleteTimeout returns the maximum duration of the delete operation.
+func (t *Timeouts) DeleteTimeout() time.Duration {
+	if t == nil {
+		return defaultDeleteTimeout
+	}
+	return durationOrDefault(t.Delete, defaultDeleteTimeout)
+}
+
+// Interval returns the time between two consecutive polling requests.
+func (t *Timeouts) Interval() time.Duration {
+	if t == nil {
+		return defaultPollInterval
+	}
+	return durationOrDefault(t.PollInterval, defaultPollInterval)
+}
+
+// durationOrDefault parses the given value as a duration. If the value is null, unknown or can't be
+// parsed it returns the given default. Note that values are checked at plan time by the duration
+// validator, so this should only happen when the value isn't set.
+func durationOrDefault(value types.String, def time.Duration) time.Duration {
+	if value.Unknown || value.Null {
+		return def
+	}
+	result, err := time.ParseDuration(value.Value)
+	if err != nil || result <= 0 {
+		return def
+	}
+	return result
+}
+
+type durationValidator struct {
+}
+
+// DurationValidator returns an attribute validator that checks that the value of a string
+// attribute is a positive duration, for example '30m' or '1h30m'.
+func DurationValidator() tfsdk.AttributeValidator {
+	return durationValidator{}
+}
+
+func (v durationValidator) Description(ctx context.Context) string {
+	return "The value must be a positive duration, for example '30m' or '1h30m'."
+}
+
+func (v durationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v durationValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest,
+	resp *tfsdk.ValidateAttributeResponse) {
+	value, ok := req.AttributeConfig.(types.String)
+	if !ok || value.Unknown || value.Null {
+		return
+	}
+	duration, err := time.ParseDuration(value.Value)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.AttributePath,
+			"Invalid duration",
+			fmt.Sprintf("Can't parse duration '%s': %v", value.Value, err),
+		)
+		return
+	}
+	if duration <= 0 {
+		resp.Diagnostics.AddAttributeError(
+			req.AttributePath,
+			"Invalid duration",
+			fmt.Sprintf("Duration '%s' must be greater than zero", value.Value),
+		)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	. "github.com/onsi/ginkgo/v2/dsl/core"  // nolint
+	. "github.com/onsi/ginkgo/v2/dsl/table" // nolint
+	. "github.com/onsi/gomega"              // nolint
+)
+
+var _ = Describe("Timeouts", func() {
+	It("Returns the defaults when the timeouts aren't set", func() {
+		var timeouts *Timeouts
+		Expect(timeouts.CreateTimeout()).To(Equal(defaultCreateTimeout))
+		Expect(timeouts.UpdateTimeout()).To(Equal(defaultUpdateTimeout))
+		Expect(timeouts.DeleteTimeout()).To(Equal(defaultDeleteTimeout))
+		Expect(timeouts.Interval()).To(Equal(defaultPollInterval))
+	})
+
+	It("Returns the defaults for the attributes that are null", func() {
+		timeouts := &Timeouts{
+			Create: types.String{
+				Value: "2h",
+			},
+			Update: types.String{
+				Null: true,
+			},
+			Delete: types.String{
+				Unknown: true,
+			},
+			PollInterval: types.String{
+				Value: "5s",
+			},
+		}
+		Expect(timeouts.CreateTimeout()).To(Equal(2 * time.Hour))
+		Expect(timeouts.UpdateTimeout()).To(Equal(defaultUpdateTimeout))
+		Expect(timeouts.DeleteTimeout()).To(Equal(defaultDeleteTimeout))
+		Expect(timeouts.Interval()).To(Equal(5 * time.Second))
+	})
+
+	DescribeTable("Validates durations",
+		func(value string, valid bool) {
+			request := tfsdk.ValidateAttributeRequest{
+				AttributePath: tftypes.NewAttributePath().
+					WithAttributeName("timeouts").
+					WithAttributeName("create"),
+				AttributeConfig: types.String{
+					Value: value,
+				},
+			}
+			response := &tfsdk.ValidateAttributeResponse{}
+			DurationValidator().Validate(context.Background(), request, response)
+			Expect(response.Diagnostics.HasError()).To(Equal(!valid))
+		},
+		Entry("Minutes", "90m", true),
+		Entry("Hours and minutes", "1h30m", true),
+		Entry("Missing unit", "90", false),
+		Entry("Zero", "0s", false),
+		Entry("Negative", "-5m", false),
+		Entry("Garbage", "junk", false),
+	)
+})
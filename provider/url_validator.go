@@ -0,0 +1,92 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type urlValidator struct {
+	schemes []string
+}
+
+// URLValidator returns an attribute validator that checks that the value of a string attribute
+// is an absolute URL with a host name and one of the given schemes.
+func URLValidator(schemes ...string) tfsdk.AttributeValidator {
+	return urlValidator{
+		schemes: schemes,
+	}
+}
+
+func (v urlValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf(
+		"The value must be an URL with one of the schemes %s.",
+		quoteNames(v.schemes),
+	)
+}
+
+func (v urlValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v urlValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest,
+	resp *tfsdk.ValidateAttributeResponse) {
+	value, ok := req.AttributeConfig.(types.String)
+	if !ok || value.Unknown || value.Null {
+		return
+	}
+	parsed, err := url.Parse(value.Value)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.AttributePath,
+			"Invalid URL",
+			fmt.Sprintf("Can't parse URL '%s': %v", value.Value, err),
+		)
+		return
+	}
+	valid := false
+	for _, scheme := range v.schemes {
+		if strings.EqualFold(parsed.Scheme, scheme) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		resp.Diagnostics.AddAttributeError(
+			req.AttributePath,
+			"Invalid URL",
+			fmt.Sprintf(
+				"URL '%s' must use one of the schemes %s",
+				value.Value, quoteNames(v.schemes),
+			),
+		)
+		return
+	}
+	if parsed.Hostname() == "" {
+		resp.Diagnostics.AddAttributeError(
+			req.AttributePath,
+			"Invalid URL",
+			fmt.Sprintf("URL '%s' must contain a host name", value.Value),
+		)
+	}
+}
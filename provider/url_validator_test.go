@@ -0,0 +1,48 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	. "github.com/onsi/ginkgo/v2/dsl/table" // nolint
+	. "github.com/onsi/gomega"              // nolint
+)
+
+var _ = DescribeTable("Validates URLs",
+	func(value string, valid bool) {
+		request := tfsdk.ValidateAttributeRequest{
+			AttributePath: tftypes.NewAttributePath().WithAttributeName("url"),
+			AttributeConfig: types.String{
+				Value: value,
+			},
+		}
+		response := &tfsdk.ValidateAttributeResponse{}
+		URLValidator("ldap", "ldaps").Validate(context.Background(), request, response)
+		Expect(response.Diagnostics.HasError()).To(Equal(!valid))
+	},
+	Entry("Plain", "ldap://my-server.com", true),
+	Entry("TLS", "ldaps://my-server.com:636/ou=users?uid", true),
+	Entry("Upper case scheme", "LDAPS://my-server.com", true),
+	Entry("Wrong scheme", "https://my-server.com", false),
+	Entry("Missing scheme", "my-server.com", false),
+	Entry("Missing host", "ldap:///ou=users", false),
+	Entry("Garbage", "ldap://my server.com", false),
+)
@@ -0,0 +1,195 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/errors"
+)
+
+// waitTillClusterReady polls the given cluster till its state is ready. The context must have a
+// deadline, usually derived from the `timeouts` attribute of the resource. It returns the last
+// version of the cluster retrieved from the server. If the cluster moves to the error state the
+// polling stops and the returned error contains the provision error message.
+func waitTillClusterReady(ctx context.Context, resource *cmv1.ClusterClient,
+	interval time.Duration) (object *cmv1.Cluster, err error) {
+	_, err = resource.Poll().
+		Interval(interval).
+		Predicate(func(get *cmv1.ClusterGetResponse) bool {
+			object = get.Body()
+			switch object.State() {
+			case cmv1.ClusterStateReady, cmv1.ClusterStateError:
+				return true
+			default:
+				return false
+			}
+		}).
+		StartContext(ctx)
+	if err != nil {
+		return
+	}
+
+	// Note that the polling loop stops without an error when there isn't time for another
+	// iteration before the deadline, so we need to check the state explicitly:
+	switch object.State() {
+	case cmv1.ClusterStateReady:
+	case cmv1.ClusterStateError:
+		err = clusterProvisionError(ctx, resource, object)
+	default:
+		err = fmt.Errorf(
+			"cluster is still in state '%s' after the timeout expired",
+			object.State(),
+		)
+	}
+	return
+}
+
+// clusterProvisionError creates the error that is reported when a cluster that we are waiting
+// for moves to the error state. The provision error message isn't always included in the cluster
+// object, so when it is missing it is retrieved from the status of the cluster.
+func clusterProvisionError(ctx context.Context, resource *cmv1.ClusterClient,
+	object *cmv1.Cluster) error {
+	code := object.Status().ProvisionErrorCode()
+	message := object.Status().ProvisionErrorMessage()
+	if message == "" {
+		get, err := resource.Status().Get().SendContext(ctx)
+		if err == nil {
+			code = get.Body().ProvisionErrorCode()
+			message = get.Body().ProvisionErrorMessage()
+		}
+	}
+	switch {
+	case code != "" && message != "":
+		return fmt.Errorf("cluster is in error state: %s: %s", code, message)
+	case message != "":
+		return fmt.Errorf("cluster is in error state: %s", message)
+	default:
+		return fmt.Errorf("cluster is in error state")
+	}
+}
+
+// waitTillClusterDeleted polls the given cluster till the server responds saying that it doesn't
+// exist. The context must have a deadline, usually derived from the `timeouts` attribute of the
+// resource.
+func waitTillClusterDeleted(ctx context.Context, resource *cmv1.ClusterClient,
+	interval time.Duration) error {
+	poll, err := resource.Poll().
+		Interval(interval).
+		Status(http.StatusNotFound).
+		StartContext(ctx)
+	sdkErr, ok := err.(*errors.Error)
+	if ok && sdkErr.Status() == http.StatusNotFound {
+		return nil
+	}
+	if err == nil && poll != nil && poll.Status() != http.StatusNotFound {
+		err = fmt.Errorf("cluster still exists after the timeout expired")
+	}
+	return err
+}
+
+// waitTillClusterUpgraded polls the given cluster till it runs the given raw version. The context
+// must have a deadline, usually derived from the `timeouts` attribute of the resource. It returns
+// the last version of the cluster retrieved from the server.
+func waitTillClusterUpgraded(ctx context.Context, resource *cmv1.ClusterClient, version string,
+	interval time.Duration) (object *cmv1.Cluster, err error) {
+	_, err = resource.Poll().
+		Interval(interval).
+		Predicate(func(get *cmv1.ClusterGetResponse) bool {
+			object = get.Body()
+			return rawVersion(object.Version().ID()) == version ||
+				object.State() == cmv1.ClusterStateError
+		}).
+		StartContext(ctx)
+	if err != nil {
+		return
+	}
+	switch {
+	case object.State() == cmv1.ClusterStateError:
+		err = clusterProvisionError(ctx, resource, object)
+	case rawVersion(object.Version().ID()) != version:
+		err = fmt.Errorf(
+			"cluster is still running version '%s' after the timeout expired",
+			rawVersion(object.Version().ID()),
+		)
+	}
+	return
+}
+
+// waitTillAddonReady polls the given add-on installation till its state is ready. The context
+// must have a deadline, usually derived from the `timeouts` attribute of the resource. It returns
+// the last version of the installation retrieved from the server. If the installation moves to
+// the failed state the polling stops and the returned error contains the state description.
+func waitTillAddonReady(ctx context.Context, resource *cmv1.AddOnInstallationClient,
+	interval time.Duration) (object *cmv1.AddOnInstallation, err error) {
+	_, err = resource.Poll().
+		Interval(interval).
+		Predicate(func(get *cmv1.AddOnInstallationGetResponse) bool {
+			object = get.Body()
+			switch object.State() {
+			case cmv1.AddOnInstallationStateReady, cmv1.AddOnInstallationStateFailed:
+				return true
+			default:
+				return false
+			}
+		}).
+		StartContext(ctx)
+	if err != nil {
+		return
+	}
+	switch object.State() {
+	case cmv1.AddOnInstallationStateReady:
+	case cmv1.AddOnInstallationStateFailed:
+		if object.StateDescription() != "" {
+			err = fmt.Errorf(
+				"add-on installation failed: %s",
+				object.StateDescription(),
+			)
+		} else {
+			err = fmt.Errorf("add-on installation failed")
+		}
+	default:
+		err = fmt.Errorf(
+			"add-on is still in state '%s' after the timeout expired",
+			object.State(),
+		)
+	}
+	return
+}
+
+// waitTillAddonDeleted polls the given add-on installation till the server responds saying that
+// it doesn't exist. The context must have a deadline, usually derived from the `timeouts`
+// attribute of the resource.
+func waitTillAddonDeleted(ctx context.Context, resource *cmv1.AddOnInstallationClient,
+	interval time.Duration) error {
+	poll, err := resource.Poll().
+		Interval(interval).
+		Status(http.StatusNotFound).
+		StartContext(ctx)
+	sdkErr, ok := err.(*errors.Error)
+	if ok && sdkErr.Status() == http.StatusNotFound {
+		return nil
+	}
+	if err == nil && poll != nil && poll.Status() != http.StatusNotFound {
+		err = fmt.Errorf("add-on is still installed after the timeout expired")
+	}
+	return err
+}
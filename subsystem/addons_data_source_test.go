@@ -0,0 +1,94 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/onsi/gomega/ghttp"                   // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Add-ons data source", func() {
+	It("Can list add-ons", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/addons"),
+				RespondWithJSON(http.StatusOK, `{
+				  "page": 1,
+				  "size": 1,
+				  "total": 1,
+				  "items": [
+				    {
+				      "id": "my-addon",
+				      "name": "My add-on",
+				      "description": "My add-on description",
+				      "enabled": true,
+				      "parameters": {
+				        "items": [
+				          {
+				            "id": "size",
+				            "name": "Size",
+				            "description": "Size of the storage",
+				            "value_type": "string",
+				            "required": true,
+				            "editable": false,
+				            "enabled": true,
+				            "default_value": "1Ti",
+				            "options": [
+				              {
+				                "name": "One",
+				                "value": "1Ti"
+				              },
+				              {
+				                "name": "Four",
+				                "value": "4Ti"
+				              }
+				            ]
+				          }
+				        ]
+				      }
+				    }
+				  ]
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  data "ocm_addons" "my_addons" {
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_addons", "my_addons")
+		Expect(resource).To(MatchJQ(`.attributes.items | length`, 1))
+		Expect(resource).To(MatchJQ(`.attributes.items[0].id`, "my-addon"))
+		Expect(resource).To(MatchJQ(`.attributes.items[0].name`, "My add-on"))
+		Expect(resource).To(MatchJQ(`.attributes.items[0].enabled`, true))
+		Expect(resource).To(MatchJQ(`.attributes.items[0].parameters[0].id`, "size"))
+		Expect(resource).To(MatchJQ(`.attributes.items[0].parameters[0].required`, true))
+		Expect(resource).To(MatchJQ(`.attributes.items[0].parameters[0].default_value`, "1Ti"))
+		Expect(resource).To(MatchJQ(`.attributes.items[0].parameters[0].options`, []interface{}{
+			"1Ti", "4Ti",
+		}))
+	})
+})
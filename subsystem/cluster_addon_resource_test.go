@@ -0,0 +1,355 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/onsi/gomega/ghttp"                   // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cluster add-on creation", func() {
+	// This is the definition of the add-on used by all the tests:
+	const addon = `{
+	  "id": "my-addon",
+	  "name": "My add-on",
+	  "enabled": true,
+	  "parameters": {
+	    "items": [
+	      {
+	        "id": "size",
+	        "enabled": true,
+	        "required": true,
+	        "options": [
+	          {
+	            "value": "1Ti"
+	          },
+	          {
+	            "value": "4Ti"
+	          }
+	        ]
+	      }
+	    ]
+	  }
+	}`
+
+	BeforeEach(func() {
+		// The provider gets the definition of the add-on to check the parameters every
+		// time that the plan is calculated, and that may happen more than once for each
+		// command, so we always respond to that regardless of the order:
+		server.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/addons/my-addon",
+			RespondWithJSON(http.StatusOK, addon),
+		)
+
+		// The first thing that the provider will do when installing an add-on is check
+		// that the cluster is ready, so we always need to prepare the server to respond
+		// to that:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "123",
+				  "name": "my-cluster",
+				  "state": "ready"
+				}`),
+			),
+		)
+	})
+
+	It("Can install, update and uninstall an add-on", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/addons",
+				),
+				VerifyJSON(`{
+				  "kind": "AddOnInstallation",
+				  "addon": {
+				    "kind": "AddOn",
+				    "id": "my-addon"
+				  },
+				  "parameters": {
+				    "items": [
+				      {
+				        "kind": "AddOnInstallationParameter",
+				        "id": "size",
+				        "value": "1Ti"
+				      }
+				    ]
+				  }
+				}`),
+				RespondWithJSON(http.StatusCreated, `{
+				  "id": "my-addon",
+				  "addon": {
+				    "id": "my-addon"
+				  },
+				  "state": "installing",
+				  "parameters": {
+				    "items": [
+				      {
+				        "id": "size",
+				        "value": "1Ti"
+				      }
+				    ]
+				  }
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/addons/my-addon",
+				),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "my-addon",
+				  "addon": {
+				    "id": "my-addon"
+				  },
+				  "state": "ready",
+				  "parameters": {
+				    "items": [
+				      {
+				        "id": "size",
+				        "value": "1Ti"
+				      }
+				    ]
+				  }
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_cluster_addon" "my_addon" {
+		    cluster = "123"
+		    addon   = "my-addon"
+		    parameters = {
+		      size = "1Ti"
+		    }
+		    timeouts = {
+		      poll_interval = "1s"
+		    }
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_cluster_addon", "my_addon")
+		Expect(resource).To(MatchJQ(".attributes.cluster", "123"))
+		Expect(resource).To(MatchJQ(".attributes.id", "my-addon"))
+		Expect(resource).To(MatchJQ(".attributes.addon", "my-addon"))
+		Expect(resource).To(MatchJQ(".attributes.state", "ready"))
+		Expect(resource).To(MatchJQ(".attributes.parameters.size", "1Ti"))
+
+		// Prepare the server for the update:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/addons/my-addon",
+				),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "my-addon",
+				  "addon": {
+				    "id": "my-addon"
+				  },
+				  "state": "ready",
+				  "parameters": {
+				    "items": [
+				      {
+				        "id": "size",
+				        "value": "1Ti"
+				      }
+				    ]
+				  }
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPatch,
+					"/api/clusters_mgmt/v1/clusters/123/addons/my-addon",
+				),
+				VerifyJSON(`{
+				  "kind": "AddOnInstallation",
+				  "parameters": {
+				    "items": [
+				      {
+				        "kind": "AddOnInstallationParameter",
+				        "id": "size",
+				        "value": "4Ti"
+				      }
+				    ]
+				  }
+				}`),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "my-addon",
+				  "addon": {
+				    "id": "my-addon"
+				  },
+				  "state": "installing"
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/addons/my-addon",
+				),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "my-addon",
+				  "addon": {
+				    "id": "my-addon"
+				  },
+				  "state": "ready",
+				  "parameters": {
+				    "items": [
+				      {
+				        "id": "size",
+				        "value": "4Ti"
+				      }
+				    ]
+				  }
+				}`),
+			),
+		)
+
+		// Run the apply command again:
+		terraform.Source(`
+		  resource "ocm_cluster_addon" "my_addon" {
+		    cluster = "123"
+		    addon   = "my-addon"
+		    parameters = {
+		      size = "4Ti"
+		    }
+		    timeouts = {
+		      poll_interval = "1s"
+		    }
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource = terraform.Resource("ocm_cluster_addon", "my_addon")
+		Expect(resource).To(MatchJQ(".attributes.parameters.size", "4Ti"))
+
+		// Prepare the server for the uninstall:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/addons/my-addon",
+				),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "my-addon",
+				  "addon": {
+				    "id": "my-addon"
+				  },
+				  "state": "ready",
+				  "parameters": {
+				    "items": [
+				      {
+				        "id": "size",
+				        "value": "4Ti"
+				      }
+				    ]
+				  }
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodDelete,
+					"/api/clusters_mgmt/v1/clusters/123/addons/my-addon",
+				),
+				RespondWithJSON(http.StatusNoContent, "{}"),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/addons/my-addon",
+				),
+				RespondWithJSON(http.StatusNotFound, "{}"),
+			),
+		)
+
+		// Run the destroy command:
+		Expect(terraform.Destroy()).To(BeZero())
+	})
+
+	It("Fails if the installation fails", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/addons",
+				),
+				RespondWithJSON(http.StatusCreated, `{
+				  "id": "my-addon",
+				  "addon": {
+				    "id": "my-addon"
+				  },
+				  "state": "installing"
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/addons/my-addon",
+				),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "my-addon",
+				  "addon": {
+				    "id": "my-addon"
+				  },
+				  "state": "failed",
+				  "state_description": "Not enough capacity"
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_cluster_addon" "my_addon" {
+		    cluster = "123"
+		    addon   = "my-addon"
+		    parameters = {
+		      size = "1Ti"
+		    }
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
+
+	It("Fails if a parameter isn't valid", func() {
+		// Run the plan command, it should fail without installing anything:
+		terraform.Source(`
+		  resource "ocm_cluster_addon" "my_addon" {
+		    cluster = "123"
+		    addon   = "my-addon"
+		    parameters = {
+		      size = "2Ti"
+		    }
+		  }
+		`)
+		Expect(terraform.Plan()).ToNot(BeZero())
+	})
+})
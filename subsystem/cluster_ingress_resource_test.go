@@ -0,0 +1,215 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/onsi/gomega/ghttp"                   // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cluster ingress creation", func() {
+	BeforeEach(func() {
+		// The first thing that the provider will do when creating an ingress is check
+		// that the cluster is ready, so we always need to prepare the server to respond
+		// to that:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "123",
+				  "name": "my-cluster",
+				  "state": "ready"
+				}`),
+			),
+		)
+	})
+
+	It("Adopts the default ingress and doesn't delete it", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/ingresses",
+				),
+				RespondWithJSON(http.StatusOK, `{
+				  "page": 1,
+				  "size": 2,
+				  "total": 2,
+				  "items": [
+				    {
+				      "id": "abc",
+				      "default": false,
+				      "listening": "external",
+				      "dns_name": "apps2.my-cluster.example.com"
+				    },
+				    {
+				      "id": "def",
+				      "default": true,
+				      "listening": "external",
+				      "dns_name": "apps.my-cluster.example.com"
+				    }
+				  ]
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPatch,
+					"/api/clusters_mgmt/v1/clusters/123/ingresses/def",
+				),
+				VerifyJSON(`{
+				  "kind": "Ingress",
+				  "listening": "internal"
+				}`),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "def",
+				  "default": true,
+				  "listening": "internal",
+				  "dns_name": "apps.my-cluster.example.com"
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_cluster_ingress" "my_ingress" {
+		    cluster   = "123"
+		    default   = true
+		    listening = "internal"
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_cluster_ingress", "my_ingress")
+		Expect(resource).To(MatchJQ(".attributes.cluster", "123"))
+		Expect(resource).To(MatchJQ(".attributes.id", "def"))
+		Expect(resource).To(MatchJQ(".attributes.default", true))
+		Expect(resource).To(MatchJQ(".attributes.listening", "internal"))
+		Expect(resource).To(MatchJQ(".attributes.dns_name", "apps.my-cluster.example.com"))
+
+		// Prepare the server for the destroy, note that there is no delete request:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/ingresses/def",
+				),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "def",
+				  "default": true,
+				  "listening": "internal",
+				  "dns_name": "apps.my-cluster.example.com"
+				}`),
+			),
+		)
+
+		// Run the destroy command:
+		Expect(terraform.Destroy()).To(BeZero())
+	})
+
+	It("Can create and delete a secondary ingress", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/ingresses",
+				),
+				VerifyJSON(`{
+				  "kind": "Ingress",
+				  "default": false,
+				  "listening": "external",
+				  "route_selectors": {
+				    "shard": "public"
+				  }
+				}`),
+				RespondWithJSON(http.StatusCreated, `{
+				  "id": "abc",
+				  "default": false,
+				  "listening": "external",
+				  "route_selectors": {
+				    "shard": "public"
+				  },
+				  "dns_name": "apps2.my-cluster.example.com"
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_cluster_ingress" "my_ingress" {
+		    cluster   = "123"
+		    listening = "external"
+		    route_selectors = {
+		      shard = "public"
+		    }
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_cluster_ingress", "my_ingress")
+		Expect(resource).To(MatchJQ(".attributes.id", "abc"))
+		Expect(resource).To(MatchJQ(".attributes.default", nil))
+		Expect(resource).To(MatchJQ(".attributes.route_selectors.shard", "public"))
+
+		// Prepare the server for the destroy:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/ingresses/abc",
+				),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "abc",
+				  "default": false,
+				  "listening": "external",
+				  "route_selectors": {
+				    "shard": "public"
+				  },
+				  "dns_name": "apps2.my-cluster.example.com"
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodDelete,
+					"/api/clusters_mgmt/v1/clusters/123/ingresses/abc",
+				),
+				RespondWithJSON(http.StatusNoContent, "{}"),
+			),
+		)
+
+		// Run the destroy command:
+		Expect(terraform.Destroy()).To(BeZero())
+	})
+
+	It("Fails if the listening method isn't valid", func() {
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_cluster_ingress" "my_ingress" {
+		    cluster   = "123"
+		    listening = "public"
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
+})
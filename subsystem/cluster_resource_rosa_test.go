@@ -501,4 +501,396 @@ var _ = Describe("Cluster creation", func() {
 		Expect(terraform.Apply()).To(BeZero())
 	})
 
+	It("Creates rosa sts cluster with an OIDC configuration", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, "/api/clusters_mgmt/v1/clusters"),
+				VerifyJQ(`.name`, "my-cluster"),
+				VerifyJQ(`.aws.sts.role_arn`, "arn:aws:iam::account-id:role/ManagedOpenShift-Installer-Role"),
+				VerifyJQ(`.aws.sts.operator_role_prefix`, "terraform-operator"),
+				VerifyJQ(`.aws.sts.oidc_config.id`, "456"),
+				RespondWithPatchedJSON(http.StatusCreated, template, `[
+					{
+					  "op": "add",
+					  "path": "/aws",
+					  "value": {
+						  "sts" : {
+							  "oidc_endpoint_url": "https://oidc.example.com/456",
+							  "role_arn": "arn:aws:iam::account-id:role/ManagedOpenShift-Installer-Role",
+							  "support_role_arn": "arn:aws:iam::account-id:role/ManagedOpenShift-Support-Role",
+							  "instance_iam_roles" : {
+								"master_role_arn" : "arn:aws:iam::account-id:role/ManagedOpenShift-ControlPlane-Role",
+								"worker_role_arn" : "arn:aws:iam::account-id:role/ManagedOpenShift-Worker-Role"
+							  },
+							  "operator_role_prefix" : "terraform-operator",
+							  "oidc_config": {
+								  "id": "456"
+							  }
+						  }
+					  }
+					}
+				  ]`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_cluster_rosa_classic" "my_cluster" {
+		    name           = "my-cluster"
+		    cloud_region   = "us-west-1"
+		    aws_account_id = "123"
+		    sts = {
+		      role_arn = "arn:aws:iam::account-id:role/ManagedOpenShift-Installer-Role",
+		      support_role_arn = "arn:aws:iam::account-id:role/ManagedOpenShift-Support-Role",
+		      instance_iam_roles = {
+		        master_role_arn = "arn:aws:iam::account-id:role/ManagedOpenShift-ControlPlane-Role",
+		        worker_role_arn = "arn:aws:iam::account-id:role/ManagedOpenShift-Worker-Role"
+		      },
+		      operator_role_prefix = "terraform-operator"
+		      oidc_config_id = "456"
+		    }
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_cluster_rosa_classic", "my_cluster")
+		Expect(resource).To(MatchJQ(".attributes.sts.oidc_config_id", "456"))
+		Expect(resource).To(MatchJQ(".attributes.sts.oidc_endpoint_url", "oidc.example.com/456"))
+	})
+
+	It("Waits till the cluster is ready when requested", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, "/api/clusters_mgmt/v1/clusters"),
+				RespondWithPatchedJSON(http.StatusCreated, template, `[
+				  {
+				    "op": "replace",
+				    "path": "/state",
+				    "value": "installing"
+				  }
+				]`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithPatchedJSON(http.StatusOK, template, `[
+				  {
+				    "op": "replace",
+				    "path": "/state",
+				    "value": "installing"
+				  }
+				]`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusOK, template),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_cluster_rosa_classic" "my_cluster" {
+		    name           = "my-cluster"
+		    cloud_region   = "us-west-1"
+		    aws_account_id = "123"
+		    wait           = true
+		    timeouts = {
+		      poll_interval = "1s"
+		    }
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_cluster_rosa_classic", "my_cluster")
+		Expect(resource).To(MatchJQ(".attributes.state", "ready"))
+	})
+
+	It("Fails with the provision error message if the cluster can't be installed", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, "/api/clusters_mgmt/v1/clusters"),
+				RespondWithPatchedJSON(http.StatusCreated, template, `[
+				  {
+				    "op": "replace",
+				    "path": "/state",
+				    "value": "installing"
+				  }
+				]`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithPatchedJSON(http.StatusOK, template, `[
+				  {
+				    "op": "replace",
+				    "path": "/state",
+				    "value": "error"
+				  }
+				]`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123/status"),
+				RespondWithJSON(http.StatusOK, `{
+				  "state": "error",
+				  "provision_error_code": "OCM3999",
+				  "provision_error_message": "Install failed"
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_cluster_rosa_classic" "my_cluster" {
+		    name           = "my-cluster"
+		    cloud_region   = "us-west-1"
+		    aws_account_id = "123"
+		    wait           = true
+		    timeouts = {
+		      poll_interval = "1s"
+		    }
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
+
+	It("Waits till the cluster is uninstalled when requested", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, "/api/clusters_mgmt/v1/clusters"),
+				RespondWithJSON(http.StatusCreated, template),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_cluster_rosa_classic" "my_cluster" {
+		    name           = "my-cluster"
+		    cloud_region   = "us-west-1"
+		    aws_account_id = "123"
+		    wait           = true
+		    timeouts = {
+		      poll_interval = "1s"
+		    }
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Prepare the server for the refresh, the deletion and the polling:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusOK, template),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodDelete, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusNoContent, "{}"),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithPatchedJSON(http.StatusOK, template, `[
+				  {
+				    "op": "replace",
+				    "path": "/state",
+				    "value": "uninstalling"
+				  }
+				]`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusNotFound, `{
+				  "kind": "Error",
+				  "id": "404",
+				  "code": "CLUSTERS-MGMT-404",
+				  "reason": "Cluster '123' not found"
+				}`),
+			),
+		)
+
+		// Run the destroy command:
+		Expect(terraform.Destroy()).To(BeZero())
+	})
+
+	Context("Version upgrades", func() {
+		// This patch replaces the version of the template with a version that has the
+		// `openshift-v` prefix, like the versions returned by the real server:
+		const versionPatch = `[
+		  {
+		    "op": "replace",
+		    "path": "/version",
+		    "value": {
+		      "id": "openshift-v4.10.1"
+		    }
+		  }
+		]`
+
+		BeforeEach(func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(http.MethodPost, "/api/clusters_mgmt/v1/clusters"),
+					VerifyJQ(`.version.id`, "openshift-v4.10.1"),
+					RespondWithPatchedJSON(http.StatusCreated, template, versionPatch),
+				),
+			)
+
+			// Run the apply command:
+			terraform.Source(`
+			  resource "ocm_cluster_rosa_classic" "my_cluster" {
+			    name           = "my-cluster"
+			    cloud_region   = "us-west-1"
+			    aws_account_id = "123"
+			    version        = "openshift-v4.10.1"
+			  }
+			`)
+			Expect(terraform.Apply()).To(BeZero())
+		})
+
+		It("Schedules an upgrade when the version changes", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+					RespondWithPatchedJSON(http.StatusOK, template, versionPatch),
+				),
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+					RespondWithPatchedJSON(http.StatusOK, template, versionPatch),
+				),
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodGet,
+						"/api/clusters_mgmt/v1/versions/openshift-v4.10.1",
+					),
+					RespondWithJSON(http.StatusOK, `{
+					  "id": "openshift-v4.10.1",
+					  "raw_id": "4.10.1",
+					  "available_upgrades": ["4.10.2", "4.10.3"]
+					}`),
+				),
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodGet,
+						"/api/clusters_mgmt/v1/clusters/123/upgrade_policies",
+					),
+					RespondWithJSON(http.StatusOK, `{
+					  "kind": "UpgradePolicyList",
+					  "page": 1,
+					  "size": 0,
+					  "total": 0,
+					  "items": []
+					}`),
+				),
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodPost,
+						"/api/clusters_mgmt/v1/clusters/123/upgrade_policies",
+					),
+					VerifyJQ(`.schedule_type`, "manual"),
+					VerifyJQ(`.upgrade_type`, "OSD"),
+					VerifyJQ(`.version`, "4.10.3"),
+					RespondWithJSON(http.StatusCreated, `{
+					  "id": "456",
+					  "schedule_type": "manual",
+					  "upgrade_type": "OSD",
+					  "version": "4.10.3"
+					}`),
+				),
+				CombineHandlers(
+					VerifyRequest(http.MethodPatch, "/api/clusters_mgmt/v1/clusters/123"),
+					RespondWithPatchedJSON(http.StatusOK, template, versionPatch),
+				),
+			)
+
+			// Run the apply command:
+			terraform.Source(`
+			  resource "ocm_cluster_rosa_classic" "my_cluster" {
+			    name           = "my-cluster"
+			    cloud_region   = "us-west-1"
+			    aws_account_id = "123"
+			    version        = "openshift-v4.10.3"
+			  }
+			`)
+			Expect(terraform.Apply()).To(BeZero())
+
+			// Check the state:
+			resource := terraform.Resource("ocm_cluster_rosa_classic", "my_cluster")
+			Expect(resource).To(MatchJQ(".attributes.version", "openshift-v4.10.3"))
+
+			// Prepare the server so that the cluster still has the old version, but the
+			// upgrade policy is still pending:
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+					RespondWithPatchedJSON(http.StatusOK, template, versionPatch),
+				),
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodGet,
+						"/api/clusters_mgmt/v1/clusters/123/upgrade_policies",
+					),
+					RespondWithJSON(http.StatusOK, `{
+					  "kind": "UpgradePolicyList",
+					  "page": 1,
+					  "size": 1,
+					  "total": 1,
+					  "items": [
+					    {
+					      "id": "456",
+					      "schedule_type": "manual",
+					      "upgrade_type": "OSD",
+					      "version": "4.10.3"
+					    }
+					  ]
+					}`),
+				),
+			)
+
+			// Run the apply command again, it shouldn't try to schedule the upgrade again:
+			Expect(terraform.Apply()).To(BeZero())
+
+			// Check that the state still contains the requested version:
+			resource = terraform.Resource("ocm_cluster_rosa_classic", "my_cluster")
+			Expect(resource).To(MatchJQ(".attributes.version", "openshift-v4.10.3"))
+		})
+
+		It("Rejects a version that isn't an available upgrade", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+					RespondWithPatchedJSON(http.StatusOK, template, versionPatch),
+				),
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+					RespondWithPatchedJSON(http.StatusOK, template, versionPatch),
+				),
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodGet,
+						"/api/clusters_mgmt/v1/versions/openshift-v4.10.1",
+					),
+					RespondWithJSON(http.StatusOK, `{
+					  "id": "openshift-v4.10.1",
+					  "raw_id": "4.10.1",
+					  "available_upgrades": ["4.10.2", "4.10.3"]
+					}`),
+				),
+			)
+
+			// Run the apply command:
+			terraform.Source(`
+			  resource "ocm_cluster_rosa_classic" "my_cluster" {
+			    name           = "my-cluster"
+			    cloud_region   = "us-west-1"
+			    aws_account_id = "123"
+			    version        = "openshift-v4.12.0"
+			  }
+			`)
+			Expect(terraform.Apply()).ToNot(BeZero())
+		})
+	})
 })
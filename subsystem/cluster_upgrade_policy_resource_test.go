@@ -0,0 +1,425 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/onsi/gomega/ghttp"                   // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cluster upgrade policy creation", func() {
+	BeforeEach(func() {
+		// The first thing that the provider will do when creating an upgrade policy is
+		// check that the cluster is ready, so we always need to prepare the server to
+		// respond to that:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "123",
+				  "name": "my-cluster",
+				  "state": "ready",
+				  "version": {
+				    "id": "openshift-v4.10.1"
+				  }
+				}`),
+			),
+		)
+	})
+
+	It("Can create a manual upgrade policy", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/versions/openshift-v4.10.1",
+				),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "openshift-v4.10.1",
+				  "raw_id": "4.10.1",
+				  "available_upgrades": ["4.10.2", "4.10.3"]
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPatch,
+					"/api/clusters_mgmt/v1/clusters/123",
+				),
+				VerifyJSON(`{
+				  "kind": "Cluster",
+				  "node_drain_grace_period": {
+				    "value": 30,
+				    "unit": "minutes"
+				  }
+				}`),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "123",
+				  "node_drain_grace_period": {
+				    "value": 30,
+				    "unit": "minutes"
+				  }
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/upgrade_policies",
+				),
+				VerifyJSON(`{
+				  "kind": "UpgradePolicy",
+				  "schedule_type": "manual",
+				  "upgrade_type": "OSD",
+				  "version": "4.10.3",
+				  "next_run": "2040-01-01T20:00:00Z"
+				}`),
+				RespondWithJSON(http.StatusCreated, `{
+				  "id": "456",
+				  "cluster_id": "123",
+				  "schedule_type": "manual",
+				  "upgrade_type": "OSD",
+				  "version": "4.10.3",
+				  "next_run": "2040-01-01T20:00:00Z"
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_cluster_upgrade_policy" "my_policy" {
+		    cluster                 = "123"
+		    schedule_type           = "manual"
+		    version                 = "4.10.3"
+		    next_run                = "2040-01-01T20:00:00Z"
+		    node_drain_grace_period = 30
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_cluster_upgrade_policy", "my_policy")
+		Expect(resource).To(MatchJQ(".attributes.cluster", "123"))
+		Expect(resource).To(MatchJQ(".attributes.id", "456"))
+		Expect(resource).To(MatchJQ(".attributes.schedule_type", "manual"))
+		Expect(resource).To(MatchJQ(".attributes.version", "4.10.3"))
+		Expect(resource).To(MatchJQ(".attributes.next_run", "2040-01-01T20:00:00Z"))
+		Expect(resource).To(MatchJQ(".attributes.node_drain_grace_period", 30.0))
+	})
+
+	It("Can create an automatic upgrade policy", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/upgrade_policies",
+				),
+				VerifyJSON(`{
+				  "kind": "UpgradePolicy",
+				  "schedule_type": "automatic",
+				  "upgrade_type": "OSD",
+				  "schedule": "0 20 * * 6"
+				}`),
+				RespondWithJSON(http.StatusCreated, `{
+				  "id": "456",
+				  "cluster_id": "123",
+				  "schedule_type": "automatic",
+				  "upgrade_type": "OSD",
+				  "schedule": "0 20 * * 6",
+				  "next_run": "2040-01-07T20:00:00Z"
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_cluster_upgrade_policy" "my_policy" {
+		    cluster       = "123"
+		    schedule_type = "automatic"
+		    schedule      = "0 20 * * 6"
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_cluster_upgrade_policy", "my_policy")
+		Expect(resource).To(MatchJQ(".attributes.id", "456"))
+		Expect(resource).To(MatchJQ(".attributes.schedule_type", "automatic"))
+		Expect(resource).To(MatchJQ(".attributes.schedule", "0 20 * * 6"))
+		Expect(resource).To(MatchJQ(".attributes.next_run", "2040-01-07T20:00:00Z"))
+		Expect(resource).To(MatchJQ(".attributes.version", nil))
+	})
+
+	It("Fails if the version isn't an available upgrade", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/versions/openshift-v4.10.1",
+				),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "openshift-v4.10.1",
+				  "raw_id": "4.10.1",
+				  "available_upgrades": ["4.10.2"]
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_cluster_upgrade_policy" "my_policy" {
+		    cluster       = "123"
+		    schedule_type = "manual"
+		    version       = "4.11.0"
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
+
+	It("Fails if the new version isn't an available upgrade", func() {
+		// Prepare the server:
+		const policy = `{
+		  "id": "456",
+		  "cluster_id": "123",
+		  "schedule_type": "manual",
+		  "upgrade_type": "OSD",
+		  "version": "4.10.2",
+		  "next_run": "2040-01-01T20:00:00Z"
+		}`
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/versions/openshift-v4.10.1",
+				),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "openshift-v4.10.1",
+				  "raw_id": "4.10.1",
+				  "available_upgrades": ["4.10.2"]
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/upgrade_policies",
+				),
+				RespondWithJSON(http.StatusCreated, policy),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_cluster_upgrade_policy" "my_policy" {
+		    cluster       = "123"
+		    schedule_type = "manual"
+		    version       = "4.10.2"
+		    next_run      = "2040-01-01T20:00:00Z"
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Prepare the server for the update:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/upgrade_policies/456",
+				),
+				RespondWithJSON(http.StatusOK, policy),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "123",
+				  "name": "my-cluster",
+				  "state": "ready",
+				  "version": {
+				    "id": "openshift-v4.10.1"
+				  }
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/versions/openshift-v4.10.1",
+				),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "openshift-v4.10.1",
+				  "raw_id": "4.10.1",
+				  "available_upgrades": ["4.10.2"]
+				}`),
+			),
+		)
+
+		// Run the apply command again with a version that isn't available:
+		terraform.Source(`
+		  resource "ocm_cluster_upgrade_policy" "my_policy" {
+		    cluster       = "123"
+		    schedule_type = "manual"
+		    version       = "4.11.0"
+		    next_run      = "2040-01-01T20:00:00Z"
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
+
+	It("Creates a new policy when the version changes after the upgrade", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/versions/openshift-v4.10.1",
+				),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "openshift-v4.10.1",
+				  "raw_id": "4.10.1",
+				  "available_upgrades": ["4.10.2"]
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/upgrade_policies",
+				),
+				RespondWithJSON(http.StatusCreated, `{
+				  "id": "456",
+				  "cluster_id": "123",
+				  "schedule_type": "manual",
+				  "upgrade_type": "OSD",
+				  "version": "4.10.2",
+				  "next_run": "2040-01-01T20:00:00Z"
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_cluster_upgrade_policy" "my_policy" {
+		    cluster       = "123"
+		    schedule_type = "manual"
+		    version       = "4.10.2"
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Prepare the server so that the upgrade has been completed and the policy has
+		// been deleted, and then for the creation of the new policy:
+		const upgradedCluster = `{
+		  "id": "123",
+		  "name": "my-cluster",
+		  "state": "ready",
+		  "version": {
+		    "id": "openshift-v4.10.2"
+		  }
+		}`
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/upgrade_policies/456",
+				),
+				RespondWithJSON(http.StatusNotFound, `{}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusOK, upgradedCluster),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusOK, upgradedCluster),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/versions/openshift-v4.10.2",
+				),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "openshift-v4.10.2",
+				  "raw_id": "4.10.2",
+				  "available_upgrades": ["4.10.3"]
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPatch,
+					"/api/clusters_mgmt/v1/clusters/123/upgrade_policies/456",
+				),
+				RespondWithJSON(http.StatusNotFound, `{}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/upgrade_policies",
+				),
+				VerifyJQ(`.schedule_type`, "manual"),
+				VerifyJQ(`.version`, "4.10.3"),
+				RespondWithJSON(http.StatusCreated, `{
+				  "id": "789",
+				  "cluster_id": "123",
+				  "schedule_type": "manual",
+				  "upgrade_type": "OSD",
+				  "version": "4.10.3",
+				  "next_run": "2040-01-02T20:00:00Z"
+				}`),
+			),
+		)
+
+		// Run the apply command again with the next version:
+		terraform.Source(`
+		  resource "ocm_cluster_upgrade_policy" "my_policy" {
+		    cluster       = "123"
+		    schedule_type = "manual"
+		    version       = "4.10.3"
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_cluster_upgrade_policy", "my_policy")
+		Expect(resource).To(MatchJQ(".attributes.id", "789"))
+		Expect(resource).To(MatchJQ(".attributes.version", "4.10.3"))
+	})
+
+	It("Fails if the schedule type isn't valid", func() {
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_cluster_upgrade_policy" "my_policy" {
+		    cluster       = "123"
+		    schedule_type = "sometimes"
+		    schedule      = "0 20 * * 6"
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
+
+	It("Fails if an automatic policy doesn't have a schedule", func() {
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_cluster_upgrade_policy" "my_policy" {
+		    cluster       = "123"
+		    schedule_type = "automatic"
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
+})
@@ -0,0 +1,146 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/onsi/gomega/ghttp"                   // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Group members", func() {
+	BeforeEach(func() {
+		// The first thing that the provider will do when creating the resource is check
+		// that the cluster is ready, so we always need to prepare the server to respond to
+		// that:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "123",
+				  "name": "my-cluster",
+				  "state": "ready"
+				}`),
+			),
+		)
+	})
+
+	It("Adds the missing members and removes the ones that aren't configured", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/groups/dedicated-admins",
+				),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "dedicated-admins"
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/groups/dedicated-admins/users",
+				),
+				RespondWithJSON(http.StatusOK, `{
+				  "page": 1,
+				  "size": 2,
+				  "total": 2,
+				  "items": [
+				    {
+				      "id": "my-admin"
+				    },
+				    {
+				      "id": "intruder"
+				    }
+				  ]
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodDelete,
+					"/api/clusters_mgmt/v1/clusters/123/groups/dedicated-admins/users/intruder",
+				),
+				RespondWithJSON(http.StatusNoContent, `{}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/groups/dedicated-admins/users",
+				),
+				VerifyJSON(`{
+				  "kind": "User",
+				  "id": "your-admin"
+				}`),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "your-admin"
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_group_members" "my_members" {
+		    cluster = "123"
+		    group   = "dedicated-admins"
+		    users   = ["my-admin", "your-admin"]
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_group_members", "my_members")
+		Expect(resource).To(MatchJQ(".attributes.id", "dedicated-admins"))
+		Expect(resource).To(MatchJQ(".attributes.users | length", 2))
+	})
+
+	It("Fails if the group doesn't exist", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123/groups/junk"),
+				RespondWithJSON(http.StatusNotFound, `{}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123/groups"),
+				RespondWithJSON(http.StatusOK, `{
+				  "page": 1,
+				  "size": 1,
+				  "total": 1,
+				  "items": [
+				    {
+				      "id": "dedicated-admins"
+				    }
+				  ]
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_group_members" "my_members" {
+		    cluster = "123"
+		    group   = "junk"
+		    users   = ["my-admin"]
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
+})
@@ -77,4 +77,81 @@ var _ = Describe("Group membership creation", func() {
 		Expect(resource).To(MatchJQ(".attributes.id", "my-admin"))
 		Expect(resource).To(MatchJQ(".attributes.user", "my-admin"))
 	})
+
+	It("Explains that the group doesn't exist", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/groups/junk/users",
+				),
+				RespondWithJSON(http.StatusNotFound, `{}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123/groups/junk"),
+				RespondWithJSON(http.StatusNotFound, `{}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123/groups"),
+				RespondWithJSON(http.StatusOK, `{
+				  "page": 1,
+				  "size": 1,
+				  "total": 1,
+				  "items": [
+				    {
+				      "id": "dedicated-admins"
+				    }
+				  ]
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_group_membership" "my_membership" {
+		    cluster   = "123"
+		    group     = "junk"
+		    user      = "my-admin"
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
+})
+
+var _ = Describe("Group membership import", func() {
+	It("Can import a membership using the cluster, group and user identifiers", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/groups/dedicated-admins/users/my-admin",
+				),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "my-admin"
+				}`),
+			),
+		)
+
+		// Run the import command:
+		terraform.Source(`
+		  resource "ocm_group_membership" "my_membership" {
+		    cluster = "123"
+		    group   = "dedicated-admins"
+		    user    = "my-admin"
+		  }
+		`)
+		Expect(terraform.Import(
+			"ocm_group_membership.my_membership",
+			"123,dedicated-admins,my-admin",
+		)).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_group_membership", "my_membership")
+		Expect(resource).To(MatchJQ(".attributes.cluster", "123"))
+		Expect(resource).To(MatchJQ(".attributes.group", "dedicated-admins"))
+		Expect(resource).To(MatchJQ(".attributes.id", "my-admin"))
+		Expect(resource).To(MatchJQ(".attributes.user", "my-admin"))
+	})
 })
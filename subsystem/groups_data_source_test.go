@@ -37,7 +37,17 @@ var _ = Describe("Groups data source", func() {
 				  "total": 1,
 				  "items": [
 				    {
-				      "id": "dedicated-admins"
+				      "id": "dedicated-admins",
+				      "users": {
+				        "items": [
+				          {
+				            "id": "your-admin"
+				          },
+				          {
+				            "id": "my-admin"
+				          }
+				        ]
+				      }
 				    }
 				  ]
 				}`),
@@ -57,5 +67,8 @@ var _ = Describe("Groups data source", func() {
 		Expect(resource).To(MatchJQ(`.attributes.items |length`, 1))
 		Expect(resource).To(MatchJQ(`.attributes.items[0].id`, "dedicated-admins"))
 		Expect(resource).To(MatchJQ(`.attributes.items[0].name`, "dedicated-admins"))
+		Expect(resource).To(MatchJQ(`.attributes.items[0].users |length`, 2))
+		Expect(resource).To(MatchJQ(`.attributes.items[0].users[0]`, "my-admin"))
+		Expect(resource).To(MatchJQ(`.attributes.items[0].users[1]`, "your-admin"))
 	})
 })
@@ -0,0 +1,178 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/onsi/gomega/ghttp"                   // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("HTPasswd user creation", func() {
+	// This is the response that the server returns for the user created by all the tests,
+	// note that it doesn't contain the password:
+	const userResponse = `{
+	  "id": "789",
+	  "username": "my-user"
+	}`
+
+	BeforeEach(func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "123",
+				  "name": "my-cluster",
+				  "state": "ready"
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/identity_providers/456/htpasswd_users",
+				),
+				VerifyJSON(`{
+				  "kind": "HTPasswdUser",
+				  "username": "my-user",
+				  "password": "My-Password-1234"
+				}`),
+				RespondWithJSON(http.StatusCreated, userResponse),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_htpasswd_user" "my_user" {
+		    cluster           = "123"
+		    identity_provider = "456"
+		    username          = "my-user"
+		    password          = "My-Password-1234"
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_htpasswd_user", "my_user")
+		Expect(resource).To(MatchJQ(".attributes.id", "789"))
+		Expect(resource).To(MatchJQ(".attributes.password", "My-Password-1234"))
+	})
+
+	It("Updates the password in place", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/identity_providers/456/htpasswd_users/789",
+				),
+				RespondWithJSON(http.StatusOK, userResponse),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPatch,
+					"/api/clusters_mgmt/v1/clusters/123/identity_providers/456/htpasswd_users/789",
+				),
+				VerifyJSON(`{
+				  "kind": "HTPasswdUser",
+				  "password": "My-New-Password-1234"
+				}`),
+				RespondWithJSON(http.StatusOK, userResponse),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_htpasswd_user" "my_user" {
+		    cluster           = "123"
+		    identity_provider = "456"
+		    username          = "my-user"
+		    password          = "My-New-Password-1234"
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_htpasswd_user", "my_user")
+		Expect(resource).To(MatchJQ(".attributes.id", "789"))
+		Expect(resource).To(MatchJQ(".attributes.password", "My-New-Password-1234"))
+	})
+
+	It("Deletes the user", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/identity_providers/456/htpasswd_users/789",
+				),
+				RespondWithJSON(http.StatusOK, userResponse),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodDelete,
+					"/api/clusters_mgmt/v1/clusters/123/identity_providers/456/htpasswd_users/789",
+				),
+				RespondWithJSON(http.StatusNoContent, "{}"),
+			),
+		)
+
+		// Run the destroy command:
+		Expect(terraform.Destroy()).To(BeZero())
+	})
+
+	It("Removes the user from the state if it no longer exists", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/identity_providers/456/htpasswd_users/789",
+				),
+				RespondWithJSON(http.StatusNotFound, `{}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "123",
+				  "name": "my-cluster",
+				  "state": "ready"
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/identity_providers/456/htpasswd_users",
+				),
+				RespondWithJSON(http.StatusCreated, `{
+				  "id": "790",
+				  "username": "my-user"
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_htpasswd_user", "my_user")
+		Expect(resource).To(MatchJQ(".attributes.id", "790"))
+	})
+})
@@ -17,6 +17,7 @@ limitations under the License.
 package provider
 
 import (
+	"fmt"
 	"net/http"
 
 	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
@@ -25,6 +26,20 @@ import (
 	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
 )
 
+// testCA is a self signed certificate used to check the attributes that contain certificates of
+// certificate authorities.
+const testCA = `-----BEGIN CERTIFICATE-----
+MIIBdzCCAR2gAwIBAgIUHE8xwyI1ZeG0gp85F5kKqTAU6mYwCgYIKoZIzj0EAwIw
+EDEOMAwGA1UEAwwFbXktY2EwIBcNMjYxMDE3MTgzMDAyWhgPMjEyNjA5MjMxODMw
+MDJaMBAxDjAMBgNVBAMMBW15LWNhMFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE
+sVlQybqupu7LyoeEs70q0+imbCu9lwpvBh/AI2qPQ9S9GFChYNFrhE3rshM+448Y
+Mu/7IYfjkaTbuUYBfjF4CqNTMFEwHQYDVR0OBBYEFKET2BboF6lews6kK1DX2ax0
+LTw1MB8GA1UdIwQYMBaAFKET2BboF6lews6kK1DX2ax0LTw1MA8GA1UdEwEB/wQF
+MAMBAf8wCgYIKoZIzj0EAwIDSAAwRQIhAO6CXwGhEFLWb+JVUY/9jun8ik2AkDQI
+5R1aH7ubjZhQAiA9dojpMAAaTt4P7R1QWR1yQoTp1Oj9HPXEEnuWCyNxSA==
+-----END CERTIFICATE-----
+`
+
 var _ = Describe("Identity provider creation", func() {
 	BeforeEach(func() {
 		// The first thing that the provider will do for any operation on identity providers
@@ -55,7 +70,7 @@ var _ = Describe("Identity provider creation", func() {
 				  "type": "HTPasswdIdentityProvider",
 				  "name": "my-ip",
 				  "htpasswd": {
-				    "password": "my-password",
+				    "password": "My-Password-1234",
 				    "username": "my-user"
 				  }
 				}`),
@@ -76,7 +91,7 @@ var _ = Describe("Identity provider creation", func() {
 		    name    = "my-ip"
 		    htpasswd = {
 		      username = "my-user"
-		      password = "my-password"
+		      password = "My-Password-1234"
 		    }
 		  }
 		`)
@@ -91,14 +106,14 @@ var _ = Describe("Identity provider creation", func() {
 					http.MethodPost,
 					"/api/clusters_mgmt/v1/clusters/123/identity_providers",
 				),
-				VerifyJSON(`{
+				VerifyJSON(fmt.Sprintf(`{
 				  "kind": "IdentityProvider",
 				  "type": "LDAPIdentityProvider",
 				  "name": "my-ip",
 				  "ldap": {
 				    "bind_dn": "my-bind-dn",
 				    "bind_password": "my-bind-password",
-				    "ca": "my-ca",
+				    "ca": %q,
 				    "insecure": false,
 				    "url": "ldap://my-server.com",
 				    "attributes": {
@@ -108,14 +123,14 @@ var _ = Describe("Identity provider creation", func() {
 				      "preferred_username": ["my-preferred-username"]
 				    }
 				  }
-				}`),
-				RespondWithJSON(http.StatusOK, `{
+				}`, testCA)),
+				RespondWithJSON(http.StatusOK, fmt.Sprintf(`{
 				  "id": "456",
 				  "name": "my-ip",
 				  "ldap": {
 				    "bind_dn": "my-bind-dn",
 				    "bind_password": "my-bind-password",
-				    "ca": "my-ca",
+				    "ca": %q,
 				    "insecure": false,
 				    "url": "ldap://my-server.com",
 				    "attributes": {
@@ -125,12 +140,12 @@ var _ = Describe("Identity provider creation", func() {
 				      "preferred_username": ["my-preferred-username"]
 				    }
 				  }
-				}`),
+				}`, testCA)),
 			),
 		)
 
 		// Run the apply command:
-		terraform.Source(`
+		terraform.Source(fmt.Sprintf(`
 		  resource "ocm_identity_provider" "my_ip" {
 		    cluster    = "123"
 		    name       = "my-ip"
@@ -138,7 +153,7 @@ var _ = Describe("Identity provider creation", func() {
 		      bind_dn       = "my-bind-dn"
 		      bind_password = "my-bind-password"
 		      insecure      = false
-		      ca            = "my-ca"
+		      ca            = %q
 		      url           = "ldap://my-server.com"
 		      attributes    = {
 		        id                 = ["my-id"]
@@ -148,7 +163,7 @@ var _ = Describe("Identity provider creation", func() {
 		      }
 		    }
 		  }
-		`)
+		`, testCA))
 		Expect(terraform.Apply()).To(BeZero())
 	})
 
@@ -160,12 +175,12 @@ var _ = Describe("Identity provider creation", func() {
 					http.MethodPost,
 					"/api/clusters_mgmt/v1/clusters/123/identity_providers",
 				),
-				VerifyJSON(`{
+				VerifyJSON(fmt.Sprintf(`{
 				  "kind": "IdentityProvider",
 				  "type": "OpenIDIdentityProvider",
 				  "name": "my-ip",
 				  "open_id": {
-					"ca": "test_ca",
+					"ca": %q,
 					"claims": {
 						"email": [
 							"email"
@@ -190,7 +205,7 @@ var _ = Describe("Identity provider creation", func() {
 					],
 					"issuer": "https://test.okta.com"
 					}
-				}`),
+				}`, testCA)),
 				RespondWithJSON(http.StatusOK, `{
 					"kind": "IdentityProvider",
 					"type": "OpenIDIdentityProvider",
@@ -226,12 +241,12 @@ var _ = Describe("Identity provider creation", func() {
 		)
 
 		// Run the apply command:
-		terraform.Source(`
+		terraform.Source(fmt.Sprintf(`
 		  resource "ocm_identity_provider" "my_ip" {
 		    cluster    				= "123"
 		    name       				= "my-ip"
 		    openid = {
-				ca            			= "test_ca"
+				ca            			= %q
 				issuer					= "https://test.okta.com"
 				client_id 				= "test_client"
 				client_secret			= "test_secret"
@@ -244,8 +259,798 @@ var _ = Describe("Identity provider creation", func() {
 		      	}
 		    }
 		  }
+		`, testCA))
+		Expect(terraform.Apply()).To(BeZero())
+	})
+
+	It("Can create a GitHub identity provider", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/identity_providers",
+				),
+				VerifyJSON(`{
+				  "kind": "IdentityProvider",
+				  "type": "GithubIdentityProvider",
+				  "name": "my-ip",
+				  "github": {
+				    "client_id": "test_client",
+				    "client_secret": "test_secret",
+				    "organizations": [
+				      "my-org"
+				    ]
+				  }
+				}`),
+				RespondWithJSON(http.StatusOK, `{
+				  "kind": "IdentityProvider",
+				  "type": "GithubIdentityProvider",
+				  "href": "/api/clusters_mgmt/v1/clusters/123/identity_providers/456",
+				  "id": "456",
+				  "name": "my-ip",
+				  "github": {
+				    "client_id": "test_client",
+				    "organizations": [
+				      "my-org"
+				    ]
+				  }
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_identity_provider" "my_ip" {
+		    cluster = "123"
+		    name    = "my-ip"
+		    github = {
+		      client_id     = "test_client"
+		      client_secret = "test_secret"
+		      organizations = ["my-org"]
+		    }
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+	})
+
+	It("Can create a GitLab identity provider", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/identity_providers",
+				),
+				VerifyJSON(`{
+				  "kind": "IdentityProvider",
+				  "type": "GitlabIdentityProvider",
+				  "name": "my-ip",
+				  "gitlab": {
+				    "client_id": "test_client",
+				    "client_secret": "test_secret",
+				    "url": "https://gitlab.com"
+				  }
+				}`),
+				RespondWithJSON(http.StatusOK, `{
+				  "kind": "IdentityProvider",
+				  "type": "GitlabIdentityProvider",
+				  "href": "/api/clusters_mgmt/v1/clusters/123/identity_providers/456",
+				  "id": "456",
+				  "name": "my-ip",
+				  "gitlab": {
+				    "client_id": "test_client",
+				    "url": "https://gitlab.com"
+				  }
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_identity_provider" "my_ip" {
+		    cluster = "123"
+		    name    = "my-ip"
+		    gitlab = {
+		      client_id     = "test_client"
+		      client_secret = "test_secret"
+		      url           = "https://gitlab.com"
+		    }
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+	})
+
+	It("Can create a Google identity provider", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/identity_providers",
+				),
+				VerifyJSON(`{
+				  "kind": "IdentityProvider",
+				  "type": "GoogleIdentityProvider",
+				  "name": "my-ip",
+				  "google": {
+				    "client_id": "test_client",
+				    "client_secret": "test_secret",
+				    "hosted_domain": "example.com"
+				  }
+				}`),
+				RespondWithJSON(http.StatusOK, `{
+				  "kind": "IdentityProvider",
+				  "type": "GoogleIdentityProvider",
+				  "href": "/api/clusters_mgmt/v1/clusters/123/identity_providers/456",
+				  "id": "456",
+				  "name": "my-ip",
+				  "google": {
+				    "client_id": "test_client",
+				    "hosted_domain": "example.com"
+				  }
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_identity_provider" "my_ip" {
+		    cluster = "123"
+		    name    = "my-ip"
+		    google = {
+		      client_id     = "test_client"
+		      client_secret = "test_secret"
+		      hosted_domain = "example.com"
+		    }
+		  }
 		`)
 		Expect(terraform.Apply()).To(BeZero())
 	})
 
+	It("Fails if no identity provider type is set", func() {
+		terraform.Source(`
+		  resource "ocm_identity_provider" "my_ip" {
+		    cluster = "123"
+		    name    = "my-ip"
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
+
+	It("Fails if more than one identity provider type is set", func() {
+		terraform.Source(`
+		  resource "ocm_identity_provider" "my_ip" {
+		    cluster = "123"
+		    name    = "my-ip"
+		    github = {
+		      client_id     = "test_client"
+		      client_secret = "test_secret"
+		      organizations = ["my-org"]
+		    }
+		    google = {
+		      client_id     = "test_client"
+		      client_secret = "test_secret"
+		    }
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
+
+	Context("Validation", func() {
+		It("Rejects an OpenID issuer that doesn't use HTTPS", func() {
+			terraform.Source(`
+			  resource "ocm_identity_provider" "my_ip" {
+			    cluster = "123"
+			    name    = "my-ip"
+			    openid = {
+			      issuer        = "http://test.okta.com"
+			      client_id     = "test_client"
+			      client_secret = "test_secret"
+			      claims = {
+			        email = ["email"]
+			      }
+			    }
+			  }
+			`)
+			Expect(terraform.Apply()).ToNot(BeZero())
+		})
+
+		It("Rejects OpenID claims without entries", func() {
+			terraform.Source(`
+			  resource "ocm_identity_provider" "my_ip" {
+			    cluster = "123"
+			    name    = "my-ip"
+			    openid = {
+			      issuer        = "https://test.okta.com"
+			      client_id     = "test_client"
+			      client_secret = "test_secret"
+			      claims = {
+			        email = []
+			      }
+			    }
+			  }
+			`)
+			Expect(terraform.Apply()).ToNot(BeZero())
+		})
+
+		It("Rejects an LDAP URL with the wrong scheme", func() {
+			terraform.Source(`
+			  resource "ocm_identity_provider" "my_ip" {
+			    cluster = "123"
+			    name    = "my-ip"
+			    ldap = {
+			      bind_dn       = "my-bind-dn"
+			      bind_password = "my-bind-password"
+			      url           = "https://my-server.com"
+			      attributes    = {
+			        id = ["my-id"]
+			      }
+			    }
+			  }
+			`)
+			Expect(terraform.Apply()).ToNot(BeZero())
+		})
+
+		It("Rejects a CA that isn't in PEM format", func() {
+			terraform.Source(`
+			  resource "ocm_identity_provider" "my_ip" {
+			    cluster = "123"
+			    name    = "my-ip"
+			    ldap = {
+			      bind_dn       = "my-bind-dn"
+			      bind_password = "my-bind-password"
+			      ca            = "my-ca"
+			      url           = "ldap://my-server.com"
+			      attributes    = {
+			        id = ["my-id"]
+			      }
+			    }
+			  }
+			`)
+			Expect(terraform.Apply()).ToNot(BeZero())
+		})
+
+		It("Rejects a weak 'htpasswd' password", func() {
+			terraform.Source(`
+			  resource "ocm_identity_provider" "my_ip" {
+			    cluster = "123"
+			    name    = "my-ip"
+			    htpasswd = {
+			      username = "my-user"
+			      password = "my-password"
+			    }
+			  }
+			`)
+			Expect(terraform.Apply()).ToNot(BeZero())
+		})
+	})
+
+	Context("HTPasswd users", func() {
+		// This is the response that the server returns for the 'htpasswd' identity provider
+		// created by all the tests:
+		const htpasswdResponse = `{
+		  "id": "456",
+		  "name": "my-ip",
+		  "type": "HTPasswdIdentityProvider",
+		  "htpasswd": {}
+		}`
+
+		// This is the list of users that the server returns after creating the identity
+		// provider, note that it doesn't contain the passwords:
+		const usersResponse = `{
+		  "page": 1,
+		  "size": 2,
+		  "total": 2,
+		  "items": [
+		    {
+		      "id": "u1",
+		      "username": "alice"
+		    },
+		    {
+		      "id": "u2",
+		      "username": "bob"
+		    }
+		  ]
+		}`
+
+		BeforeEach(func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodPost,
+						"/api/clusters_mgmt/v1/clusters/123/identity_providers",
+					),
+					VerifyJSON(`{
+					  "kind": "IdentityProvider",
+					  "type": "HTPasswdIdentityProvider",
+					  "name": "my-ip",
+					  "htpasswd": {
+					    "users": {
+					      "items": [
+					        {
+					          "username": "alice",
+					          "password": "Alice-Password-1"
+					        },
+					        {
+					          "username": "bob",
+					          "password": "Bob-Password-123"
+					        }
+					      ]
+					    }
+					  }
+					}`),
+					RespondWithJSON(http.StatusOK, htpasswdResponse),
+				),
+			)
+
+			// Run the apply command:
+			terraform.Source(`
+			  resource "ocm_identity_provider" "my_ip" {
+			    cluster = "123"
+			    name    = "my-ip"
+			    htpasswd = {
+			      users = [
+			        {
+			          username = "alice"
+			          password = "Alice-Password-1"
+			        },
+			        {
+			          username = "bob"
+			          password = "Bob-Password-123"
+			        }
+			      ]
+			    }
+			  }
+			`)
+			Expect(terraform.Apply()).To(BeZero())
+		})
+
+		It("Adds, updates and removes users without replacing the identity provider", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodGet,
+						"/api/clusters_mgmt/v1/clusters/123/identity_providers/456",
+					),
+					RespondWithJSON(http.StatusOK, htpasswdResponse),
+				),
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodGet,
+						"/api/clusters_mgmt/v1/clusters/123/identity_providers/456/htpasswd_users",
+					),
+					RespondWithJSON(http.StatusOK, usersResponse),
+				),
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodGet,
+						"/api/clusters_mgmt/v1/clusters/123/identity_providers/456/htpasswd_users",
+					),
+					RespondWithJSON(http.StatusOK, usersResponse),
+				),
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodDelete,
+						"/api/clusters_mgmt/v1/clusters/123/identity_providers/456/htpasswd_users/u2",
+					),
+					RespondWithJSON(http.StatusNoContent, "{}"),
+				),
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodPatch,
+						"/api/clusters_mgmt/v1/clusters/123/identity_providers/456/htpasswd_users/u1",
+					),
+					VerifyJSON(`{
+					  "kind": "HTPasswdUser",
+					  "password": "Alice-New-Password-1"
+					}`),
+					RespondWithJSON(http.StatusOK, `{
+					  "id": "u1",
+					  "username": "alice"
+					}`),
+				),
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodPost,
+						"/api/clusters_mgmt/v1/clusters/123/identity_providers/456/htpasswd_users",
+					),
+					VerifyJSON(`{
+					  "kind": "HTPasswdUser",
+					  "username": "carol",
+					  "password": "Carol-Password-1"
+					}`),
+					RespondWithJSON(http.StatusCreated, `{
+					  "id": "u3",
+					  "username": "carol"
+					}`),
+				),
+			)
+
+			// Run the apply command:
+			terraform.Source(`
+			  resource "ocm_identity_provider" "my_ip" {
+			    cluster = "123"
+			    name    = "my-ip"
+			    htpasswd = {
+			      users = [
+			        {
+			          username = "alice"
+			          password = "Alice-New-Password-1"
+			        },
+			        {
+			          username = "carol"
+			          password = "Carol-Password-1"
+			        }
+			      ]
+			    }
+			  }
+			`)
+			Expect(terraform.Apply()).To(BeZero())
+
+			// Check the state:
+			resource := terraform.Resource("ocm_identity_provider", "my_ip")
+			Expect(resource).To(MatchJQ(".attributes.id", "456"))
+			Expect(resource).To(MatchJQ(".attributes.htpasswd.users | length", 2))
+			Expect(resource).To(MatchJQ(".attributes.htpasswd.users[1].username", "carol"))
+		})
+	})
+
+	Context("Updates", func() {
+		// This is the response that the server returns for the LDAP identity provider
+		// created by all the tests, note that it doesn't contain the bind password:
+		const ldapResponse = `{
+		  "id": "456",
+		  "name": "my-ip",
+		  "type": "LDAPIdentityProvider",
+		  "ldap": {
+		    "bind_dn": "my-bind-dn",
+		    "insecure": false,
+		    "url": "ldap://my-server.com",
+		    "attributes": {
+		      "id": ["my-id"]
+		    }
+		  }
+		}`
+
+		BeforeEach(func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodPost,
+						"/api/clusters_mgmt/v1/clusters/123/identity_providers",
+					),
+					RespondWithJSON(http.StatusOK, ldapResponse),
+				),
+			)
+
+			// Run the apply command:
+			terraform.Source(`
+			  resource "ocm_identity_provider" "my_ip" {
+			    cluster = "123"
+			    name    = "my-ip"
+			    ldap = {
+			      bind_dn       = "my-bind-dn"
+			      bind_password = "my-bind-password"
+			      url           = "ldap://my-server.com"
+			      attributes    = {
+			        id = ["my-id"]
+			      }
+			    }
+			  }
+			`)
+			Expect(terraform.Apply()).To(BeZero())
+		})
+
+		It("Updates the details in place", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodGet,
+						"/api/clusters_mgmt/v1/clusters/123/identity_providers/456",
+					),
+					RespondWithJSON(http.StatusOK, ldapResponse),
+				),
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodPatch,
+						"/api/clusters_mgmt/v1/clusters/123/identity_providers/456",
+					),
+					VerifyJSON(`{
+					  "kind": "IdentityProvider",
+					  "type": "LDAPIdentityProvider",
+					  "mapping_method": "claim",
+					  "ldap": {
+					    "bind_dn": "my-bind-dn",
+					    "bind_password": "my-new-bind-password",
+					    "insecure": false,
+					    "url": "ldaps://my-new-server.com",
+					    "attributes": {
+					      "id": ["my-id"]
+					    }
+					  }
+					}`),
+					RespondWithPatchedJSON(http.StatusOK, ldapResponse, `[
+					  {
+					    "op": "replace",
+					    "path": "/ldap/url",
+					    "value": "ldaps://my-new-server.com"
+					  }
+					]`),
+				),
+			)
+
+			// Run the apply command:
+			terraform.Source(`
+			  resource "ocm_identity_provider" "my_ip" {
+			    cluster = "123"
+			    name    = "my-ip"
+			    ldap = {
+			      bind_dn       = "my-bind-dn"
+			      bind_password = "my-new-bind-password"
+			      url           = "ldaps://my-new-server.com"
+			      attributes    = {
+			        id = ["my-id"]
+			      }
+			    }
+			  }
+			`)
+			Expect(terraform.Apply()).To(BeZero())
+
+			// Check the state:
+			resource := terraform.Resource("ocm_identity_provider", "my_ip")
+			Expect(resource).To(MatchJQ(".attributes.id", "456"))
+			Expect(resource).To(MatchJQ(".attributes.ldap.url", "ldaps://my-new-server.com"))
+			Expect(resource).To(MatchJQ(
+				".attributes.ldap.bind_password", "my-new-bind-password",
+			))
+		})
+
+		It("Updates the mapping method in place", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodGet,
+						"/api/clusters_mgmt/v1/clusters/123/identity_providers/456",
+					),
+					RespondWithJSON(http.StatusOK, ldapResponse),
+				),
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodPatch,
+						"/api/clusters_mgmt/v1/clusters/123/identity_providers/456",
+					),
+					VerifyJQ(".mapping_method", "lookup"),
+					RespondWithPatchedJSON(http.StatusOK, ldapResponse, `[
+					  {
+					    "op": "add",
+					    "path": "/mapping_method",
+					    "value": "lookup"
+					  }
+					]`),
+				),
+			)
+
+			// Run the apply command:
+			terraform.Source(`
+			  resource "ocm_identity_provider" "my_ip" {
+			    cluster        = "123"
+			    name           = "my-ip"
+			    mapping_method = "lookup"
+			    ldap = {
+			      bind_dn       = "my-bind-dn"
+			      bind_password = "my-bind-password"
+			      url           = "ldap://my-server.com"
+			      attributes    = {
+			        id = ["my-id"]
+			      }
+			    }
+			  }
+			`)
+			Expect(terraform.Apply()).To(BeZero())
+
+			// Check the state:
+			resource := terraform.Resource("ocm_identity_provider", "my_ip")
+			Expect(resource).To(MatchJQ(".attributes.id", "456"))
+			Expect(resource).To(MatchJQ(".attributes.mapping_method", "lookup"))
+		})
+
+		It("Updates the login options in place", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodGet,
+						"/api/clusters_mgmt/v1/clusters/123/identity_providers/456",
+					),
+					RespondWithJSON(http.StatusOK, ldapResponse),
+				),
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodPatch,
+						"/api/clusters_mgmt/v1/clusters/123/identity_providers/456",
+					),
+					VerifyJQ(".login", false),
+					VerifyJQ(".challenge", true),
+					RespondWithPatchedJSON(http.StatusOK, ldapResponse, `[
+					  {
+					    "op": "add",
+					    "path": "/login",
+					    "value": false
+					  },
+					  {
+					    "op": "add",
+					    "path": "/challenge",
+					    "value": true
+					  }
+					]`),
+				),
+			)
+
+			// Run the apply command:
+			terraform.Source(`
+			  resource "ocm_identity_provider" "my_ip" {
+			    cluster   = "123"
+			    name      = "my-ip"
+			    login     = false
+			    challenge = true
+			    ldap = {
+			      bind_dn       = "my-bind-dn"
+			      bind_password = "my-bind-password"
+			      url           = "ldap://my-server.com"
+			      attributes    = {
+			        id = ["my-id"]
+			      }
+			    }
+			  }
+			`)
+			Expect(terraform.Apply()).To(BeZero())
+
+			// Check the state:
+			resource := terraform.Resource("ocm_identity_provider", "my_ip")
+			Expect(resource).To(MatchJQ(".attributes.id", "456"))
+			Expect(resource).To(MatchJQ(".attributes.login", false))
+			Expect(resource).To(MatchJQ(".attributes.challenge", true))
+		})
+
+		It("Fails if the mapping method isn't valid", func() {
+			terraform.Source(`
+			  resource "ocm_identity_provider" "my_ip" {
+			    cluster        = "123"
+			    name           = "my-ip"
+			    mapping_method = "junk"
+			    ldap = {
+			      bind_dn       = "my-bind-dn"
+			      bind_password = "my-bind-password"
+			      url           = "ldap://my-server.com"
+			      attributes    = {
+			        id = ["my-id"]
+			      }
+			    }
+			  }
+			`)
+			Expect(terraform.Apply()).ToNot(BeZero())
+		})
+
+		It("Replaces the identity provider when the name changes", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodGet,
+						"/api/clusters_mgmt/v1/clusters/123/identity_providers/456",
+					),
+					RespondWithJSON(http.StatusOK, ldapResponse),
+				),
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodDelete,
+						"/api/clusters_mgmt/v1/clusters/123/identity_providers/456",
+					),
+					RespondWithJSON(http.StatusNoContent, "{}"),
+				),
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+					RespondWithJSON(http.StatusOK, `{
+					  "id": "123",
+					  "name": "my-cluster",
+					  "state": "ready"
+					}`),
+				),
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodPost,
+						"/api/clusters_mgmt/v1/clusters/123/identity_providers",
+					),
+					VerifyJQ(".name", "my-new-ip"),
+					RespondWithPatchedJSON(http.StatusOK, ldapResponse, `[
+					  {
+					    "op": "replace",
+					    "path": "/id",
+					    "value": "789"
+					  },
+					  {
+					    "op": "replace",
+					    "path": "/name",
+					    "value": "my-new-ip"
+					  }
+					]`),
+				),
+			)
+
+			// Run the apply command:
+			terraform.Source(`
+			  resource "ocm_identity_provider" "my_ip" {
+			    cluster = "123"
+			    name    = "my-new-ip"
+			    ldap = {
+			      bind_dn       = "my-bind-dn"
+			      bind_password = "my-bind-password"
+			      url           = "ldap://my-server.com"
+			      attributes    = {
+			        id = ["my-id"]
+			      }
+			    }
+			  }
+			`)
+			Expect(terraform.Apply()).To(BeZero())
+
+			// Check the state:
+			resource := terraform.Resource("ocm_identity_provider", "my_ip")
+			Expect(resource).To(MatchJQ(".attributes.id", "789"))
+			Expect(resource).To(MatchJQ(".attributes.name", "my-new-ip"))
+		})
+	})
+})
+
+var _ = Describe("Identity provider import", func() {
+	It("Can import an identity provider using the cluster and identity provider identifiers", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/identity_providers/456",
+				),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "456",
+				  "name": "my-ip",
+				  "type": "GithubIdentityProvider",
+				  "mapping_method": "lookup",
+				  "github": {
+				    "client_id": "test_client",
+				    "organizations": ["my-org"]
+				  }
+				}`),
+			),
+		)
+
+		// Run the import command:
+		terraform.Source(`
+		  resource "ocm_identity_provider" "my_ip" {
+		    cluster        = "123"
+		    name           = "my-ip"
+		    mapping_method = "lookup"
+		    github = {
+		      client_id     = "test_client"
+		      client_secret = "test_secret"
+		      organizations = ["my-org"]
+		    }
+		  }
+		`)
+		Expect(terraform.Import("ocm_identity_provider.my_ip", "123,456")).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_identity_provider", "my_ip")
+		Expect(resource).To(MatchJQ(".attributes.cluster", "123"))
+		Expect(resource).To(MatchJQ(".attributes.id", "456"))
+		Expect(resource).To(MatchJQ(".attributes.name", "my-ip"))
+		Expect(resource).To(MatchJQ(".attributes.mapping_method", "lookup"))
+		Expect(resource).To(MatchJQ(".attributes.github.client_id", "test_client"))
+	})
 })
@@ -209,4 +209,554 @@ var _ = Describe("Machine pool creation", func() {
 		Expect(resource).To(MatchJQ(".attributes.replicas", float64(10)))
 	})
 
+	It("Can create machine pool with labels and taints and update them", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/machine_pools",
+				),
+				VerifyJSON(`{
+				  "kind": "MachinePool",
+				  "id": "my-pool",
+				  "instance_type": "r5.xlarge",
+				  "replicas": 3,
+				  "labels": {
+				    "role": "db",
+				    "tier": "gold"
+				  },
+				  "taints": [
+				    {
+				      "key": "dedicated",
+				      "value": "db",
+				      "effect": "NoSchedule"
+				    }
+				  ]
+				}`),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "my-pool",
+				  "instance_type": "r5.xlarge",
+				  "replicas": 3,
+				  "labels": {
+				    "role": "db",
+				    "tier": "gold"
+				  },
+				  "taints": [
+				    {
+				      "key": "dedicated",
+				      "value": "db",
+				      "effect": "NoSchedule"
+				    }
+				  ]
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_machine_pool" "my_pool" {
+		    cluster      = "123"
+		    name         = "my-pool"
+		    machine_type = "r5.xlarge"
+		    replicas     = 3
+		    labels = {
+		      role = "db"
+		      tier = "gold"
+		    }
+		    taints = [
+		      {
+		        key           = "dedicated"
+		        value         = "db"
+		        schedule_type = "NoSchedule"
+		      }
+		    ]
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_machine_pool", "my_pool")
+		Expect(resource).To(MatchJQ(".attributes.labels.role", "db"))
+		Expect(resource).To(MatchJQ(".attributes.labels.tier", "gold"))
+		Expect(resource).To(MatchJQ(".attributes.taints | length", 1))
+		Expect(resource).To(MatchJQ(".attributes.taints[0].key", "dedicated"))
+		Expect(resource).To(MatchJQ(".attributes.taints[0].value", "db"))
+		Expect(resource).To(MatchJQ(".attributes.taints[0].schedule_type", "NoSchedule"))
+
+		// Prepare the server for the update:
+		server.AppendHandlers(
+			// First get is for the Read function
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123/machine_pools/my-pool"),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "my-pool",
+				  "instance_type": "r5.xlarge",
+				  "replicas": 3,
+				  "labels": {
+				    "role": "db",
+				    "tier": "gold"
+				  },
+				  "taints": [
+				    {
+				      "key": "dedicated",
+				      "value": "db",
+				      "effect": "NoSchedule"
+				    }
+				  ]
+				}`),
+			),
+			// Second get is for the Update function
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123/machine_pools/my-pool"),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "my-pool",
+				  "instance_type": "r5.xlarge",
+				  "replicas": 3
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPatch,
+					"/api/clusters_mgmt/v1/clusters/123/machine_pools/my-pool",
+				),
+				VerifyJSON(`{
+				  "kind": "MachinePool",
+				  "id": "my-pool",
+				  "replicas": 3,
+				  "labels": {
+				    "role": "db"
+				  },
+				  "taints": []
+				}`),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "my-pool",
+				  "instance_type": "r5.xlarge",
+				  "replicas": 3,
+				  "labels": {
+				    "role": "db"
+				  }
+				}`),
+			),
+		)
+
+		// Run the apply command to update the machine pool:
+		terraform.Source(`
+		  resource "ocm_machine_pool" "my_pool" {
+		    cluster      = "123"
+		    name         = "my-pool"
+		    machine_type = "r5.xlarge"
+		    replicas     = 3
+		    labels = {
+		      role = "db"
+		    }
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource = terraform.Resource("ocm_machine_pool", "my_pool")
+		Expect(resource).To(MatchJQ(".attributes.labels.role", "db"))
+		Expect(resource).To(MatchJQ(".attributes.labels.tier", nil))
+		Expect(resource).To(MatchJQ(".attributes.taints", nil))
+	})
+
+	It("Can create machine pool with spot instances in a subnet", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/machine_pools",
+				),
+				VerifyJSON(`{
+				  "kind": "MachinePool",
+				  "id": "my-pool",
+				  "instance_type": "r5.xlarge",
+				  "replicas": 3,
+				  "aws": {
+				    "kind": "AWSMachinePool",
+				    "spot_market_options": {
+				      "kind": "AWSSpotMarketOptions",
+				      "max_price": 0.5
+				    }
+				  },
+				  "availability_zones": [
+				    "us-east-1a"
+				  ],
+				  "subnets": [
+				    "subnet-123"
+				  ]
+				}`),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "my-pool",
+				  "instance_type": "r5.xlarge",
+				  "replicas": 3,
+				  "aws": {
+				    "spot_market_options": {
+				      "max_price": 0.5
+				    }
+				  },
+				  "availability_zones": [
+				    "us-east-1a"
+				  ],
+				  "subnets": [
+				    "subnet-123"
+				  ]
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_machine_pool" "my_pool" {
+		    cluster            = "123"
+		    name               = "my-pool"
+		    machine_type       = "r5.xlarge"
+		    replicas           = 3
+		    use_spot_instances = true
+		    max_spot_price     = 0.5
+		    availability_zone  = "us-east-1a"
+		    subnet_id          = "subnet-123"
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_machine_pool", "my_pool")
+		Expect(resource).To(MatchJQ(".attributes.use_spot_instances", true))
+		Expect(resource).To(MatchJQ(".attributes.max_spot_price", 0.5))
+		Expect(resource).To(MatchJQ(".attributes.availability_zone", "us-east-1a"))
+		Expect(resource).To(MatchJQ(".attributes.subnet_id", "subnet-123"))
+
+		// Prepare the server for the replacement caused by the change of the subnet:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123/machine_pools/my-pool"),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "my-pool",
+				  "instance_type": "r5.xlarge",
+				  "replicas": 3,
+				  "aws": {
+				    "spot_market_options": {
+				      "max_price": 0.5
+				    }
+				  },
+				  "availability_zones": [
+				    "us-east-1a"
+				  ],
+				  "subnets": [
+				    "subnet-123"
+				  ]
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodDelete,
+					"/api/clusters_mgmt/v1/clusters/123/machine_pools/my-pool",
+				),
+				RespondWithJSON(http.StatusNoContent, "{}"),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "123",
+				  "name": "my-cluster",
+				  "state": "ready"
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/machine_pools",
+				),
+				VerifyJQ(`.subnets`, []interface{}{"subnet-456"}),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "my-pool",
+				  "instance_type": "r5.xlarge",
+				  "replicas": 3,
+				  "aws": {
+				    "spot_market_options": {
+				      "max_price": 0.5
+				    }
+				  },
+				  "availability_zones": [
+				    "us-east-1a"
+				  ],
+				  "subnets": [
+				    "subnet-456"
+				  ]
+				}`),
+			),
+		)
+
+		// Run the apply command with the new subnet:
+		terraform.Source(`
+		  resource "ocm_machine_pool" "my_pool" {
+		    cluster            = "123"
+		    name               = "my-pool"
+		    machine_type       = "r5.xlarge"
+		    replicas           = 3
+		    use_spot_instances = true
+		    max_spot_price     = 0.5
+		    availability_zone  = "us-east-1a"
+		    subnet_id          = "subnet-456"
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource = terraform.Resource("ocm_machine_pool", "my_pool")
+		Expect(resource).To(MatchJQ(".attributes.subnet_id", "subnet-456"))
+	})
+
+	It("Can create machine pool with additional security groups", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/machine_pools",
+				),
+				VerifyJSON(`{
+				  "kind": "MachinePool",
+				  "id": "my-pool",
+				  "instance_type": "r5.xlarge",
+				  "replicas": 3,
+				  "aws": {
+				    "additional_security_group_ids": [
+				      "sg-123",
+				      "sg-456"
+				    ]
+				  }
+				}`),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "my-pool",
+				  "instance_type": "r5.xlarge",
+				  "replicas": 3,
+				  "aws": {
+				    "additional_security_group_ids": [
+				      "sg-123",
+				      "sg-456"
+				    ]
+				  }
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_machine_pool" "my_pool" {
+		    cluster      = "123"
+		    name         = "my-pool"
+		    machine_type = "r5.xlarge"
+		    replicas     = 3
+		    aws_additional_security_group_ids = ["sg-123", "sg-456"]
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_machine_pool", "my_pool")
+		Expect(resource).To(MatchJQ(
+			".attributes.aws_additional_security_group_ids",
+			[]interface{}{"sg-123", "sg-456"},
+		))
+	})
+
+	It("Doesn't replace the machine pool when the security groups are empty", func() {
+		// Prepare the server:
+		const pool = `{
+		  "id": "my-pool",
+		  "instance_type": "r5.xlarge",
+		  "replicas": 3
+		}`
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/machine_pools",
+				),
+				VerifyJSON(`{
+				  "kind": "MachinePool",
+				  "id": "my-pool",
+				  "instance_type": "r5.xlarge",
+				  "replicas": 3
+				}`),
+				RespondWithJSON(http.StatusOK, pool),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_machine_pool" "my_pool" {
+		    cluster      = "123"
+		    name         = "my-pool"
+		    machine_type = "r5.xlarge"
+		    replicas     = 3
+		    aws_additional_security_group_ids = []
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_machine_pool", "my_pool")
+		Expect(resource).To(MatchJQ(
+			".attributes.aws_additional_security_group_ids",
+			[]interface{}{},
+		))
+
+		// Prepare the server for the refresh, nothing else should be requested:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/machine_pools/my-pool",
+				),
+				RespondWithJSON(http.StatusOK, pool),
+			),
+		)
+
+		// Run the apply command again, the plan should be empty:
+		Expect(terraform.Apply()).To(BeZero())
+		resource = terraform.Resource("ocm_machine_pool", "my_pool")
+		Expect(resource).To(MatchJQ(".attributes.id", "my-pool"))
+	})
+
+	It("Fails if the spot price is set without spot instances", func() {
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_machine_pool" "my_pool" {
+		    cluster        = "123"
+		    name           = "my-pool"
+		    machine_type   = "r5.xlarge"
+		    replicas       = 3
+		    max_spot_price = 0.5
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
+
+	It("Fails if the effect of a taint isn't valid", func() {
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_machine_pool" "my_pool" {
+		    cluster      = "123"
+		    name         = "my-pool"
+		    machine_type = "r5.xlarge"
+		    replicas     = 3
+		    taints = [
+		      {
+		        key           = "dedicated"
+		        value         = "db"
+		        schedule_type = "NoSchedul"
+		      }
+		    ]
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
+
+	It("Can create machine pool with custom timeouts", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/machine_pools",
+				),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "my-pool",
+				  "instance_type": "r5.xlarge",
+				  "replicas": 10
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_machine_pool" "my_pool" {
+		    cluster      = "123"
+		    name         = "my-pool"
+		    machine_type = "r5.xlarge"
+		    replicas     = 10
+		    timeouts = {
+		      create        = "2h"
+		      delete        = "30m"
+		      poll_interval = "1s"
+		    }
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_machine_pool", "my_pool")
+		Expect(resource).To(MatchJQ(".attributes.timeouts.create", "2h"))
+		Expect(resource).To(MatchJQ(".attributes.timeouts.delete", "30m"))
+		Expect(resource).To(MatchJQ(".attributes.timeouts.poll_interval", "1s"))
+	})
+
+	It("Fails if a timeout isn't a valid duration", func() {
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_machine_pool" "my_pool" {
+		    cluster      = "123"
+		    name         = "my-pool"
+		    machine_type = "r5.xlarge"
+		    replicas     = 10
+		    timeouts = {
+		      create = "two hours"
+		    }
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
+})
+
+var _ = Describe("Machine pool import", func() {
+	It("Can import a machine pool using the cluster and machine pool identifiers", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/machine_pools/my-pool",
+				),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "my-pool",
+				  "instance_type": "r5.xlarge",
+				  "replicas": 10
+				}`),
+			),
+		)
+
+		// Run the import command:
+		terraform.Source(`
+		  resource "ocm_machine_pool" "my_pool" {
+		    cluster      = "123"
+		    name         = "my-pool"
+		    machine_type = "r5.xlarge"
+		    replicas     = 10
+		  }
+		`)
+		Expect(terraform.Import("ocm_machine_pool.my_pool", "123,my-pool")).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_machine_pool", "my_pool")
+		Expect(resource).To(MatchJQ(".attributes.cluster", "123"))
+		Expect(resource).To(MatchJQ(".attributes.id", "my-pool"))
+		Expect(resource).To(MatchJQ(".attributes.replicas", 10.0))
+	})
+
+	It("Fails if the import identifier doesn't contain the cluster", func() {
+		terraform.Source(`
+		  resource "ocm_machine_pool" "my_pool" {
+		    cluster      = "123"
+		    name         = "my-pool"
+		    machine_type = "r5.xlarge"
+		    replicas     = 10
+		  }
+		`)
+		Expect(terraform.Import("ocm_machine_pool.my_pool", "my-pool")).ToNot(BeZero())
+	})
 })
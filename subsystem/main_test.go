@@ -250,11 +250,26 @@ func (r *TerraformRunner) Validate() int {
 	return r.Run("validate")
 }
 
+// Plan runs the `plan` command.
+func (r *TerraformRunner) Plan() int {
+	return r.Run("plan")
+}
+
 // Apply runs the `apply` command.
 func (r *TerraformRunner) Apply() int {
 	return r.Run("apply", "-auto-approve")
 }
 
+// Destroy runs the `destroy` command.
+func (r *TerraformRunner) Destroy() int {
+	return r.Run("destroy", "-auto-approve")
+}
+
+// Import runs the `import` command.
+func (r *TerraformRunner) Import(address, id string) int {
+	return r.Run("import", address, id)
+}
+
 // State returns the reads the Terraform state and returns the result of parsing
 // it as a JSON document.
 func (r *TerraformRunner) State() interface{} {
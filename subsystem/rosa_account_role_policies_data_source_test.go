@@ -0,0 +1,173 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/onsi/gomega/ghttp"                   // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+const getAccountRolePolicies = `{
+	"page": 1,
+	"size": 8,
+	"total": 8,
+	"items": [
+		{
+			"kind": "STSPolicy",
+			"id": "sts_installer_trust_policy",
+			"details": "{\"Principal\": {\"AWS\": \"arn:%{partition}:iam::%{aws_account_id}:role/RH-Managed-OpenShift-Installer\"}}",
+			"type": "AccountRole"
+		},
+		{
+			"kind": "STSPolicy",
+			"id": "sts_installer_permission_policy",
+			"details": "{\"Action\": \"ec2:*\"}",
+			"type": "AccountRole"
+		},
+		{
+			"kind": "STSPolicy",
+			"id": "sts_support_trust_policy",
+			"details": "{\"Principal\": {\"AWS\": \"arn:%{partition}:iam::%{aws_account_id}:role/RH-Technical-Support-Access\"}}",
+			"type": "AccountRole"
+		},
+		{
+			"kind": "STSPolicy",
+			"id": "sts_support_permission_policy",
+			"details": "{\"Action\": \"ec2:Describe*\"}",
+			"type": "AccountRole"
+		},
+		{
+			"kind": "STSPolicy",
+			"id": "sts_instance_controlplane_trust_policy",
+			"details": "{\"Principal\": {\"Service\": \"ec2.amazonaws.com\"}}",
+			"type": "AccountRole"
+		},
+		{
+			"kind": "STSPolicy",
+			"id": "sts_instance_controlplane_permission_policy",
+			"details": "{\"Action\": \"elasticloadbalancing:*\"}",
+			"type": "AccountRole"
+		},
+		{
+			"kind": "STSPolicy",
+			"id": "sts_instance_worker_trust_policy",
+			"details": "{\"Principal\": {\"Service\": \"ec2.amazonaws.com\"}}",
+			"type": "AccountRole"
+		},
+		{
+			"kind": "STSPolicy",
+			"id": "sts_instance_worker_permission_policy",
+			"details": "{\"Action\": \"ec2:DescribeInstances\"}",
+			"type": "AccountRole"
+		}
+	]
+}`
+
+var _ = Describe("ROSA account role policies data source", func() {
+	It("Can get the policies of the account roles", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/aws_inquiries/sts_policies"),
+				RespondWithJSON(http.StatusOK, getAccountRolePolicies),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  data "ocm_rosa_account_role_policies" "policies" {
+		    openshift_version = "4.11.2"
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_rosa_account_role_policies", "policies")
+		Expect(resource).To(MatchJQ(`.attributes.trust_policies | length`, 4))
+		Expect(resource).To(MatchJQ(`.attributes.permission_policies | length`, 4))
+		Expect(resource).To(MatchJQ(
+			`.attributes.trust_policies.installer | fromjson | .Principal.AWS`,
+			"arn:aws:iam::710019948333:role/RH-Managed-OpenShift-Installer",
+		))
+		Expect(resource).To(MatchJQ(
+			`.attributes.permission_policies.instance_worker | fromjson | .Action`,
+			"ec2:DescribeInstances",
+		))
+	})
+
+	It("Uses the given partition", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/aws_inquiries/sts_policies"),
+				RespondWithJSON(http.StatusOK, getAccountRolePolicies),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  data "ocm_rosa_account_role_policies" "policies" {
+		    partition = "aws-us-gov"
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_rosa_account_role_policies", "policies")
+		Expect(resource).To(MatchJQ(`.attributes.partition`, "aws-us-gov"))
+		Expect(resource).To(MatchJQ(
+			`.attributes.trust_policies.installer | fromjson | .Principal.AWS`,
+			"arn:aws-us-gov:iam::710019948333:role/RH-Managed-OpenShift-Installer",
+		))
+	})
+
+	It("Fails if the version doesn't support STS", func() {
+		// Run the apply command:
+		terraform.Source(`
+		  data "ocm_rosa_account_role_policies" "policies" {
+		    openshift_version = "4.9.12"
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
+
+	It("Fails if a policy is missing", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/aws_inquiries/sts_policies"),
+				RespondWithJSON(http.StatusOK, `{
+				  "page": 1,
+				  "size": 0,
+				  "total": 0,
+				  "items": []
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  data "ocm_rosa_account_role_policies" "policies" {
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
+})
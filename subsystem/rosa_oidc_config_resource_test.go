@@ -0,0 +1,199 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/onsi/gomega/ghttp"                   // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("ROSA OIDC configuration", func() {
+	// This is the configuration returned by the server for the managed configuration created
+	// by most of the tests:
+	const configResponse = `{
+	  "id": "456",
+	  "issuer_url": "https://oidc.example.com/456",
+	  "managed": true,
+	  "reusable": true
+	}`
+
+	It("Creates a managed configuration", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, "/api/clusters_mgmt/v1/oidc_configs"),
+				VerifyJSON(`{
+				  "managed": true,
+				  "reusable": true
+				}`),
+				RespondWithJSON(http.StatusCreated, configResponse),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_rosa_oidc_config" "my_config" {
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_rosa_oidc_config", "my_config")
+		Expect(resource).To(MatchJQ(".attributes.id", "456"))
+		Expect(resource).To(MatchJQ(".attributes.managed", true))
+		Expect(resource).To(MatchJQ(".attributes.issuer_url", "https://oidc.example.com/456"))
+		Expect(resource).To(MatchJQ(".attributes.oidc_endpoint_url", "oidc.example.com/456"))
+	})
+
+	It("Creates an unmanaged configuration", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, "/api/clusters_mgmt/v1/oidc_configs"),
+				VerifyJSON(`{
+				  "issuer_url": "https://my-bucket.s3.us-east-1.amazonaws.com",
+				  "secret_arn": "arn:aws:secretsmanager:us-east-1:123:secret:my-secret",
+				  "installer_role_arn": "arn:aws:iam::123:role/ManagedOpenShift-Installer-Role",
+				  "managed": false,
+				  "reusable": true
+				}`),
+				RespondWithJSON(http.StatusCreated, `{
+				  "id": "789",
+				  "issuer_url": "https://my-bucket.s3.us-east-1.amazonaws.com",
+				  "secret_arn": "arn:aws:secretsmanager:us-east-1:123:secret:my-secret",
+				  "installer_role_arn": "arn:aws:iam::123:role/ManagedOpenShift-Installer-Role",
+				  "managed": false,
+				  "reusable": true
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_rosa_oidc_config" "my_config" {
+		    managed            = false
+		    issuer_url         = "https://my-bucket.s3.us-east-1.amazonaws.com"
+		    secret_arn         = "arn:aws:secretsmanager:us-east-1:123:secret:my-secret"
+		    installer_role_arn = "arn:aws:iam::123:role/ManagedOpenShift-Installer-Role"
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_rosa_oidc_config", "my_config")
+		Expect(resource).To(MatchJQ(".attributes.id", "789"))
+		Expect(resource).To(MatchJQ(".attributes.managed", false))
+		Expect(resource).To(MatchJQ(
+			".attributes.oidc_endpoint_url",
+			"my-bucket.s3.us-east-1.amazonaws.com",
+		))
+	})
+
+	It("Requires the secret for unmanaged configurations", func() {
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_rosa_oidc_config" "my_config" {
+		    managed            = false
+		    issuer_url         = "https://my-bucket.s3.us-east-1.amazonaws.com"
+		    installer_role_arn = "arn:aws:iam::123:role/ManagedOpenShift-Installer-Role"
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
+
+	It("Rejects the secret for managed configurations", func() {
+		// Run the apply command:
+		terraform.Source(`
+		  resource "ocm_rosa_oidc_config" "my_config" {
+		    secret_arn = "arn:aws:secretsmanager:us-east-1:123:secret:my-secret"
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
+
+	Context("Existing configuration", func() {
+		BeforeEach(func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(http.MethodPost, "/api/clusters_mgmt/v1/oidc_configs"),
+					RespondWithJSON(http.StatusCreated, configResponse),
+				),
+			)
+
+			// Run the apply command:
+			terraform.Source(`
+			  resource "ocm_rosa_oidc_config" "my_config" {
+			  }
+			`)
+			Expect(terraform.Apply()).To(BeZero())
+		})
+
+		It("Deletes the configuration", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/oidc_configs/456"),
+					RespondWithJSON(http.StatusOK, configResponse),
+				),
+				CombineHandlers(
+					VerifyRequest(http.MethodDelete, "/api/clusters_mgmt/v1/oidc_configs/456"),
+					RespondWithJSON(http.StatusNoContent, "{}"),
+				),
+			)
+
+			// Run the destroy command:
+			Expect(terraform.Destroy()).To(BeZero())
+		})
+
+		It("Creates the configuration again if it was deleted outside", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/oidc_configs/456"),
+					RespondWithJSON(http.StatusNotFound, `{
+					  "kind": "Error",
+					  "id": "404",
+					  "href": "/api/clusters_mgmt/v1/errors/404",
+					  "code": "CLUSTERS-MGMT-404",
+					  "reason": "OIDC config '456' not found"
+					}`),
+				),
+				CombineHandlers(
+					VerifyRequest(http.MethodPost, "/api/clusters_mgmt/v1/oidc_configs"),
+					RespondWithJSON(http.StatusCreated, `{
+					  "id": "457",
+					  "issuer_url": "https://oidc.example.com/457",
+					  "managed": true,
+					  "reusable": true
+					}`),
+				),
+			)
+
+			// Run the apply command:
+			Expect(terraform.Apply()).To(BeZero())
+
+			// Check the state:
+			resource := terraform.Resource("ocm_rosa_oidc_config", "my_config")
+			Expect(resource).To(MatchJQ(".attributes.id", "457"))
+		})
+	})
+})
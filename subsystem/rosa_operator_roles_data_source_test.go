@@ -46,6 +46,26 @@ const (
 		}
 	]
 }`
+
+	getStsPolicies = `{
+	"page": 1,
+	"size": 2,
+	"total": 2,
+	"items": [
+		{
+			"kind": "STSPolicy",
+			"id": "openshift_cluster_csi_drivers_ebs_cloud_credentials_policy",
+			"details": "{\"Version\": \"2012-10-17\", \"Statement\": [{\"Effect\": \"Allow\", \"Action\": [\"ec2:AttachVolume\"], \"Resource\": \"*\"}]}",
+			"type": "OperatorRole"
+		},
+		{
+			"kind": "STSPolicy",
+			"id": "openshift_cloud_network_config_controller_cloud_credentials_policy",
+			"details": "{\"Version\": \"2012-10-17\", \"Statement\": [{\"Effect\": \"Allow\", \"Action\": [\"ec2:AssignPrivateIpAddresses\"], \"Resource\": \"arn:%{partition}:ec2:*:*:*\"}]}",
+			"type": "OperatorRole"
+		}
+	]
+}`
 )
 
 var _ = Describe("ROSA Operator IAM roles data source", func() {
@@ -57,6 +77,10 @@ var _ = Describe("ROSA Operator IAM roles data source", func() {
 				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/aws_inquiries/sts_credential_requests"),
 				RespondWithJSON(http.StatusOK, getStsCredentialRequests),
 			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/aws_inquiries/sts_policies"),
+				RespondWithJSON(http.StatusOK, getStsPolicies),
+			),
 		)
 
 		// Run the apply command:
@@ -74,7 +98,7 @@ var _ = Describe("ROSA Operator IAM roles data source", func() {
 		Expect(resource).To(MatchJQ(`.attributes.operator_role_prefix`, "terraform-operator"))
 		Expect(resource).To(MatchJQ(`.attributes.account_role_prefix`, "TerraformAccountPrefix"))
 		Expect(resource).To(MatchJQ(`.attributes.operator_iam_roles | length`, 2))
-		compareResultOfRoles(resource, 0,
+		compareResultOfRoles(resource, 1,
 			"ebs-cloud-credentials",
 			"openshift-cluster-csi-drivers",
 			"TerraformAccountPrefix-openshift-cluster-csi-drivers-ebs-cloud-c",
@@ -86,7 +110,7 @@ var _ = Describe("ROSA Operator IAM roles data source", func() {
 			},
 		)
 
-		compareResultOfRoles(resource, 1,
+		compareResultOfRoles(resource, 0,
 			"cloud-credentials",
 			"openshift-cloud-network-config-controller",
 			"TerraformAccountPrefix-openshift-cloud-network-config-controller",
@@ -103,6 +127,10 @@ var _ = Describe("ROSA Operator IAM roles data source", func() {
 				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/aws_inquiries/sts_credential_requests"),
 				RespondWithJSON(http.StatusOK, getStsCredentialRequests),
 			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/aws_inquiries/sts_policies"),
+				RespondWithJSON(http.StatusOK, getStsPolicies),
+			),
 		)
 
 		// Run the apply command:
@@ -118,7 +146,7 @@ var _ = Describe("ROSA Operator IAM roles data source", func() {
 		//Expect(resource).To(MatchJQ(`.attributes.items | length`, 1))
 		Expect(resource).To(MatchJQ(`.attributes.operator_role_prefix`, "terraform-operator"))
 		Expect(resource).To(MatchJQ(`.attributes.operator_iam_roles | length`, 2))
-		compareResultOfRoles(resource, 0,
+		compareResultOfRoles(resource, 1,
 			"ebs-cloud-credentials",
 			"openshift-cluster-csi-drivers",
 			"ManagedOpenShift-openshift-cluster-csi-drivers-ebs-cloud-credent",
@@ -130,7 +158,7 @@ var _ = Describe("ROSA Operator IAM roles data source", func() {
 			},
 		)
 
-		compareResultOfRoles(resource, 1,
+		compareResultOfRoles(resource, 0,
 			"cloud-credentials",
 			"openshift-cloud-network-config-controller",
 			"ManagedOpenShift-openshift-cloud-network-config-controller-cloud",
@@ -139,6 +167,200 @@ var _ = Describe("ROSA Operator IAM roles data source", func() {
 			[]string{"system:serviceaccount:openshift-cloud-network-config-controller:cloud-network-config-controller"},
 		)
 	})
+
+	It("Can build the policy documents of the roles", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "123",
+				  "name": "my-cluster",
+				  "aws": {
+				    "account_id": "123456789012",
+				    "sts": {
+				      "oidc_endpoint_url": "https://rh-oidc.s3.us-east-1.amazonaws.com/123"
+				    }
+				  }
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/aws_inquiries/sts_credential_requests"),
+				RespondWithJSON(http.StatusOK, getStsCredentialRequests),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/aws_inquiries/sts_policies"),
+				RespondWithJSON(http.StatusOK, getStsPolicies),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  data "ocm_rosa_operator_roles" "operator_roles" {
+			  operator_role_prefix = "terraform-operator"
+			  cluster              = "123"
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_rosa_operator_roles", "operator_roles")
+		Expect(resource).To(MatchJQ(`.attributes.operator_iam_roles | length`, 2))
+		Expect(resource).To(MatchJQ(
+			`.attributes.operator_iam_roles[] | select(.operator_namespace == "openshift-cloud-network-config-controller") | .policy_document | fromjson | .Statement[0].Resource`,
+			"arn:aws:ec2:*:*:*",
+		))
+		Expect(resource).To(MatchJQ(
+			`.attributes.operator_iam_roles[] | select(.operator_namespace == "openshift-cloud-network-config-controller") | .assume_role_policy | fromjson | .Statement[0].Principal.Federated`,
+			"arn:aws:iam::123456789012:oidc-provider/rh-oidc.s3.us-east-1.amazonaws.com/123",
+		))
+		Expect(resource).To(MatchJQ(
+			`.attributes.operator_iam_roles[] | select(.operator_namespace == "openshift-cloud-network-config-controller") | .assume_role_policy | fromjson | .Statement[0].Condition.StringEquals["rh-oidc.s3.us-east-1.amazonaws.com/123:sub"][0]`,
+			"system:serviceaccount:openshift-cloud-network-config-controller:cloud-network-config-controller",
+		))
+	})
+
+	It("Can filter Operator IAM roles by version", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/aws_inquiries/sts_credential_requests"),
+				RespondWithJSON(http.StatusOK, getStsCredentialRequests),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/aws_inquiries/sts_policies"),
+				RespondWithJSON(http.StatusOK, getStsPolicies),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  data "ocm_rosa_operator_roles" "operator_roles" {
+			  operator_role_prefix = "terraform-operator"
+			  openshift_version    = "4.9.12"
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_rosa_operator_roles", "operator_roles")
+		Expect(resource).To(MatchJQ(`.attributes.operator_iam_roles | length`, 1))
+		Expect(resource).To(MatchJQ(
+			`.attributes.operator_iam_roles[0].operator_namespace`,
+			"openshift-cluster-csi-drivers",
+		))
+	})
+
+	It("Uses the operator role prefix and the version of the cluster", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusOK, `{
+				  "id": "123",
+				  "name": "my-cluster",
+				  "version": {
+				    "id": "openshift-v4.9.12",
+				    "raw_id": "4.9.12"
+				  },
+				  "aws": {
+				    "account_id": "123456789012",
+				    "sts": {
+				      "oidc_endpoint_url": "https://rh-oidc.s3.us-east-1.amazonaws.com/123",
+				      "operator_role_prefix": "my-cluster",
+				      "role_arn": "arn:aws-us-gov:iam::123456789012:role/my-Installer-Role"
+				    }
+				  }
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/aws_inquiries/sts_credential_requests"),
+				RespondWithJSON(http.StatusOK, getStsCredentialRequests),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/aws_inquiries/sts_policies"),
+				RespondWithJSON(http.StatusOK, getStsPolicies),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  data "ocm_rosa_operator_roles" "operator_roles" {
+			  cluster = "123"
+		  }
+		`)
+		Expect(terraform.Apply()).To(BeZero())
+
+		// Check the state:
+		resource := terraform.Resource("ocm_rosa_operator_roles", "operator_roles")
+		Expect(resource).To(MatchJQ(`.attributes.operator_role_prefix`, "my-cluster"))
+		Expect(resource).To(MatchJQ(`.attributes.openshift_version`, "4.9.12"))
+		Expect(resource).To(MatchJQ(`.attributes.partition`, "aws-us-gov"))
+		Expect(resource).To(MatchJQ(`.attributes.operator_iam_roles | length`, 1))
+		Expect(resource).To(MatchJQ(
+			`.attributes.operator_iam_roles[0].role_name`,
+			"my-cluster-openshift-cluster-csi-drivers-ebs-cloud-credentials",
+		))
+	})
+
+	It("Fails if a policy is missing", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/aws_inquiries/sts_credential_requests"),
+				RespondWithJSON(http.StatusOK, getStsCredentialRequests),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/aws_inquiries/sts_policies"),
+				RespondWithJSON(http.StatusOK, `{
+				  "page": 1,
+				  "size": 0,
+				  "total": 0,
+				  "items": []
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  data "ocm_rosa_operator_roles" "operator_roles" {
+			  operator_role_prefix = "terraform-operator"
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
+
+	It("Fails if the operator role prefix is missing", func() {
+		terraform.Source(`
+		  data "ocm_rosa_operator_roles" "operator_roles" {
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
+
+	It("Fails if the credential requests can't be listed", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/aws_inquiries/sts_credential_requests"),
+				RespondWithJSON(http.StatusInternalServerError, `{
+				  "kind": "Error",
+				  "id": "500",
+				  "href": "/api/clusters_mgmt/v1/errors/500",
+				  "code": "CLUSTERS-MGMT-500",
+				  "reason": "Internal error"
+				}`),
+			),
+		)
+
+		// Run the apply command:
+		terraform.Source(`
+		  data "ocm_rosa_operator_roles" "operator_roles" {
+			  operator_role_prefix = "terraform-operator"
+		  }
+		`)
+		Expect(terraform.Apply()).ToNot(BeZero())
+	})
 })
 
 func compareResultOfRoles(resource interface{}, index int, name, namespace, policyName, roleName string, serviceAccountLen int, serviceAccounts []string) {